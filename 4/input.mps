@@ -0,0 +1,20 @@
+NAME          LAB4
+ROWS
+ N  obj
+ G  c1
+ G  c2
+COLUMNS
+    x1        obj       4
+    x1        c1        2
+    x1        c2        2
+    x2        obj       3
+    x2        c1        1
+    x2        c2        2
+    x3        obj       7
+    x3        c1        4
+    x3        c2        2
+RHS
+    RHS       c1        1
+    RHS       c2        1.5
+BOUNDS
+ENDATA
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/lp"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+	"github.com/Lykashonok/moiu_labs_3_course/qp"
+	"github.com/Lykashonok/moiu_labs_3_course/transport"
+	"gonum.org/v1/gonum/mat"
+)
+
+var simplexCommand = command{
+	name: "simplex",
+	doc: `Main phase of the simplex method for c'x -> max, Ax = b, x >= 0.
+Problem rows: c, rows of A, b, baseline plan x. -exact solves the problem with
+fractions and prints them, e.g. 7/3.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		exact := flags.Bool("exact", false, "compute with rational numbers and print fractions")
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := lp.ParseOptimizationProblem(block, false)
+			if err != nil {
+				return solution{}, err
+			}
+			var result optim.Result
+			if *exact {
+				result, err = lp.ExactSimplexMainPhaseContext(ctx, options, problem.Exact.ScalesVector, problem.Exact.ConditionsMatrix, problem.Exact.BaselineVector, problem.BaselineIndexes)
+			} else {
+				result, err = lp.SimplexMainPhaseContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.BaselineVector, problem.BaselineIndexes)
+			}
+			s := solution{Result: result}
+			if err == nil {
+				if sensitivity, err := lp.SensitivityAnalysisContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, result); err == nil {
+					s.Sensitivity = &sensitivity
+				}
+			}
+			return s, err
+		}
+	},
+	solverFlags: append([]string{"ranges"}, primalFlags...),
+}
+
+var phase1Command = command{
+	name: "phase1",
+	doc: `Preparation phase of the simplex method, finds baseline plan of Ax = b, x >= 0.
+Problem rows: c, rows of A, b. Problem of MPS or CPLEX LP file is converted to
+canonical form.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := lp.ParseOptimizationProblem(block, true)
+			if err != nil {
+				return solution{}, err
+			}
+			result, err := lp.SimplexPreparationPhaseContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector)
+			return solution{Result: result}, err
+		}
+	},
+	model: func(ctx context.Context, options optim.Options, problem lp.GeneralProblem) (solution, error) {
+		canonical := problem.Canonical()
+		result, err := lp.SimplexPreparationPhaseContext(ctx, options, canonical.ScalesVector, canonical.ConditionsMatrix, canonical.FreeVector)
+		return solution{Result: result}, err
+	},
+	solverFlags: primalFlags,
+}
+
+var dualCommand = command{
+	name: "dual",
+	doc: `Dual simplex method for c'x -> max, Ax = b, x >= 0.
+Problem rows: c, rows of A, b, dual feasible baseline indexes starting from 1.
+Baseline indexes row may be omitted. If it is or the indexes aren't dual
+feasible basis, the basis is found by dual phase 1. -exact solves the problem
+with fractions and prints them, e.g. 7/3.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		exact := flags.Bool("exact", false, "compute with rational numbers and print fractions")
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := lp.ParseDoubleOptimizationProblem(block)
+			if err != nil {
+				return solution{}, err
+			}
+			var result optim.Result
+			if *exact {
+				result, err = lp.ExactDoubleSimplexMethodContext(ctx, options, problem.Exact.ScalesVector, problem.Exact.ConditionsMatrix, problem.Exact.FreeVector, problem.BaselineIndexes)
+			} else {
+				result, err = lp.DoubleSimplexMethodContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, problem.BaselineIndexes)
+			}
+			return solution{Result: result}, err
+		}
+	},
+	solverFlags: simplexFlags,
+}
+
+var boundedDualCommand = command{
+	name: "bounded-dual",
+	doc: `Dual simplex method with bound flipping for c'x -> max, Ax = b, lower <= x <= upper.
+Problem rows: c, rows of A, b, lower bounds, upper bounds, dual feasible
+baseline indexes starting from 1. Bounds may be -inf and inf. Baseline indexes
+row may be omitted. If it is or the indexes aren't dual feasible basis, the
+basis is found by dual phase 1. Prints the last pseudo plan x and dual plan y
+as duals.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := lp.ParseBoundedDoubleProblem(block)
+			if err != nil {
+				return solution{}, err
+			}
+			result, err := lp.BoundedDoubleSimplexMethodContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, problem.LowerBounds, problem.UpperBounds, problem.BaselineIndexes)
+			return solution{Result: result}, err
+		}
+	},
+	solverFlags: simplexFlags,
+}
+
+var lpCommand = command{
+	name: "lp",
+	doc: `Both phases of the simplex method for general form problem
+c'x -> max (min), A[i]x <= b[i] (>= b[i], = b[i]), lower <= x <= upper.
+Problem rows: c followed by max or min, rows of A each followed by <=, >= or =,
+b, then bound rows "j >= value", "j <= value", "j = value" or "j free" for
+variables without x >= 0 bound. Basis holds indexes of canonical variables.
+Input may also be MPS file with .mps extension or CPLEX LP file with .lp one.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := lp.ParseGeneralProblem(block)
+			if err != nil {
+				return solution{}, err
+			}
+			return solveGeneralProblem(ctx, options, problem)
+		}
+	},
+	model:       solveGeneralProblem,
+	solverFlags: append([]string{"ranges"}, primalFlags...),
+}
+
+func solveGeneralProblem(ctx context.Context, options optim.Options, problem lp.GeneralProblem) (solution, error) {
+	canonical := problem.Canonical()
+	result, err := canonical.SolveContext(ctx, options)
+	s := solution{Result: result, Names: problem.VarNames, ConditionNames: problem.ConditionNames}
+	if err == nil {
+		if sensitivity, err := canonical.SensitivityContext(ctx, options, result); err == nil {
+			s.Sensitivity = &sensitivity
+		}
+	}
+	return s, err
+}
+
+var boundedCommand = command{
+	name: "bounded",
+	doc: `Bounded variables simplex method for c'x -> max, Ax = b, lower <= x <= upper.
+Problem rows: c, lower bounds, upper bounds, rows of A, b. Bounds may be -inf
+and inf. Problem of MPS or CPLEX LP file keeps bounds of its variables,
+inequalities get slack variables, basis holds their indexes after the variables.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := lp.ParseBoundedProblem(block)
+			if err != nil {
+				return solution{}, err
+			}
+			result, err := lp.BoundedSimplexMethodContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, problem.LowerBounds, problem.UpperBounds)
+			return solution{Result: result}, err
+		}
+	},
+	model: func(ctx context.Context, options optim.Options, problem lp.GeneralProblem) (solution, error) {
+		result, err := problem.SolveBoundedContext(ctx, options)
+		return solution{Result: result, Names: problem.VarNames, ConditionNames: problem.ConditionNames}, err
+	},
+	solverFlags: append([]string{"pricing"}, simplexFlags...),
+}
+
+var parametricCommand = command{
+	name: "parametric",
+	doc: `Parametric simplex method for (c + t*d)'x -> max, Ax = b, x >= 0 or, with -rhs,
+c'x -> max, Ax = b + t*g, x >= 0 over t from -from to -to. Prints intervals
+of t between breakpoints with their optimal basis and objective
+constant + slope*t.
+Problem rows: c, rows of A, b, direction d or, with -rhs, g.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		freeValues := flags.Bool("rhs", false, "t changes free values b + t*g instead of objective c + t*d")
+		from := flags.Float64("from", 0, "the first value of t")
+		to := flags.String("to", "inf", "the last value of t, may be inf")
+		table := flags.String("csv", "", "write breakpoints table to file, tables of every problem with -all")
+		written := false
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			tTo, err := parse.ParseNumber(*to)
+			if err != nil {
+				return solution{}, fmt.Errorf("-to: %v", err)
+			}
+			problem, directionVector, err := lp.ParseParametricProblem(block, *freeValues)
+			if err != nil {
+				return solution{}, err
+			}
+			var intervals []lp.ParametricInterval
+			if *freeValues {
+				intervals, err = lp.ParametricFreeValues(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, directionVector, *from, tTo)
+			} else {
+				intervals, err = lp.ParametricObjective(ctx, options, problem.ScalesVector, directionVector, problem.ConditionsMatrix, problem.FreeVector, *from, tTo)
+			}
+			s := solution{Intervals: intervals}
+			if len(intervals) > 0 {
+				first, last := intervals[0], intervals[len(intervals)-1]
+				s.Result = optim.Result{
+					Status:     first.Status,
+					X:          first.X,
+					Basis:      first.Basis,
+					Objective:  first.Constant + first.Slope*first.From,
+					Iterations: last.Iteration,
+				}
+			}
+			if *table != "" && len(intervals) > 0 {
+				if tableErr := writeBreakpoints(*table, intervals, written); tableErr != nil {
+					return s, tableErr
+				}
+				written = true
+			}
+			return s, err
+		}
+	},
+	solverFlags: primalFlags,
+}
+
+var milpCommand = command{
+	name: "milp",
+	doc: `Branch and bound for c'x -> max, Ax = b, x >= 0 with integer variables.
+Relaxations of branches are solved with the dual simplex method from the
+optimal basis of their parent. -time-limit stops the whole search, the best
+integer plan found is printed with bound of the objective and gap.
+Problem rows: c, rows of A, b, indexes of integer variables starting from 1.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		selection := flags.String("node-selection", "best", "open node solved next: best (best bound) or depth (depth first)")
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			nodeSelection, err := parseNodeSelection(*selection)
+			if err != nil {
+				return solution{}, err
+			}
+			problem, integerIndexes, err := lp.ParseIntegerProblem(block)
+			if err != nil {
+				return solution{}, err
+			}
+			result, err := lp.BranchAndBoundContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, integerIndexes, nodeSelection)
+			return solution{Result: result.Result, Integer: &result}, err
+		}
+	},
+	solverFlags: integerFlags,
+}
+
+var gomoryCommand = command{
+	name: "gomory",
+	doc: `Gomory fractional cutting plane method for c'x -> max, Ax = b, x >= 0 with
+every x integer. Cuts are made of the tableau row of the baseline variable
+with the greatest fractional part and the relaxation is re-optimized with the
+dual simplex method. -exact computes tableau rows and cuts with fractions.
+Prints the cuts, s1, s2, ... are slack variables of the cuts.
+Problem rows: c, rows of A, b.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		exact := flags.Bool("exact", false, "compute tableau rows and cuts with rational numbers")
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := lp.ParseOptimizationProblem(block, true)
+			if err != nil {
+				return solution{}, err
+			}
+			var (
+				result optim.Result
+				cuts   []lp.GomoryCut
+			)
+			if *exact {
+				result, cuts, err = lp.ExactGomoryCutsContext(ctx, options, problem.Exact.ScalesVector, problem.Exact.ConditionsMatrix, problem.Exact.FreeVector)
+			} else {
+				result, cuts, err = lp.GomoryCutsContext(ctx, options, problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector)
+			}
+			return solution{Result: result, Cuts: cuts}, err
+		}
+	},
+	solverFlags: integerFlags,
+}
+
+// parseNodeSelection - node selection of branch and bound by its name
+func parseNodeSelection(name string) (lp.NodeSelection, error) {
+	for _, selection := range []lp.NodeSelection{lp.BestFirst, lp.DepthFirst} {
+		if selection.String() == name {
+			return selection, nil
+		}
+	}
+	return lp.BestFirst, fmt.Errorf("-node-selection: unknown node selection %q", name)
+}
+
+// writeBreakpoints - writes breakpoints table of intervals to file, appends
+// it after a blank line if the file was written by this run
+func writeBreakpoints(name string, intervals []lp.ParametricInterval, appending bool) error {
+	mode := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appending {
+		mode = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(name, mode, 0o644)
+	if err != nil {
+		return err
+	}
+	if appending {
+		fmt.Fprintln(file)
+	}
+	if err := lp.WriteBreakpoints(file, intervals); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+var transportCommand = command{
+	name: "transport",
+	doc: `Potentials method for closed transport problem.
+Problem rows: produced values a, needed values b, rows of costs matrix c.
+-exact solves the problem with fractions and prints them, e.g. 7/3.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		exact := flags.Bool("exact", false, "compute with rational numbers and print fractions")
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := transport.ParseTransportProblem(block)
+			if err != nil {
+				return solution{}, err
+			}
+			var result optim.Result
+			if *exact {
+				result, err = transport.ExactPotentialsMethodContext(ctx, options, problem.Exact.A, problem.Exact.B, problem.Exact.C)
+			} else {
+				result, err = transport.PotentialsMethodContext(ctx, options, problem.A, problem.B, problem.C)
+			}
+			s := solution{Result: result}
+			if result.X != nil {
+				s.MatrixName, s.Matrix = "plan", transport.Plan(result, problem.A.Len(), problem.B.Len())
+			}
+			if result.Exact != nil {
+				s.ExactMatrix = transport.ExactPlan(result, problem.A.Len(), problem.B.Len())
+			}
+			return s, err
+		}
+	},
+	solverFlags: []string{"dual-tolerance", "zero-tolerance"},
+}
+
+var qpCommand = command{
+	name: "qp",
+	doc: `Quadratic problem c'x + x'Dx/2 -> min, Ax = b, x >= 0.
+Problem rows: c, rows of D, rows of A, then feasible plan x, its support and
+extended support starting from 1, unless they're given with -plan, -support
+and -support-ex flags. Problems of 6/input.txt have no plan rows, e.g.
+moiu qp -plan "0 10 4" -support "2 3" 6/input.txt solves the first of them.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		plan := flags.String("plan", "", "feasible plan x, e.g. \"0 10 4\"")
+		support := flags.String("support", "", "support of the plan starting from 1, e.g. \"2 3\"")
+		supportEx := flags.String("support-ex", "", "extended support of the plan, support by default")
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			problem, err := qp.ParseSquareProblem(block, *plan == "")
+			if err != nil {
+				if _, withoutPlanErr := qp.ParseSquareProblem(block, false); *plan == "" && withoutPlanErr == nil {
+					return solution{}, usageError{fmt.Errorf("problem has no rows of feasible plan and its supports, give them with -plan and -support flags")}
+				}
+				return solution{}, err
+			}
+			if *plan != "" {
+				if problem.FeasiblePlan, problem.SupConstraints, problem.SupConstraintsEx, err = parsePlan(*plan, *support, *supportEx, problem.ObjectiveVector.Len()); err != nil {
+					return solution{}, err
+				}
+			}
+			result, err := qp.SolveSquareProblemContext(ctx, options, problem.ObjectiveVector, problem.SemiDefiniteMatrix, problem.ConditionsMatrix, problem.FeasiblePlan, problem.SupConstraints, problem.SupConstraintsEx)
+			return solution{Result: result}, err
+		}
+	},
+	solverFlags: []string{"dual-tolerance", "zero-tolerance", "pivot-tolerance"},
+}
+
+var inverseUpdateCommand = command{
+	name: "inverse-update",
+	doc: `Inverse of n×n matrix after replacing one of its columns.
+Problem rows: rows of matrix, rows of its inverse, a row for every column value,
+index of replaced column starting from 1. -exact computes the inverse with
+fractions and prints them, e.g. 7/3.`,
+	setup: func(flags *flag.FlagSet) solveFunc {
+		exact := flags.Bool("exact", false, "compute with rational numbers and print fractions")
+		return func(ctx context.Context, options optim.Options, block parse.Block) (solution, error) {
+			update, err := linalg.ParseMatrixMatrixInvVectorIndex(block)
+			if err != nil {
+				return solution{}, err
+			}
+			if *exact {
+				inversed, err := linalg.InvOptimizedOf(update.Exact.Matrix, update.Exact.MatrixInv, update.Exact.Vector, update.Index)
+				if err != nil {
+					return solution{Result: optim.Result{Status: optim.SingularBasis}}, err
+				}
+				return solution{
+					Result:      optim.Result{Status: optim.Optimal},
+					MatrixName:  "inversed matrix",
+					Matrix:      inversed.Float(),
+					ExactMatrix: inversed,
+				}, nil
+			}
+			inversed, err := linalg.InvOptimized(update.Matrix, update.MatrixInv, update.Vector, update.Index, optim.NewLimits(ctx, options).Tolerances().Pivot)
+			if err != nil {
+				return solution{Result: optim.Result{Status: optim.SingularBasis}}, err
+			}
+			return solution{
+				Result:     optim.Result{Status: optim.Optimal},
+				MatrixName: "inversed matrix",
+				Matrix:     inversed,
+			}, nil
+		}
+	},
+	solverFlags: []string{"pivot-tolerance"},
+}
+
+// parsePlan - feasible plan of varNumber values with its support and extended
+// support given with flags, extended support is support if it's empty
+func parsePlan(plan, support, supportEx string, varNumber int) (*mat.VecDense, []int, []int, error) {
+	var numbers []float64
+	for _, token := range parse.Tokenize("", 1, plan) {
+		number, err := parse.ParseNumber(token.Text)
+		if err != nil {
+			return nil, nil, nil, usageError{fmt.Errorf("plan: %v", err)}
+		}
+		numbers = append(numbers, number)
+	}
+	if len(numbers) == 0 {
+		return nil, nil, nil, usageError{fmt.Errorf("plan: expected numbers, got %q", plan)}
+	}
+	if len(numbers) != varNumber {
+		return nil, nil, nil, usageError{fmt.Errorf("plan has %v values, expected %v", len(numbers), varNumber)}
+	}
+	supConstraints, err := parseIndexes(support, varNumber)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	supConstraintsEx := supConstraints
+	if supportEx != "" {
+		if supConstraintsEx, err = parseIndexes(supportEx, varNumber); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return mat.NewVecDense(len(numbers), numbers), supConstraints, supConstraintsEx, nil
+}
+
+// parseIndexes - indexes of varNumber variables in whitespace or comma
+// separated fields, numeration starts from 1 in fields
+func parseIndexes(fields string, varNumber int) ([]int, error) {
+	var indexes []int
+	for _, token := range parse.Tokenize("", 1, fields) {
+		index, err := strconv.Atoi(token.Text)
+		if err != nil || index < 1 {
+			return nil, usageError{fmt.Errorf("support: %q is not an index", token.Text)}
+		}
+		if index > varNumber {
+			return nil, usageError{fmt.Errorf("support: index %v is out of %v variables", index, varNumber)}
+		}
+		indexes = append(indexes, index-1)
+	}
+	return indexes, nil
+}
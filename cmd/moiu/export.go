@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Lykashonok/moiu_labs_3_course/lp"
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+)
+
+// runExport - export command, writes general form problem of input to stdout
+// in MPS format
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	fixed := flags.Bool("fixed", false, "write fixed MPS instead of free one")
+	number := flags.Int("problem", 1, "number of the problem of input to write")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `usage: moiu export [flags] <input>
+
+Writes general form problem of input in MPS format, see "moiu lp -h" for the
+file format. Input with .mps or .lp extension is read as MPS or CPLEX LP file.
+
+flags:
+`)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return exitOptimal
+	} else if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	problem, err := readGeneralProblem(flags.Arg(0), *number)
+	if err == nil {
+		err = lp.WriteMPS(os.Stdout, problem, !*fixed)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "moiu export: %v\n", err)
+		return exitInputError
+	}
+	return exitOptimal
+}
+
+// readGeneralProblem - problem number of input
+func readGeneralProblem(input string, number int) (lp.GeneralProblem, error) {
+	if readModel := modelReader(input); readModel != nil {
+		return readModel(input)
+	}
+	blocks, err := parse.ReadBlocks(input)
+	if err != nil {
+		return lp.GeneralProblem{}, err
+	}
+	if number < 1 || number > len(blocks) {
+		return lp.GeneralProblem{}, fmt.Errorf("%v: there's no problem %v, there're %v problems", input, number, len(blocks))
+	}
+	return lp.ParseGeneralProblem(blocks[number-1])
+}
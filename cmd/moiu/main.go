@@ -0,0 +1,396 @@
+// Command moiu solves optimization problems from the labs input files.
+//
+// Usage:
+//
+//	moiu <command> [flags] <input>
+//
+// Commands are simplex, phase1, dual, bounded-dual, lp, bounded, parametric,
+// milp, gomory, transport, qp, inverse-update and export, see "moiu <command>
+// -h" for the flags and file format of each.
+// Problems in input are separated by blank lines, the first one is solved
+// unless -problem or -all flags are set. Sizes of every problem are inferred
+// from its rows. lp, bounded and phase1 commands also read the problem of MPS
+// file with .mps extension or CPLEX LP file with .lp extension, export command
+// writes problem in MPS format. Solutions of problems with named variables are
+// printed with the names. -pricing flag selects pricing rule of the primal
+// simplex method, -pricing all solves problems with every rule and prints
+// iterations each of them made. -anti-cycling flag selects how the primal
+// simplex method breaks ties of degenerate pivots, degenerate pivots and
+// baseline variables that were zero are printed. The simplex methods keep
+// baseline matrix as its LU with Forrest-Tomlin updates, -factorization eta
+// keeps LU and eta columns of updates instead, -refactor-every flag sets how
+// many updates are made between factorizations.
+// Optimal plans of the primal simplex method are printed with their dual
+// solution, -ranges flag adds ranges of c and b over which the optimal basis
+// stays optimal. parametric command prints breakpoints of t in objective c +
+// t*d or free values b + t*g, -csv flag writes them as a table. milp command
+// prints the best integer plan with bound of the objective, gap and number of
+// solved relaxations, gomory command prints the integer plan with the cuts
+// made. -exact flag of simplex, dual, transport, gomory and inverse-update
+// commands computes with rational numbers made of values as they are written in
+// the problem file, so 1/3 and 0.1 aren't rounded, and prints fractions such as
+// 7/3. -primal-tolerance, -dual-tolerance, -pivot-tolerance, -zero-tolerance,
+// -integrality-tolerance and -gap-tolerance flags set tolerances of the
+// solvers, plans are printed with their primal infeasibility, the greatest
+// violation of constraints and bounds, and dual infeasibility, the greatest
+// reduced cost of the wrong sign, of the plan or of any iteration before it.
+// Plans found optimal that violate constraints beyond -primal-tolerance aren't
+// solved.
+// Solver flags are accepted only by commands whose solvers use them, others
+// exit with the usage error code.
+// Indexes are printed with numeration starting from 1.
+//
+// Exit code is 0 for optimal runs, 1 for input errors, 2 for usage errors, 3
+// for infeasible problems, 4 for unbounded problems and 5 for runs stopped by
+// singular basis, iteration limit, time limit, interrupt, rounding errors
+// making primal and dual objectives differ or plans violating constraints.
+// With -all it's the exit code of the first problem that wasn't solved to
+// optimality.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/Lykashonok/moiu_labs_3_course/lp"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+)
+
+// Exit codes
+const (
+	exitOptimal = iota
+	exitInputError
+	exitUsage
+	exitInfeasible
+	exitUnbounded
+	exitNotSolved
+)
+
+// command - moiu subcommand. setup registers command flags and returns the
+// function solving problem of block, model solves problem of MPS file and is
+// nil for commands that don't read them. solverFlags are names of flags of
+// solverFlagSetters the command's solvers use, only they are registered
+type command struct {
+	name        string
+	doc         string
+	setup       func(flags *flag.FlagSet) solveFunc
+	model       func(ctx context.Context, options optim.Options, problem lp.GeneralProblem) (solution, error)
+	solverFlags []string
+}
+
+// solverSettings - values of solver flags that are parsed after flags, with
+// the defaults of unregistered ones
+type solverSettings struct {
+	pricing       string
+	antiCycling   string
+	factorization string
+	ranges        bool
+}
+
+// solverFlagSetters - registration of solver flags by their names
+var solverFlagSetters = map[string]func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings){
+	"pricing": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.StringVar(&settings.pricing, "pricing", settings.pricing, "pricing rule of the primal simplex method: "+strings.Join(lp.PricingRuleNames, ", ")+" or all to compare iterations of every rule")
+	},
+	"anti-cycling": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.StringVar(&settings.antiCycling, "anti-cycling", settings.antiCycling, "leaving row choice of the primal simplex method among tied ones: first, lexicographic or perturbation")
+	},
+	"factorization": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.StringVar(&settings.factorization, "factorization", settings.factorization, "factorization of baseline matrix of the simplex methods: lu (Forrest-Tomlin updates) or eta (product form)")
+	},
+	"refactor-every": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.IntVar(&options.RefactorEvery, "refactor-every", optim.RefactorEvery, "factorize baseline matrix of the simplex methods anew after this many updates")
+	},
+	"ranges": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.BoolVar(&settings.ranges, "ranges", false, "print ranges of c and b keeping optimal basis")
+	},
+	"primal-tolerance": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.Float64Var(&options.Tolerances.PrimalFeasibility, "primal-tolerance", optim.DefaultTolerances.PrimalFeasibility, "violations of constraints and bounds up to this are feasible")
+	},
+	"dual-tolerance": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.Float64Var(&options.Tolerances.DualFeasibility, "dual-tolerance", optim.DefaultTolerances.DualFeasibility, "reduced costs of the wrong sign up to this are optimal")
+	},
+	"pivot-tolerance": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.Float64Var(&options.Tolerances.Pivot, "pivot-tolerance", optim.DefaultTolerances.Pivot, "elements of pivot column or row up to this in absolute value aren't pivots")
+	},
+	"zero-tolerance": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.Float64Var(&options.Tolerances.Zero, "zero-tolerance", optim.DefaultTolerances.Zero, "values up to this in absolute value are zero")
+	},
+	"integrality-tolerance": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.Float64Var(&options.Tolerances.Integrality, "integrality-tolerance", optim.DefaultTolerances.Integrality, "values up to this far from an integer are integral")
+	},
+	"gap-tolerance": func(flags *flag.FlagSet, options *optim.Options, settings *solverSettings) {
+		flags.Float64Var(&options.Tolerances.DualityGap, "gap-tolerance", optim.DefaultTolerances.DualityGap, "difference of primal and dual objectives relative to 1 + |objective| an optimal plan may have")
+	},
+}
+
+// Solver flags of commands by the methods they run
+var (
+	// simplexFlags - flags of the simplex methods keeping baseline matrix
+	// factorized
+	simplexFlags = []string{"factorization", "refactor-every", "primal-tolerance", "dual-tolerance", "pivot-tolerance", "zero-tolerance"}
+	// primalFlags - flags of the primal simplex method with duality gap check
+	// of its optimal plan
+	primalFlags = append([]string{"pricing", "anti-cycling", "gap-tolerance"}, simplexFlags...)
+	// integerFlags - flags of the integer methods solving relaxations with
+	// both simplex methods
+	integerFlags = append([]string{"integrality-tolerance"}, primalFlags...)
+)
+
+// solveFunc - solves problem of block within solver limits
+type solveFunc func(ctx context.Context, options optim.Options, block parse.Block) (solution, error)
+
+// usageError - wrong value of command flag found by solveFunc, run exits
+// with the usage error code
+type usageError struct {
+	error
+}
+
+// problem - problem of input with the line it starts at
+type problem struct {
+	line  int
+	solve func(ctx context.Context, options optim.Options) (solution, error)
+}
+
+var commands = []command{
+	simplexCommand,
+	phase1Command,
+	dualCommand,
+	boundedDualCommand,
+	lpCommand,
+	boundedCommand,
+	parametricCommand,
+	milpCommand,
+	gomoryCommand,
+	transportCommand,
+	qpCommand,
+	inverseUpdateCommand,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: moiu <command> [flags] <input>\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %v\n", c.name)
+	}
+	fmt.Fprintf(os.Stderr, "  export\n")
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+	if args[0] == "export" {
+		return runExport(args[1:])
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "moiu: unknown command %q\n", args[0])
+	usage()
+	return exitUsage
+}
+
+func (c command) run(args []string) int {
+	flags := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	format := flags.String("format", "text", "output format, text or json")
+	verbose := flags.Bool("v", false, "write iterations log to stderr")
+	all := flags.Bool("all", false, "solve every problem of input")
+	number := flags.Int("problem", 1, "number of the problem of input to solve")
+	var options optim.Options
+	flags.IntVar(&options.MaxIterations, "max-iterations", optim.MaxIterations, "stop every solver run after this many pivots")
+	flags.DurationVar(&options.TimeLimit, "time-limit", 0, "stop every solver run after this time, e.g. 10s (0 - no limit)")
+	settings := solverSettings{pricing: "bland", antiCycling: "first", factorization: "lu"}
+	for _, name := range c.solverFlags {
+		solverFlagSetters[name](flags, &options, &settings)
+	}
+	solve := c.setup(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: moiu %v [flags] <input>\n\n%v\n\nflags:\n", c.name, c.doc)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return exitOptimal
+	} else if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || (*format != "text" && *format != "json") {
+		flags.Usage()
+		return exitUsage
+	}
+	mode, err := parseAntiCycling(settings.antiCycling)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "moiu %v: %v\n", c.name, err)
+		return exitUsage
+	}
+	options.AntiCycling = mode
+	if options.Factorization, err = parseFactorization(settings.factorization); err != nil {
+		fmt.Fprintf(os.Stderr, "moiu %v: %v\n", c.name, err)
+		return exitUsage
+	}
+	if settings.pricing != "all" {
+		rule, err := lp.NewPricingRule(settings.pricing)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "moiu %v: %v\n", c.name, err)
+			return exitUsage
+		}
+		options.Pricing = rule
+	}
+	if *verbose {
+		optim.Trace = os.Stderr
+	}
+
+	problems, err := c.read(flags.Arg(0), solve)
+	if err == nil && (*number < 1 || *number > len(problems)) {
+		err = fmt.Errorf("%v: there's no problem %v, there're %v problems", flags.Arg(0), *number, len(problems))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "moiu %v: %v\n", c.name, err)
+		return exitInputError
+	}
+	if !*all {
+		problems = problems[*number-1 : *number]
+	} else {
+		*number = 1
+	}
+
+	// interrupt stops the solver run with status canceled
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var reports []report
+	code := exitOptimal
+	for i, p := range problems {
+		optim.Tracef("---Problem %v at line %v---\n", *number+i, p.line)
+		var s solution
+		if settings.pricing == "all" {
+			s, err = p.solveEveryPricing(ctx, options)
+		} else {
+			s, err = p.solve(ctx, options)
+		}
+		if !settings.ranges {
+			s.Sensitivity = nil
+		}
+		reports = append(reports, report{Number: *number + i, Line: p.line, Solution: s, Err: err})
+		if code == exitOptimal {
+			code = exitCode(s.Result, err)
+		}
+	}
+	if *format == "json" {
+		writeJSON(os.Stdout, reports, *all)
+	} else {
+		writeText(os.Stdout, reports, *all)
+	}
+	return code
+}
+
+// read - problems of input. Files with .mps and .lp extensions hold one
+// problem in MPS or CPLEX LP format, other files hold problems separated by
+// blank lines
+func (c command) read(input string, solve solveFunc) ([]problem, error) {
+	if readModel := modelReader(input); readModel != nil {
+		if c.model == nil {
+			return nil, fmt.Errorf("%v: command doesn't read MPS and LP files", input)
+		}
+		model, err := readModel(input)
+		if err != nil {
+			return nil, err
+		}
+		return []problem{{line: 1, solve: func(ctx context.Context, options optim.Options) (solution, error) {
+			return c.model(ctx, options, model)
+		}}}, nil
+	}
+
+	blocks, err := parse.ReadBlocks(input)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]problem, len(blocks))
+	for i, block := range blocks {
+		block := block
+		problems[i] = problem{line: block.Line, solve: func(ctx context.Context, options optim.Options) (solution, error) {
+			return solve(ctx, options, block)
+		}}
+	}
+	return problems, nil
+}
+
+// solveEveryPricing - solves p with every pricing rule, returns solution of
+// the first rule with iterations made by each of them
+func (p problem) solveEveryPricing(ctx context.Context, options optim.Options) (solution, error) {
+	var first solution
+	var firstErr error
+	var runs []pricingRun
+	for i, name := range lp.PricingRuleNames {
+		optim.Tracef("---Pricing rule %v---\n", name)
+		options.Pricing, _ = lp.NewPricingRule(name)
+		s, err := p.solve(ctx, options)
+		runs = append(runs, pricingRun{Rule: name, Status: s.Result.Status, Iterations: s.Result.Iterations})
+		if i == 0 {
+			first, firstErr = s, err
+		}
+	}
+	first.Pricing = runs
+	return first, firstErr
+}
+
+// parseAntiCycling - anti cycling mode by its name
+func parseAntiCycling(name string) (optim.AntiCycling, error) {
+	for _, mode := range []optim.AntiCycling{optim.FirstRow, optim.Lexicographic, optim.Perturbation} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return optim.FirstRow, fmt.Errorf("unknown anti cycling mode %q", name)
+}
+
+// parseFactorization - factorization of baseline matrix by its name
+func parseFactorization(name string) (optim.Factorization, error) {
+	for _, factorization := range []optim.Factorization{optim.LU, optim.ProductForm} {
+		if factorization.String() == name {
+			return factorization, nil
+		}
+	}
+	return optim.LU, fmt.Errorf("unknown factorization %q", name)
+}
+
+// modelReader - reader of MPS or CPLEX LP file input, nil for other files
+func modelReader(input string) func(string) (lp.GeneralProblem, error) {
+	switch {
+	case strings.HasSuffix(input, ".mps"):
+		return lp.ReadMPSFile
+	case strings.HasSuffix(input, ".lp"):
+		return lp.ReadLPFile
+	}
+	return nil
+}
+
+// exitCode - exit code for solver run ended with result and err
+func exitCode(result optim.Result, err error) int {
+	var usage usageError
+	switch {
+	case err == nil && result.Status == optim.Optimal:
+		return exitOptimal
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.Is(err, optim.ErrInfeasible):
+		return exitInfeasible
+	case errors.Is(err, optim.ErrUnbounded):
+		return exitUnbounded
+	case errors.Is(err, optim.ErrSingularBasis), errors.Is(err, optim.ErrIterationLimit), errors.Is(err, optim.ErrCanceled), errors.Is(err, optim.ErrDualityGap), errors.Is(err, optim.ErrPrimalInfeasibility):
+		return exitNotSolved
+	}
+	return exitInputError
+}
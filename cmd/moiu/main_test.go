@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// quietRun - exit code of run with its output discarded
+func quietRun(t *testing.T, args []string) int {
+	t.Helper()
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	os.Stdout, os.Stderr = null, null
+	return run(args)
+}
+
+func TestSolverFlagsOfCommands(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input.txt")
+	// transport problem a, b, c and canonical problem c, A, b, x for simplex
+	if err := os.WriteFile(input, []byte("10 20\n15 15\n1 2\n3 4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"transport", "-zero-tolerance", "1e-8", input}, exitOptimal},
+		{[]string{"transport", "-pricing", "dantzig", input}, exitUsage},
+		{[]string{"transport", "-factorization", "eta", input}, exitUsage},
+		{[]string{"transport", "-gap-tolerance", "1e-3", input}, exitUsage},
+		{[]string{"inverse-update", "-ranges", input}, exitUsage},
+		{[]string{"qp", "-integrality-tolerance", "1e-3", input}, exitUsage},
+		{[]string{"dual", "-anti-cycling", "lexicographic", input}, exitUsage},
+		{[]string{"bounded", "-refactor-every", "10", "-ranges", input}, exitUsage},
+		{[]string{"simplex", "-integrality-tolerance", "1e-3", input}, exitUsage},
+	}
+	for _, test := range tests {
+		if code := quietRun(t, test.args); code != test.want {
+			t.Errorf("moiu %v: exit code %v, want %v", test.args, code, test.want)
+		}
+	}
+}
+
+func TestPlanFlagsOfQP(t *testing.T) {
+	input := filepath.Join("..", "..", "6", "input.txt")
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"qp", "-plan", "0 10 4", "-support", "2 3", input}, exitOptimal},
+		{[]string{"qp", input}, exitUsage},
+		{[]string{"qp", "-plan", " ", input}, exitUsage},
+		{[]string{"qp", "-plan", "0 x 4", "-support", "2 3", input}, exitUsage},
+		{[]string{"qp", "-plan", "0 10 4", "-support", "0 3", input}, exitUsage},
+		{[]string{"qp", "-plan", "0 0 0", "-support", "9 9", input}, exitUsage},
+		{[]string{"qp", "-plan", "0 10 4", "-support", "2 3", "-support-ex", "2 4", input}, exitUsage},
+		{[]string{"qp", "-plan", "0 10", "-support", "2 3", input}, exitUsage},
+	}
+	for _, test := range tests {
+		if code := quietRun(t, test.args); code != test.want {
+			t.Errorf("moiu %v: exit code %v, want %v", test.args, code, test.want)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/lp"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// solution - what a command prints: Result of the solver, names of X values
+// and conditions if the problem has them, a matrix for commands answering with one
+// (transport plan, inversed matrix), runs of every pricing rule if they're
+// compared, sensitivity ranges of optimal plan, intervals of parametric
+// problem, bound, gap and nodes of branch and bound and Gomory cuts.
+// ExactMatrix is the matrix of fractions of commands run with -exact
+type solution struct {
+	Result         optim.Result
+	Names          []string
+	ConditionNames []string
+	MatrixName     string
+	Matrix         *mat.Dense
+	ExactMatrix    *linalg.Dense[*big.Rat]
+	Pricing        []pricingRun
+	Sensitivity    *lp.Sensitivity
+	Intervals      []lp.ParametricInterval
+	Integer        *lp.IntegerResult
+	Cuts           []lp.GomoryCut
+}
+
+// pricingRun - how solver run with pricing rule ended
+type pricingRun struct {
+	Rule       string
+	Status     optim.Status
+	Iterations int
+}
+
+// jsonRange - range as it's written in json, infinite bounds are null
+type jsonRange struct {
+	Lower *float64 `json:"lower"`
+	Upper *float64 `json:"upper"`
+	Dual  *float64 `json:"dual,omitempty"`
+}
+
+// newJSONRange - json range of r
+func newJSONRange(r lp.Range) jsonRange {
+	var j jsonRange
+	r.Lower, r.Upper = number(r.Lower), number(r.Upper)
+	if !math.IsInf(r.Lower, 0) {
+		j.Lower = &r.Lower
+	}
+	if !math.IsInf(r.Upper, 0) {
+		j.Upper = &r.Upper
+	}
+	return j
+}
+
+// jsonPricingRun - pricingRun as it's written in json
+type jsonPricingRun struct {
+	Rule       string `json:"rule"`
+	Status     string `json:"status"`
+	Iterations int    `json:"iterations"`
+}
+
+// jsonInterval - interval of parametric problem as it's written in json,
+// infinite bounds are null
+type jsonInterval struct {
+	From     float64   `json:"from"`
+	To       *float64  `json:"to"`
+	Status   string    `json:"status"`
+	Constant float64   `json:"constant,omitempty"`
+	Slope    float64   `json:"slope,omitempty"`
+	Basis    []int     `json:"basis,omitempty"`
+	X        []float64 `json:"x,omitempty"`
+}
+
+// report - solution of one problem of input
+type report struct {
+	Number   int
+	Line     int
+	Solution solution
+	Err      error
+}
+
+// jsonReport - report as it's written in json
+type jsonReport struct {
+	Problem       int                `json:"problem"`
+	Line          int                `json:"line"`
+	Status        string             `json:"status"`
+	Error         string             `json:"error,omitempty"`
+	Objective     float64            `json:"objective"`
+	Iterations    int                `json:"iterations"`
+	X             []float64          `json:"x,omitempty"`
+	Values        map[string]float64 `json:"values,omitempty"`
+	PrimalInf     *float64           `json:"primal_infeasibility,omitempty"`
+	DualInf       *float64           `json:"dual_infeasibility,omitempty"`
+	Basis         []int              `json:"basis,omitempty"`
+	ExtendedBasis []int              `json:"extended_basis,omitempty"`
+	Matrix        [][]float64        `json:"matrix,omitempty"`
+	Pricing       []jsonPricingRun   `json:"pricing,omitempty"`
+	DualObjective *float64           `json:"dual_objective,omitempty"`
+	Duals         []float64          `json:"duals,omitempty"`
+	ReducedCosts  []float64          `json:"reduced_costs,omitempty"`
+	Slacks        []float64          `json:"slacks,omitempty"`
+	CostRanges    []jsonRange        `json:"cost_ranges,omitempty"`
+	FreeRanges    []jsonRange        `json:"free_value_ranges,omitempty"`
+	Degenerate    int                `json:"degenerate_pivots,omitempty"`
+	ZeroBasic     []int              `json:"zero_basic,omitempty"`
+	Intervals     []jsonInterval     `json:"intervals,omitempty"`
+	Bound         *float64           `json:"bound,omitempty"`
+	Gap           *float64           `json:"gap,omitempty"`
+	Nodes         int                `json:"nodes,omitempty"`
+	Cuts          []string           `json:"cuts,omitempty"`
+	Exact         *jsonExact         `json:"exact,omitempty"`
+}
+
+// jsonExact - values of Result and matrix as fractions like "7/3"
+type jsonExact struct {
+	Objective     string     `json:"objective,omitempty"`
+	X             []string   `json:"x,omitempty"`
+	Duals         []string   `json:"duals,omitempty"`
+	ReducedCosts  []string   `json:"reduced_costs,omitempty"`
+	Slacks        []string   `json:"slacks,omitempty"`
+	DualObjective string     `json:"dual_objective,omitempty"`
+	Matrix        [][]string `json:"matrix,omitempty"`
+}
+
+// ratStrings - values as fractions
+func ratStrings(values []*big.Rat) []string {
+	if values == nil {
+		return nil
+	}
+	r := make([]string, len(values))
+	for i, value := range values {
+		r[i] = value.RatString()
+	}
+	return r
+}
+
+// number - value with negative zero made 0. Rounding leaves -0 in place of
+// values that are 0, such as c1 = -0, and it isn't printed as 0
+func number(value float64) float64 {
+	if value == 0 {
+		return 0
+	}
+	return value
+}
+
+// values - values of v without negative zeros
+func values(v mat.Vector) []float64 {
+	r := linalg.RawVector(v)
+	for i, value := range r {
+		r[i] = number(value)
+	}
+	return r
+}
+
+// matrix - copy of m without negative zeros
+func matrix(m *mat.Dense) *mat.Dense {
+	rows, cols := m.Dims()
+	r := mat.NewDense(rows, cols, nil)
+	r.Apply(func(_, _ int, value float64) float64 { return number(value) }, m)
+	return r
+}
+
+// oneBased - indexes with numeration starting from 1
+func oneBased(indexes []int) []int {
+	if indexes == nil {
+		return nil
+	}
+	r := make([]int, len(indexes))
+	for i, index := range indexes {
+		r[i] = index + 1
+	}
+	return r
+}
+
+// writeText - write reports, headed by problem numbers if there're many
+func writeText(w io.Writer, reports []report, many bool) {
+	for i, r := range reports {
+		if many {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "problem %v (line %v)\n", r.Number, r.Line)
+		}
+		writeSolution(w, r.Solution, r.Err)
+	}
+}
+
+func writeSolution(w io.Writer, s solution, err error) {
+	fmt.Fprintf(w, "status: %v\n", s.Result.Status)
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+	}
+	exact := s.Result.Exact
+	if s.Result.X != nil && exact != nil {
+		fmt.Fprintf(w, "objective: %v\n", exact.Objective.RatString())
+		fmt.Fprintf(w, "iterations: %v\n", s.Result.Iterations)
+		writeRats(w, "x", exact.X, s.Names)
+	} else if s.Result.X != nil {
+		fmt.Fprintf(w, "objective: %v\n", number(s.Result.Objective))
+		fmt.Fprintf(w, "iterations: %v\n", s.Result.Iterations)
+		writeVector(w, "x", s.Result.X, s.Names)
+	}
+	if s.Result.X != nil {
+		fmt.Fprintf(w, "primal infeasibility: %v\n", s.Result.PrimalInfeasibility)
+		fmt.Fprintf(w, "dual infeasibility: %v\n", s.Result.DualInfeasibility)
+	}
+	if s.Result.Basis != nil {
+		fmt.Fprintf(w, "basis: %v\n", strings.Trim(fmt.Sprint(oneBased(s.Result.Basis)), "[]"))
+	}
+	if s.Result.Duals != nil {
+		if exact != nil && exact.Duals != nil {
+			fmt.Fprintf(w, "dual objective: %v\n", exact.DualObjective.RatString())
+			writeRats(w, "duals", exact.Duals, s.ConditionNames)
+			writeRats(w, "reduced costs", exact.ReducedCosts, s.Names)
+			writeRats(w, "slacks", exact.Slacks, s.ConditionNames)
+		} else {
+			fmt.Fprintf(w, "dual objective: %v\n", number(s.Result.DualObjective))
+			writeVector(w, "duals", s.Result.Duals, s.ConditionNames)
+			writeVector(w, "reduced costs", s.Result.ReducedCosts, s.Names)
+			writeVector(w, "slacks", s.Result.Slacks, s.ConditionNames)
+		}
+	}
+	if s.Sensitivity != nil {
+		fmt.Fprintf(w, "cost ranges:\n")
+		for j, r := range s.Sensitivity.Costs {
+			fmt.Fprintf(w, "  %v: %v .. %v\n", label(s.Names, j), number(r.Lower), number(r.Upper))
+		}
+		fmt.Fprintf(w, "free value ranges:\n")
+		for i, r := range s.Sensitivity.FreeValues {
+			fmt.Fprintf(w, "  %v: %v .. %v, dual %v\n", label(s.ConditionNames, i), number(r.Lower), number(r.Upper), number(s.Sensitivity.Duals.AtVec(i)))
+		}
+	}
+	if d := s.Result.Degeneracy; d.Pivots > 0 || d.ZeroBasic != nil {
+		fmt.Fprintf(w, "degenerate pivots: %v\n", d.Pivots)
+		fmt.Fprintf(w, "zero basic: %v\n", strings.Trim(fmt.Sprint(oneBased(d.ZeroBasic)), "[]"))
+	}
+	if s.Result.ExtendedBasis != nil {
+		fmt.Fprintf(w, "extended basis: %v\n", strings.Trim(fmt.Sprint(oneBased(s.Result.ExtendedBasis)), "[]"))
+	}
+	if s.ExactMatrix != nil {
+		fmt.Fprintf(w, "%v:\n", s.MatrixName)
+		s.ExactMatrix.Fprint(w)
+	} else if s.Matrix != nil {
+		fmt.Fprintf(w, "%v:\n", s.MatrixName)
+		linalg.MatFprint(w, matrix(s.Matrix))
+	}
+	if s.Intervals != nil {
+		fmt.Fprintf(w, "intervals:\n")
+		for _, interval := range s.Intervals {
+			if interval.Status != optim.Optimal {
+				fmt.Fprintf(w, "  %v <= t <= %v: %v\n", interval.From, interval.To, interval.Status)
+				continue
+			}
+			fmt.Fprintf(w, "  %v <= t <= %v: objective %v + %v*t, basis %v\n", number(interval.From), number(interval.To), number(interval.Constant), number(interval.Slope), strings.Trim(fmt.Sprint(oneBased(interval.Basis)), "[]"))
+		}
+	}
+	if s.Integer != nil {
+		fmt.Fprintf(w, "bound: %v\n", number(s.Integer.Bound))
+		fmt.Fprintf(w, "gap: %v\n", s.Integer.Gap)
+		fmt.Fprintf(w, "nodes: %v\n", s.Integer.Nodes)
+	}
+	if s.Cuts != nil {
+		fmt.Fprintf(w, "cuts:\n")
+		for _, cut := range s.Cuts {
+			fmt.Fprintf(w, "  %v\n", cut)
+		}
+	}
+	if s.Pricing != nil {
+		fmt.Fprintf(w, "iterations by pricing rule:\n")
+		for _, run := range s.Pricing {
+			fmt.Fprintf(w, "  %v: %v (%v)\n", run.Rule, run.Iterations, run.Status)
+		}
+	}
+}
+
+// label - name of value i or its index starting from 1 if there're no names
+func label(names []string, i int) string {
+	if names == nil {
+		return fmt.Sprint(i + 1)
+	}
+	return names[i]
+}
+
+// writeVector - write vector in a line or a line for every value if it has
+// names
+func writeVector(w io.Writer, label string, v *mat.VecDense, names []string) {
+	if names == nil {
+		fmt.Fprintf(w, "%v: %v\n", label, strings.Trim(fmt.Sprint(values(v)), "[]"))
+		return
+	}
+	fmt.Fprintf(w, "%v:\n", label)
+	for i, name := range names {
+		fmt.Fprintf(w, "  %v = %v\n", name, number(v.AtVec(i)))
+	}
+}
+
+// writeRats - write fractions in a line or a line for every value if they
+// have names
+func writeRats(w io.Writer, label string, values []*big.Rat, names []string) {
+	if names == nil {
+		fmt.Fprintf(w, "%v: %v\n", label, strings.Join(ratStrings(values), " "))
+		return
+	}
+	fmt.Fprintf(w, "%v:\n", label)
+	for i, name := range names {
+		fmt.Fprintf(w, "  %v = %v\n", name, values[i].RatString())
+	}
+}
+
+// writeJSON - write reports as json array if there're many, as object otherwise
+func writeJSON(w io.Writer, reports []report, many bool) {
+	j := make([]jsonReport, len(reports))
+	for i, r := range reports {
+		s := r.Solution
+		j[i] = jsonReport{
+			Problem:       r.Number,
+			Line:          r.Line,
+			Status:        s.Result.Status.String(),
+			Objective:     number(s.Result.Objective),
+			Iterations:    s.Result.Iterations,
+			Basis:         oneBased(s.Result.Basis),
+			ExtendedBasis: oneBased(s.Result.ExtendedBasis),
+			Degenerate:    s.Result.Degeneracy.Pivots,
+			ZeroBasic:     oneBased(s.Result.Degeneracy.ZeroBasic),
+		}
+		if r.Err != nil {
+			j[i].Error = r.Err.Error()
+		}
+		if s.Result.X != nil {
+			j[i].X = values(s.Result.X)
+			j[i].PrimalInf, j[i].DualInf = &s.Result.PrimalInfeasibility, &s.Result.DualInfeasibility
+			if s.Names != nil {
+				j[i].Values = map[string]float64{}
+				for k, name := range s.Names {
+					j[i].Values[name] = number(s.Result.X.AtVec(k))
+				}
+			}
+		}
+		if s.Result.Duals != nil {
+			dualObjective := number(s.Result.DualObjective)
+			j[i].DualObjective = &dualObjective
+			j[i].Duals = values(s.Result.Duals)
+			j[i].ReducedCosts = values(s.Result.ReducedCosts)
+			j[i].Slacks = values(s.Result.Slacks)
+		}
+		if s.Sensitivity != nil {
+			for _, r := range s.Sensitivity.Costs {
+				j[i].CostRanges = append(j[i].CostRanges, newJSONRange(r))
+			}
+			for k, r := range s.Sensitivity.FreeValues {
+				freeRange, dual := newJSONRange(r), number(s.Sensitivity.Duals.AtVec(k))
+				freeRange.Dual = &dual
+				j[i].FreeRanges = append(j[i].FreeRanges, freeRange)
+			}
+		}
+		if s.Matrix != nil {
+			rows, _ := s.Matrix.Dims()
+			for row := 0; row < rows; row++ {
+				j[i].Matrix = append(j[i].Matrix, values(s.Matrix.RowView(row)))
+			}
+		}
+		for _, interval := range s.Intervals {
+			jsonInterval := jsonInterval{
+				From:     number(interval.From),
+				Status:   interval.Status.String(),
+				Constant: number(interval.Constant),
+				Slope:    number(interval.Slope),
+				Basis:    oneBased(interval.Basis),
+			}
+			if !math.IsInf(interval.To, 0) {
+				to := number(interval.To)
+				jsonInterval.To = &to
+			}
+			if interval.X != nil {
+				jsonInterval.X = values(interval.X)
+			}
+			j[i].Intervals = append(j[i].Intervals, jsonInterval)
+		}
+		if s.Integer != nil {
+			bound, gap := number(s.Integer.Bound), s.Integer.Gap
+			if !math.IsInf(bound, 0) {
+				j[i].Bound = &bound
+			}
+			if !math.IsInf(gap, 0) {
+				j[i].Gap = &gap
+			}
+			j[i].Nodes = s.Integer.Nodes
+		}
+		for _, cut := range s.Cuts {
+			j[i].Cuts = append(j[i].Cuts, cut.String())
+		}
+		if exact := s.Result.Exact; exact != nil {
+			j[i].Exact = &jsonExact{
+				Objective:    exact.Objective.RatString(),
+				X:            ratStrings(exact.X),
+				Duals:        ratStrings(exact.Duals),
+				ReducedCosts: ratStrings(exact.ReducedCosts),
+				Slacks:       ratStrings(exact.Slacks),
+			}
+			if exact.DualObjective != nil {
+				j[i].Exact.DualObjective = exact.DualObjective.RatString()
+			}
+		}
+		if s.ExactMatrix != nil {
+			if j[i].Exact == nil {
+				j[i].Exact = &jsonExact{}
+			}
+			for _, row := range s.ExactMatrix.Rat() {
+				j[i].Exact.Matrix = append(j[i].Exact.Matrix, ratStrings(row))
+			}
+		}
+		for _, run := range s.Pricing {
+			j[i].Pricing = append(j[i].Pricing, jsonPricingRun{Rule: run.Rule, Status: run.Status.String(), Iterations: run.Iterations})
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if many {
+		encoder.Encode(j)
+	} else {
+		encoder.Encode(j[0])
+	}
+}
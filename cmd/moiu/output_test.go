@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestNegativeZerosArePrintedAsZeros(t *testing.T) {
+	negativeZero := math.Copysign(0, -1)
+	s := solution{
+		Result: optim.Result{
+			Status:        optim.Optimal,
+			Objective:     negativeZero,
+			X:             mat.NewVecDense(2, []float64{negativeZero, 1}),
+			Duals:         mat.NewVecDense(1, []float64{negativeZero}),
+			ReducedCosts:  mat.NewVecDense(2, []float64{0, negativeZero}),
+			Slacks:        mat.NewVecDense(1, []float64{negativeZero}),
+			DualObjective: negativeZero,
+		},
+		Names:          []string{"c1", "c2"},
+		ConditionNames: []string{"r1"},
+	}
+
+	var text bytes.Buffer
+	writeText(&text, []report{{Number: 1, Solution: s}}, false)
+	if strings.Contains(text.String(), "-0") {
+		t.Errorf("text output has negative zero:\n%v", text.String())
+	}
+
+	var j bytes.Buffer
+	writeJSON(&j, []report{{Number: 1, Solution: s}}, false)
+	if strings.Contains(j.String(), "-0") {
+		t.Errorf("json output has negative zero:\n%v", j.String())
+	}
+	var decoded jsonReport
+	if err := json.Unmarshal(j.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range append(append(decoded.Duals, decoded.Slacks...), decoded.Values["c1"]) {
+		if math.Signbit(value) {
+			t.Errorf("json value %v is negative zero", value)
+		}
+	}
+}
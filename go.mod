@@ -0,0 +1,5 @@
+module github.com/Lykashonok/moiu_labs_3_course
+
+go 1.21
+
+require gonum.org/v1/gonum v0.12.0
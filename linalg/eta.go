@@ -0,0 +1,142 @@
+package linalg
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// EtaFile - BasisFactor keeping inversed baseline matrix B in product form
+// B^-1 = E_k ... E_1 B_0^-1. B_0 is the matrix of the last factorization kept
+// as its LU, E_i is identity matrix with eta column in place of the column
+// replaced by update i. FTRAN and BTRAN solve systems of B without forming
+// B^-1. The file is factorized anew after refactorEvery updates or when
+// residual of an update grows over tolerances.Residual
+type EtaFile struct {
+	matrix        *mat.Dense
+	lu            mat.LU
+	etas          []eta
+	refactorEvery int
+	tolerances    FactorTolerances
+}
+
+// eta - eta column of update replacing column index of B. Its value at index
+// is pivot, the nonzero others are values at indexes
+type eta struct {
+	index   int
+	pivot   float64
+	indexes []int
+	values  []float64
+}
+
+// NewEtaFile - eta file of square matrix factorized anew after refactorEvery
+// updates. matrix is copied. Returns ErrSingular if it can't be inversed
+func NewEtaFile(matrix *mat.Dense, refactorEvery int, tolerances FactorTolerances) (*EtaFile, error) {
+	f := &EtaFile{matrix: mat.DenseCopyOf(matrix), refactorEvery: refactorEvery, tolerances: tolerances}
+	if err := f.factorize(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// factorize - LU of matrix in place of B_0 and etas. LU and etas are kept
+// if matrix is singular
+func (f *EtaFile) factorize() error {
+	var lu mat.LU
+	lu.Factorize(f.matrix)
+	if cond := lu.Cond(); math.IsInf(cond, 1) || cond > mat.ConditionTolerance {
+		return ErrSingular
+	}
+	f.lu, f.etas = lu, nil
+	return nil
+}
+
+// Len - number of rows of B
+func (f *EtaFile) Len() int {
+	n, _ := f.matrix.Dims()
+	return n
+}
+
+// Updates - number of eta columns since the last factorization
+func (f *EtaFile) Updates() int {
+	return len(f.etas)
+}
+
+// FTRAN - x = B^-1 v, solution of B x = v
+func (f *EtaFile) FTRAN(v mat.Vector) *mat.VecDense {
+	x := mat.NewVecDense(f.Len(), nil)
+	// conditions of B_0 were checked by factorize
+	_ = f.lu.SolveVecTo(x, false, v)
+	data := x.RawVector().Data
+	for _, e := range f.etas {
+		value := data[e.index]
+		if value == 0 {
+			continue
+		}
+		for k, i := range e.indexes {
+			data[i] += e.values[k] * value
+		}
+		data[e.index] = e.pivot * value
+	}
+	return x
+}
+
+// BTRAN - y' = v'B^-1, solution of y'B = v'
+func (f *EtaFile) BTRAN(v mat.Vector) *mat.VecDense {
+	w := mat.VecDenseCopyOf(v)
+	data := w.RawVector().Data
+	for k := len(f.etas) - 1; k >= 0; k-- {
+		e := f.etas[k]
+		value := e.pivot * data[e.index]
+		for j, i := range e.indexes {
+			value += e.values[j] * data[i]
+		}
+		data[e.index] = value
+	}
+	y := mat.NewVecDense(f.Len(), nil)
+	_ = f.lu.SolveVecTo(y, true, w)
+	return y
+}
+
+// Solve - FTRAN of v
+func (f *EtaFile) Solve(v mat.Vector) *mat.VecDense {
+	return f.FTRAN(v)
+}
+
+// SolveTranspose - BTRAN of v
+func (f *EtaFile) SolveTranspose(v mat.Vector) *mat.VecDense {
+	return f.BTRAN(v)
+}
+
+// Replace - replaces column index of B with vector adding eta column of
+// B^-1 vector, or factorizing B anew. Returns ErrSingular and keeps B if the
+// new matrix can't be inversed
+func (f *EtaFile) Replace(index int, vector mat.Vector) error {
+	z := f.FTRAN(vector)
+	pivot := z.AtVec(index)
+	if math.Abs(pivot) <= f.tolerances.Pivot {
+		return ErrSingular
+	}
+
+	// residual of B z = vector before B changes says how accurate etas are
+	relative := relativeResidual(f.matrix, z, vector)
+	previous := mat.VecDenseCopyOf(f.matrix.ColView(index))
+	f.matrix.SetCol(index, mat.VecDenseCopyOf(vector).RawVector().Data)
+	if len(f.etas)+1 >= f.refactorEvery || relative > f.tolerances.Residual {
+		if err := f.factorize(); err != nil {
+			f.matrix.SetCol(index, previous.RawVector().Data)
+			return err
+		}
+		return nil
+	}
+
+	e := eta{index: index, pivot: 1 / pivot}
+	for i, value := range z.RawVector().Data {
+		if i != index && value != 0 {
+			e.indexes = append(e.indexes, i)
+			e.values = append(e.values, -value/pivot)
+		}
+	}
+	f.etas = append(f.etas, e)
+	return nil
+}
@@ -0,0 +1,42 @@
+package linalg
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func newEtaFile(matrix *mat.Dense, refactorEvery int, tolerances FactorTolerances) (BasisFactor, error) {
+	return NewEtaFile(matrix, refactorEvery, tolerances)
+}
+
+func TestEtaFileReplace(t *testing.T) {
+	testReplaceSequence(t, newEtaFile)
+}
+
+func TestEtaFileSingularReplace(t *testing.T) {
+	testSingularReplace(t, newEtaFile)
+}
+
+func TestEtaFileRefactorization(t *testing.T) {
+	matrix := mat.NewDense(3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1})
+	file, err := NewEtaFile(matrix, 3, testTolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for step, want := range []int{1, 2, 0, 1} {
+		replaceDiagonal(t, file, step%3)
+		if file.Updates() != want {
+			t.Errorf("replacement %v: %v eta columns, want %v", step+1, file.Updates(), want)
+		}
+	}
+
+	file, err = NewEtaFile(matrix, 100, inaccurateTolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaceDiagonal(t, file, 1)
+	if file.Updates() != 0 {
+		t.Errorf("inaccurate update is kept as %v eta columns", file.Updates())
+	}
+}
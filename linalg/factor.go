@@ -0,0 +1,57 @@
+package linalg
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// BasisFactor - factorization of square baseline matrix B solving its systems
+// without forming B^-1, shared by the simplex methods
+type BasisFactor interface {
+	// Len - number of rows of B
+	Len() int
+	// Solve - x of B x = v (FTRAN)
+	Solve(v mat.Vector) *mat.VecDense
+	// SolveTranspose - y of y'B = v' (BTRAN)
+	SolveTranspose(v mat.Vector) *mat.VecDense
+	// Replace - replaces column index of B with vector. Returns ErrSingular
+	// and keeps B if the new matrix can't be inversed
+	Replace(index int, vector mat.Vector) error
+}
+
+// FactorTolerances - tolerances of float64 factors: pivots up to Pivot in
+// absolute value are zero and make the matrix singular, relative residual of
+// B z = a over Residual for entering column a makes the factor factorized anew
+type FactorTolerances struct {
+	Pivot    float64
+	Residual float64
+}
+
+// InverseRow - row index of B^-1, SolveTranspose of unit vector
+func InverseRow(factor BasisFactor, index int) *mat.VecDense {
+	unit := mat.NewVecDense(factor.Len(), nil)
+	unit.SetVec(index, 1)
+	return factor.SolveTranspose(unit)
+}
+
+// InverseOf - B^-1 as dense matrix, Solve of every unit vector
+func InverseOf(factor BasisFactor) *mat.Dense {
+	n := factor.Len()
+	inverse, unit := mat.NewDense(n, n, nil), mat.NewVecDense(n, nil)
+	for j := 0; j < n; j++ {
+		unit.SetVec(j, 1)
+		inverse.SetCol(j, factor.Solve(unit).RawVector().Data)
+		unit.SetVec(j, 0)
+	}
+	return inverse
+}
+
+// relativeResidual - |matrix x - v| / (1 + |v|) in max norm, how accurate
+// solution x of matrix x = v is
+func relativeResidual(matrix *mat.Dense, x, v mat.Vector) float64 {
+	residual := mat.NewVecDense(v.Len(), nil)
+	residual.MulVec(matrix, x)
+	residual.SubVec(residual, v)
+	return mat.Norm(residual, math.Inf(1)) / (1 + mat.Norm(v, math.Inf(1)))
+}
@@ -0,0 +1,127 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// testTolerances - tolerances of factors in tests, the ones of
+// optim.DefaultTolerances
+var testTolerances = FactorTolerances{Pivot: 1e-9, Residual: 1e-9}
+
+// newFactorFunc - constructor of BasisFactor under test
+type newFactorFunc func(matrix *mat.Dense, refactorEvery int, tolerances FactorTolerances) (BasisFactor, error)
+
+// randomMatrix - n×n matrix with diagonal dominating its rows, so it can be
+// inversed
+func randomMatrix(r *rand.Rand, n int) *mat.Dense {
+	matrix := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			matrix.Set(i, j, r.Float64()*2-1)
+		}
+		matrix.Set(i, i, float64(n)+r.Float64())
+	}
+	return matrix
+}
+
+// randomVector - n values in [-1, 1) with mostly zero ones if sparse
+func randomVector(r *rand.Rand, n int, sparse bool) *mat.VecDense {
+	v := mat.NewVecDense(n, nil)
+	for i := 0; i < n; i++ {
+		if !sparse || r.Intn(3) == 0 {
+			v.SetVec(i, r.Float64()*2-1)
+		}
+	}
+	return v
+}
+
+// checkSolves - Solve and SolveTranspose of factor against systems of matrix
+// solved by mat.Dense
+func checkSolves(t *testing.T, step int, factor BasisFactor, matrix *mat.Dense, v *mat.VecDense) {
+	t.Helper()
+	var want mat.VecDense
+	if err := want.SolveVec(matrix, v); err != nil {
+		t.Fatalf("step %v: %v", step, err)
+	}
+	if got := factor.Solve(v); !mat.EqualApprox(got, &want, 1e-9) {
+		t.Errorf("step %v: Solve = %v, want %v", step, mat.Formatted(got.T()), mat.Formatted(want.T()))
+	}
+	if err := want.SolveVec(matrix.T(), v); err != nil {
+		t.Fatalf("step %v: %v", step, err)
+	}
+	if got := factor.SolveTranspose(v); !mat.EqualApprox(got, &want, 1e-9) {
+		t.Errorf("step %v: SolveTranspose = %v, want %v", step, mat.Formatted(got.T()), mat.Formatted(want.T()))
+	}
+}
+
+// testReplaceSequence - factor keeps solving systems of B after every
+// replacement of a sequence of them, with refactorizations among them
+func testReplaceSequence(t *testing.T, newFactor newFactorFunc) {
+	r := rand.New(rand.NewSource(1))
+	const n = 8
+	for _, refactorEvery := range []int{1, 5, 100} {
+		matrix := randomMatrix(r, n)
+		factor, err := newFactor(matrix, refactorEvery, testTolerances)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSolves(t, 0, factor, matrix, randomVector(r, n, false))
+		for step := 1; step <= 40; step++ {
+			index, column := r.Intn(n), randomVector(r, n, step%2 == 0)
+			column.SetVec(index, float64(n)+r.Float64())
+			if err := factor.Replace(index, column); err != nil {
+				t.Fatalf("refactor every %v, step %v: %v", refactorEvery, step, err)
+			}
+			matrix.SetCol(index, column.RawVector().Data)
+			checkSolves(t, step, factor, matrix, randomVector(r, n, false))
+		}
+	}
+}
+
+// testSingularReplace - replacing a column with a copy of another one returns
+// ErrSingular and factor keeps solving systems of B, whether it's updated or
+// factorized anew
+func testSingularReplace(t *testing.T, newFactor newFactorFunc) {
+	r := rand.New(rand.NewSource(2))
+	const n = 5
+	for _, refactorEvery := range []int{1, 100} {
+		matrix := randomMatrix(r, n)
+		factor, err := newFactor(matrix, refactorEvery, testTolerances)
+		if err != nil {
+			t.Fatal(err)
+		}
+		column := randomVector(r, n, false)
+		column.SetVec(1, float64(n))
+		if err := factor.Replace(1, column); err != nil {
+			t.Fatal(err)
+		}
+		matrix.SetCol(1, column.RawVector().Data)
+		if err := factor.Replace(3, matrix.ColView(0)); !errors.Is(err, ErrSingular) {
+			t.Fatalf("refactor every %v: Replace with copy of column 0 = %v, want %v", refactorEvery, err, ErrSingular)
+		}
+		checkSolves(t, 1, factor, matrix, randomVector(r, n, false))
+	}
+
+	if _, err := newFactor(mat.NewDense(2, 2, []float64{1, 2, 2, 4}), 100, testTolerances); !errors.Is(err, ErrSingular) {
+		t.Errorf("factor of singular matrix = %v, want %v", err, ErrSingular)
+	}
+}
+
+// replaceDiagonal - replaces column index of diagonal matrix of factor with
+// another diagonal column
+func replaceDiagonal(t *testing.T, factor BasisFactor, index int) {
+	t.Helper()
+	column := mat.NewVecDense(factor.Len(), nil)
+	column.SetVec(index, float64(index+2))
+	if err := factor.Replace(index, column); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// inaccurateTolerances - tolerances that find every update inaccurate
+var inaccurateTolerances = FactorTolerances{Pivot: 1e-9, Residual: math.Inf(-1)}
@@ -0,0 +1,407 @@
+package linalg
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Field - arithmetic of values of Dense matrices and vectors: Float for
+// float64 values compared with tolerance, Rational for exact *big.Rat values.
+// Operations return new values, *big.Rat values are never changed in place
+type Field[T any] interface {
+	// FromFloat - value of x, Rational takes its shortest decimal form, so 0.1
+	// is 1/10
+	FromFloat(x float64) T
+	// Float - the nearest float64 to x
+	Float(x T) float64
+	// Rat - x as a fraction
+	Rat(x T) *big.Rat
+	Add(a, b T) T
+	Sub(a, b T) T
+	Mul(a, b T) T
+	Div(a, b T) T
+	// Sign - -1, 0 or 1, Float values within tolerance from zero are zero
+	Sign(x T) int
+	// Format - x as it's printed, Rational values are fractions like 7/3
+	Format(x T) string
+	// Exact - whether values are computed without rounding errors
+	Exact() bool
+}
+
+// Float - float64 values, values within Tolerance from each other are equal
+type Float struct {
+	Tolerance float64
+}
+
+// FromFloat - x
+func (Float) FromFloat(x float64) float64 { return x }
+
+// Float - x
+func (Float) Float(x float64) float64 { return x }
+
+// Rat - exact value of x
+func (Float) Rat(x float64) *big.Rat { return new(big.Rat).SetFloat64(x) }
+
+// Add - a + b
+func (Float) Add(a, b float64) float64 { return a + b }
+
+// Sub - a - b
+func (Float) Sub(a, b float64) float64 { return a - b }
+
+// Mul - a * b
+func (Float) Mul(a, b float64) float64 { return a * b }
+
+// Div - a / b
+func (Float) Div(a, b float64) float64 { return a / b }
+
+// Sign - sign of x, 0 if |x| <= Tolerance
+func (f Float) Sign(x float64) int {
+	switch {
+	case x > f.Tolerance:
+		return 1
+	case x < -f.Tolerance:
+		return -1
+	}
+	return 0
+}
+
+// Format - x in the shortest form
+func (Float) Format(x float64) string { return strconv.FormatFloat(x, 'g', -1, 64) }
+
+// Exact - false, float64 values have rounding errors
+func (Float) Exact() bool { return false }
+
+// Rational - exact *big.Rat values
+type Rational struct{}
+
+// FromFloat - fraction of the shortest decimal form of x
+func (Rational) FromFloat(x float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(x, 'g', -1, 64))
+	if !ok {
+		return new(big.Rat).SetFloat64(x)
+	}
+	return r
+}
+
+// Float - the nearest float64 to x
+func (Rational) Float(x *big.Rat) float64 {
+	f, _ := x.Float64()
+	return f
+}
+
+// Rat - x
+func (Rational) Rat(x *big.Rat) *big.Rat { return x }
+
+// Add - a + b
+func (Rational) Add(a, b *big.Rat) *big.Rat { return new(big.Rat).Add(a, b) }
+
+// Sub - a - b
+func (Rational) Sub(a, b *big.Rat) *big.Rat { return new(big.Rat).Sub(a, b) }
+
+// Mul - a * b
+func (Rational) Mul(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }
+
+// Div - a / b
+func (Rational) Div(a, b *big.Rat) *big.Rat { return new(big.Rat).Quo(a, b) }
+
+// Sign - sign of x
+func (Rational) Sign(x *big.Rat) int { return x.Sign() }
+
+// Format - x as integer or fraction
+func (Rational) Format(x *big.Rat) string { return x.RatString() }
+
+// Exact - true
+func (Rational) Exact() bool { return true }
+
+// Dense - r×c matrix with values of Field stored by rows
+type Dense[T any] struct {
+	Field Field[T]
+	rows  int
+	cols  int
+	data  []T
+}
+
+// NewDenseOf - r×c zero matrix of field
+func NewDenseOf[T any](field Field[T], r, c int) *Dense[T] {
+	m := &Dense[T]{Field: field, rows: r, cols: c, data: make([]T, r*c)}
+	zero := field.FromFloat(0)
+	for i := range m.data {
+		m.data[i] = zero
+	}
+	return m
+}
+
+// DenseOf - matrix of field with values of m
+func DenseOf[T any](field Field[T], m mat.Matrix) *Dense[T] {
+	r, c := m.Dims()
+	d := NewDenseOf(field, r, c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			d.Set(i, j, field.FromFloat(m.At(i, j)))
+		}
+	}
+	return d
+}
+
+// RatDenseOf - Rational matrix of rows of fractions, values aren't copied
+func RatDenseOf(rows [][]*big.Rat) *Dense[*big.Rat] {
+	cols := 0
+	if len(rows) > 0 {
+		cols = len(rows[0])
+	}
+	d := NewDenseOf[*big.Rat](Rational{}, len(rows), cols)
+	for i, row := range rows {
+		for j, value := range row {
+			d.Set(i, j, value)
+		}
+	}
+	return d
+}
+
+// VectorOf - values of v in field
+func VectorOf[T any](field Field[T], v mat.Vector) []T {
+	r := make([]T, v.Len())
+	for i := range r {
+		r[i] = field.FromFloat(v.AtVec(i))
+	}
+	return r
+}
+
+// FloatVectorOf - the nearest float64 values of v
+func FloatVectorOf[T any](field Field[T], v []T) *mat.VecDense {
+	r := mat.NewVecDense(len(v), nil)
+	for i, value := range v {
+		r.SetVec(i, field.Float(value))
+	}
+	return r
+}
+
+// RatVectorOf - values of v as fractions
+func RatVectorOf[T any](field Field[T], v []T) []*big.Rat {
+	r := make([]*big.Rat, len(v))
+	for i, value := range v {
+		r[i] = field.Rat(value)
+	}
+	return r
+}
+
+// Dims - numbers of rows and columns
+func (m *Dense[T]) Dims() (int, int) { return m.rows, m.cols }
+
+// At - value of row i and column j
+func (m *Dense[T]) At(i, j int) T { return m.data[i*m.cols+j] }
+
+// Set - sets value of row i and column j
+func (m *Dense[T]) Set(i, j int, value T) { m.data[i*m.cols+j] = value }
+
+// Row - copy of row i
+func (m *Dense[T]) Row(i int) []T { return append([]T{}, m.data[i*m.cols:(i+1)*m.cols]...) }
+
+// Col - copy of column j
+func (m *Dense[T]) Col(j int) []T {
+	r := make([]T, m.rows)
+	for i := range r {
+		r[i] = m.At(i, j)
+	}
+	return r
+}
+
+// SetCol - sets column j to values
+func (m *Dense[T]) SetCol(j int, values []T) {
+	for i, value := range values {
+		m.Set(i, j, value)
+	}
+}
+
+// Clone - copy of m
+func (m *Dense[T]) Clone() *Dense[T] {
+	return &Dense[T]{Field: m.Field, rows: m.rows, cols: m.cols, data: append([]T{}, m.data...)}
+}
+
+// Columns - matrix of columns of m with indexes
+func (m *Dense[T]) Columns(indexes []int) *Dense[T] {
+	r := NewDenseOf(m.Field, m.rows, len(indexes))
+	for k, j := range indexes {
+		r.SetCol(k, m.Col(j))
+	}
+	return r
+}
+
+// Float - the nearest float64 matrix to m
+func (m *Dense[T]) Float() *mat.Dense {
+	r := mat.NewDense(m.rows, m.cols, nil)
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			r.Set(i, j, m.Field.Float(m.At(i, j)))
+		}
+	}
+	return r
+}
+
+// Rat - m as rows of fractions
+func (m *Dense[T]) Rat() [][]*big.Rat {
+	r := make([][]*big.Rat, m.rows)
+	for i := range r {
+		r[i] = RatVectorOf(m.Field, m.Row(i))
+	}
+	return r
+}
+
+// MulVec - m times column vector v
+func (m *Dense[T]) MulVec(v []T) []T {
+	r := make([]T, m.rows)
+	for i := range r {
+		r[i] = Dot(m.Field, m.data[i*m.cols:(i+1)*m.cols], v)
+	}
+	return r
+}
+
+// VecMul - row vector v times m
+func (m *Dense[T]) VecMul(v []T) []T {
+	r := make([]T, m.cols)
+	for j := range r {
+		r[j] = Dot(m.Field, v, m.Col(j))
+	}
+	return r
+}
+
+// Mul - m times b
+func (m *Dense[T]) Mul(b *Dense[T]) *Dense[T] {
+	r := NewDenseOf(m.Field, m.rows, b.cols)
+	for j := 0; j < b.cols; j++ {
+		r.SetCol(j, m.MulVec(b.Col(j)))
+	}
+	return r
+}
+
+// Dot - scalar product of a and b
+func Dot[T any](field Field[T], a, b []T) T {
+	sum := field.FromFloat(0)
+	for i := range a {
+		// exact zeros are skipped, they're most of values of sparse problems
+		if field.Exact() && (field.Sign(a[i]) == 0 || field.Sign(b[i]) == 0) {
+			continue
+		}
+		sum = field.Add(sum, field.Mul(a[i], b[i]))
+	}
+	return sum
+}
+
+// Inverse - inversed square matrix m found by Gauss-Jordan elimination with
+// the greatest pivots. Returns ErrSingular if m can't be inversed
+func (m *Dense[T]) Inverse() (*Dense[T], error) {
+	n, field := m.rows, m.Field
+	a, inverse := m.Clone(), NewDenseOf(field, n, n)
+	for i := 0; i < n; i++ {
+		inverse.Set(i, i, field.FromFloat(1))
+	}
+	for column := 0; column < n; column++ {
+		pivot := column
+		for i := column + 1; i < n; i++ {
+			if math.Abs(field.Float(a.At(i, column))) > math.Abs(field.Float(a.At(pivot, column))) {
+				pivot = i
+			}
+		}
+		if field.Sign(a.At(pivot, column)) == 0 {
+			return nil, ErrSingular
+		}
+		a.swapRows(column, pivot)
+		inverse.swapRows(column, pivot)
+		scale := a.At(column, column)
+		for j := 0; j < n; j++ {
+			a.Set(column, j, field.Div(a.At(column, j), scale))
+			inverse.Set(column, j, field.Div(inverse.At(column, j), scale))
+		}
+		for i := 0; i < n; i++ {
+			factor := a.At(i, column)
+			if i == column || field.Sign(factor) == 0 {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				a.Set(i, j, field.Sub(a.At(i, j), field.Mul(factor, a.At(column, j))))
+				inverse.Set(i, j, field.Sub(inverse.At(i, j), field.Mul(factor, inverse.At(column, j))))
+			}
+		}
+	}
+	return inverse, nil
+}
+
+func (m *Dense[T]) swapRows(i, k int) {
+	for j := 0; j < m.cols; j++ {
+		m.data[i*m.cols+j], m.data[k*m.cols+j] = m.data[k*m.cols+j], m.data[i*m.cols+j]
+	}
+}
+
+// Fprint - print matrix to w by rows with values of the same width, framed
+// as MatFprint frames them
+func (m *Dense[T]) Fprint(w io.Writer) {
+	width := 0
+	for _, value := range m.data {
+		if n := len(m.Field.Format(value)); n > width {
+			width = n
+		}
+	}
+	for i := 0; i < m.rows; i++ {
+		values := make([]string, m.cols)
+		for j := range values {
+			values[j] = fmt.Sprintf("%*s", width, m.Field.Format(m.At(i, j)))
+		}
+		left, right := "⎢", "⎥"
+		switch {
+		case m.rows == 1:
+			left, right = "[", "]"
+		case i == 0:
+			left, right = "⎡", "⎤"
+		case i == m.rows-1:
+			left, right = "⎣", "⎦"
+		}
+		fmt.Fprintf(w, "%v%v%v\n", left, strings.Join(values, "  "), right)
+	}
+}
+
+// FormatVector - values of v separated by spaces
+func FormatVector[T any](field Field[T], v []T) string {
+	values := make([]string, len(v))
+	for i, value := range v {
+		values[i] = field.Format(value)
+	}
+	return strings.Join(values, " ")
+}
+
+// InvOptimizedOf - InvOptimized for matrices of any Field: inverse of matrix
+// after replacing its index column with vector, built from matrixInv in
+// O(n²). matrix is updated in place. Returns ErrSingular if the new matrix
+// can't be inversed
+func InvOptimizedOf[T any](matrix, matrixInv *Dense[T], vector []T, index int) (*Dense[T], error) {
+	field, n := matrix.Field, len(vector)
+	matrix.SetCol(index, vector)
+	l := matrixInv.MulVec(vector)
+	if field.Sign(l[index]) == 0 {
+		return nil, ErrSingular
+	}
+	// column of Q: -l/l[index] with -1/l[index] at index
+	storedNumber := l[index]
+	minusOne := field.FromFloat(-1)
+	l[index] = minusOne
+	for i := range l {
+		l[i] = field.Div(field.Mul(l[i], minusOne), storedNumber)
+	}
+	// Q·matrixInv, Q is identity with column index replaced by l
+	result := NewDenseOf(field, n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			value := field.Mul(l[i], matrixInv.At(index, j))
+			if i != index {
+				value = field.Add(matrixInv.At(i, j), value)
+			}
+			result.Set(i, j, value)
+		}
+	}
+	return result, nil
+}
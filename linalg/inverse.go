@@ -0,0 +1,60 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ErrSingular - matrix can't be inversed
+var ErrSingular = errors.New("matrix is singular")
+
+func mulOptimized(a, b *mat.Dense, index int) *mat.Dense {
+	n, _ := a.Dims()
+	result, subSum := mat.NewDense(n, n, nil), float64(0)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != index {
+				subSum = a.At(i, i)*b.At(i, j) + a.At(i, index)*b.At(index, j)
+			} else {
+				subSum = a.At(i, index) * b.At(index, j)
+			}
+			result.Set(i, j, subSum)
+		}
+	}
+	return result
+}
+
+// InvOptimized - inverse of matrix after replacing its index column with
+// vector, built from matrixInv in O(n²). matrix is updated in place. Returns
+// ErrSingular if the new matrix can't be inversed, pivot up to pivotTolerance
+// in absolute value is zero
+func InvOptimized(matrix, matrixInv *mat.Dense, vector *mat.VecDense, index int, pivotTolerance float64) (*mat.Dense, error) {
+	n := len(vector.RawVector().Data)
+	// step 0
+	matrix.SetCol(index, vector.RawVector().Data)
+
+	// step 1
+	AInv := mat.NewDense(n, n, make([]float64, n*n)) // clonning matrixInv
+	AInv.CloneFrom(matrixInv)
+	l := mat.VecDenseCopyOf(vector)
+	l.MulVec(AInv, vector)
+	if math.Abs(l.At(index, 0)) <= pivotTolerance {
+		return nil, ErrSingular
+	}
+	// step 2
+	storedNumber := l.At(index, 0)
+	l.SetVec(index, -1)
+
+	// step 3
+	l.ScaleVec((-1 / storedNumber), l)
+
+	// step 4
+	Q := Eye(n)
+	Q.SetCol(index, l.RawVector().Data)
+
+	// step 5
+	result := mulOptimized(Q, AInv, index)
+	return result, nil
+}
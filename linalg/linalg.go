@@ -0,0 +1,129 @@
+// Package linalg holds vector and matrix helpers shared by the solvers: slices
+// conversions, printing and inverse matrix update after column replacement.
+// BasisFactor solves systems of baseline matrix and updates it after column
+// replacement: LUFactor keeps its LU found with Markowitz pivoting and updated
+// by Forrest-Tomlin, EtaFile keeps inversed one in product form, LU of the
+// last factorization and eta columns of updates, solving by FTRAN and BTRAN.
+// Dense matrices of Field values run the same algorithms on float64 values
+// (Float) or exact math/big.Rat fractions (Rational).
+package linalg
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Find - find element in array
+func Find(array []float64, value float64) bool {
+	return Index(array, value) != -1
+}
+
+// FindInt - find element in array of indexes
+func FindInt(array []int, value int) bool {
+	for _, curr := range array {
+		if curr == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Index - index of first element equal to value in array, -1 if there's none
+func Index(array []float64, value float64) int {
+	for i, curr := range array {
+		if curr == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// Min - find min element in array
+func Min(array []float64) float64 {
+	min := array[0]
+	for i := 0; i < len(array); i++ {
+		if min > array[i] {
+			min = array[i]
+		}
+	}
+	return min
+}
+
+// RawVector - return mat.Vector as []float64
+func RawVector(v mat.Vector) []float64 {
+	n := v.Len()
+	r := make([]float64, n)
+	for i := 0; i < n; i++ {
+		r[i] = v.AtVec(i)
+	}
+	return r
+}
+
+// IntVector - return mat.Vector holding indexes as []int
+func IntVector(v mat.Vector) []int {
+	r := make([]int, v.Len())
+	for i := range r {
+		r[i] = int(v.AtVec(i))
+	}
+	return r
+}
+
+// FloatVector - return indexes as mat.VecDense
+func FloatVector(indexes []int) *mat.VecDense {
+	r := make([]float64, len(indexes))
+	for i, index := range indexes {
+		r[i] = float64(index)
+	}
+	return mat.NewVecDense(len(r), r)
+}
+
+// VecMulMat - row vector v multiplied by matrix m, v.Len() must be equal to
+// the number of m rows
+func VecMulMat(v mat.Vector, m mat.Matrix) *mat.VecDense {
+	r, c := m.Dims()
+	sum := 0.0
+	vector := mat.NewVecDense(c, nil)
+	for i := 0; i < c; i++ {
+		sum = 0
+		for j := 0; j < r; j++ {
+			sum += v.AtVec(j) * m.At(j, i)
+		}
+		vector.SetVec(i, sum)
+	}
+	return vector
+}
+
+// Columns - matrix made of m columns with given indexes
+func Columns(m mat.Matrix, indexes []int) *mat.Dense {
+	r, _ := m.Dims()
+	columns := mat.NewDense(r, len(indexes), nil)
+	for i, index := range indexes {
+		for j := 0; j < r; j++ {
+			columns.Set(j, i, m.At(j, index))
+		}
+	}
+	return columns
+}
+
+// Eye - identity matrix n×n
+func Eye(n int) *mat.Dense {
+	d := make([]float64, n*n)
+	for i := 0; i < n*n; i += n + 1 {
+		d[i] = 1
+	}
+	return mat.NewDense(n, n, d)
+}
+
+// MatPrint - print matrix to stdout
+func MatPrint(X mat.Matrix) {
+	MatFprint(os.Stdout, X)
+}
+
+// MatFprint - print matrix to w
+func MatFprint(w io.Writer, X mat.Matrix) {
+	fa := mat.Formatted(X, mat.Prefix(""), mat.Squeeze())
+	fmt.Fprintf(w, "%v\n", fa)
+}
@@ -0,0 +1,281 @@
+package linalg
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// markowitzThreshold - pivots of LU are at least this part of the greatest
+// absolute value of their column, smaller ones would grow rounding errors
+const markowitzThreshold = 0.1
+
+// LUFactor - BasisFactor keeping baseline matrix B as R_k ... R_1 L^-1 B = U.
+// L^-1 is the sequence of eliminations of Gauss method with Markowitz
+// pivoting, R_i are row eliminations of Forrest-Tomlin update i. U is upper
+// triangular in pivot order: row rowOrder[k] has its pivot in column
+// colOrder[k] and zeros in columns colOrder[m], m < k. It's factorized anew
+// after refactorEvery updates or when residual of an update grows over
+// tolerances.Residual
+type LUFactor struct {
+	matrix        *mat.Dense
+	upper         *mat.Dense
+	rowOrder      []int
+	colOrder      []int
+	lower         []elimination
+	updates       []elimination
+	replacements  int
+	refactorEvery int
+	tolerances    FactorTolerances
+}
+
+// elimination - multiples values of rows indexes and row index. Elimination
+// of L subtracts them from rows indexes, row elimination of an update from
+// row index
+type elimination struct {
+	index   int
+	indexes []int
+	values  []float64
+}
+
+// NewLUFactor - LU factorization of square matrix factorized anew after
+// refactorEvery updates. matrix is copied. Returns ErrSingular if it can't be
+// inversed
+func NewLUFactor(matrix *mat.Dense, refactorEvery int, tolerances FactorTolerances) (*LUFactor, error) {
+	f := &LUFactor{matrix: mat.DenseCopyOf(matrix), refactorEvery: refactorEvery, tolerances: tolerances}
+	if err := f.factorize(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// factorize - L and U of matrix without updates. Factors are kept if matrix
+// is singular
+func (f *LUFactor) factorize() error {
+	n := f.Len()
+	a := mat.DenseCopyOf(f.matrix)
+	var lower []elimination
+	rowOrder, colOrder := make([]int, 0, n), make([]int, 0, n)
+	tolerance := f.tolerances.Pivot * math.Max(1, maxAbs(a))
+	rowDone, colDone := make([]bool, n), make([]bool, n)
+	for k := 0; k < n; k++ {
+		row, col := markowitzPivot(a, rowDone, colDone, tolerance)
+		if row < 0 {
+			return ErrSingular
+		}
+		rowDone[row], colDone[col] = true, true
+		rowOrder, colOrder = append(rowOrder, row), append(colOrder, col)
+
+		e, pivot := elimination{index: row}, a.At(row, col)
+		for i := 0; i < n; i++ {
+			if rowDone[i] || a.At(i, col) == 0 {
+				continue
+			}
+			multiplier := a.At(i, col) / pivot
+			for j := 0; j < n; j++ {
+				if !colDone[j] {
+					a.Set(i, j, a.At(i, j)-multiplier*a.At(row, j))
+				}
+			}
+			a.Set(i, col, 0)
+			e.indexes, e.values = append(e.indexes, i), append(e.values, multiplier)
+		}
+		if len(e.indexes) > 0 {
+			lower = append(lower, e)
+		}
+	}
+	f.upper, f.rowOrder, f.colOrder, f.lower = a, rowOrder, colOrder, lower
+	f.updates, f.replacements = nil, 0
+	return nil
+}
+
+// markowitzPivot - entry of rows and columns that aren't done with the least
+// Markowitz count (r - 1)(c - 1) of nonzeros of its row and column, among
+// entries at least markowitzThreshold of the greatest one of their column.
+// Larger entries win ties. Returns -1, -1 if every entry is within tolerance
+// of 0
+func markowitzPivot(a *mat.Dense, rowDone, colDone []bool, tolerance float64) (int, int) {
+	n := len(rowDone)
+	rowCounts, colCounts, colMax := make([]int, n), make([]int, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if value := math.Abs(a.At(i, j)); !rowDone[i] && !colDone[j] && value > tolerance {
+				rowCounts[i]++
+				colCounts[j]++
+				colMax[j] = math.Max(colMax[j], value)
+			}
+		}
+	}
+	bestRow, bestCol, bestCount, bestValue := -1, -1, 0, 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			value := math.Abs(a.At(i, j))
+			if rowDone[i] || colDone[j] || value <= tolerance || value < markowitzThreshold*colMax[j] {
+				continue
+			}
+			count := (rowCounts[i] - 1) * (colCounts[j] - 1)
+			if bestRow < 0 || count < bestCount || (count == bestCount && value > bestValue) {
+				bestRow, bestCol, bestCount, bestValue = i, j, count, value
+			}
+		}
+	}
+	return bestRow, bestCol
+}
+
+// maxAbs - the greatest absolute value of a
+func maxAbs(a *mat.Dense) float64 {
+	greatest := 0.0
+	for _, value := range a.RawMatrix().Data {
+		greatest = math.Max(greatest, math.Abs(value))
+	}
+	return greatest
+}
+
+// Len - number of rows of B
+func (f *LUFactor) Len() int {
+	n, _ := f.matrix.Dims()
+	return n
+}
+
+// transform - R_k ... R_1 L^-1 v
+func (f *LUFactor) transform(v mat.Vector) *mat.VecDense {
+	w := mat.VecDenseCopyOf(v)
+	data := w.RawVector().Data
+	for _, e := range f.lower {
+		value := data[e.index]
+		if value == 0 {
+			continue
+		}
+		for k, i := range e.indexes {
+			data[i] -= e.values[k] * value
+		}
+	}
+	for _, e := range f.updates {
+		for k, i := range e.indexes {
+			data[e.index] -= e.values[k] * data[i]
+		}
+	}
+	return w
+}
+
+// Solve - x of B x = v, back substitution of U x = R_k ... R_1 L^-1 v
+func (f *LUFactor) Solve(v mat.Vector) *mat.VecDense {
+	n := f.Len()
+	w := f.transform(v)
+	x := mat.NewVecDense(n, nil)
+	for k := n - 1; k >= 0; k-- {
+		row, col := f.rowOrder[k], f.colOrder[k]
+		value := w.AtVec(row)
+		for m := k + 1; m < n; m++ {
+			value -= f.upper.At(row, f.colOrder[m]) * x.AtVec(f.colOrder[m])
+		}
+		x.SetVec(col, value/f.upper.At(row, col))
+	}
+	return x
+}
+
+// SolveTranspose - y of y'B = v', y' = z'R_k ... R_1 L^-1 for z of z'U = v'
+func (f *LUFactor) SolveTranspose(v mat.Vector) *mat.VecDense {
+	n := f.Len()
+	z := mat.NewVecDense(n, nil)
+	data := z.RawVector().Data
+	for k := 0; k < n; k++ {
+		row, col := f.rowOrder[k], f.colOrder[k]
+		value := v.AtVec(col)
+		for m := 0; m < k; m++ {
+			value -= f.upper.At(f.rowOrder[m], col) * data[f.rowOrder[m]]
+		}
+		data[row] = value / f.upper.At(row, col)
+	}
+	for u := len(f.updates) - 1; u >= 0; u-- {
+		e := f.updates[u]
+		value := data[e.index]
+		if value == 0 {
+			continue
+		}
+		for k, i := range e.indexes {
+			data[i] -= e.values[k] * value
+		}
+	}
+	for l := len(f.lower) - 1; l >= 0; l-- {
+		e := f.lower[l]
+		value := data[e.index]
+		for k, i := range e.indexes {
+			value -= e.values[k] * data[i]
+		}
+		data[e.index] = value
+	}
+	return z
+}
+
+// Replace - replaces column index of B with vector by Forrest-Tomlin update:
+// column of U gets spike R_k ... R_1 L^-1 vector and moves to the end of pivot
+// order with its pivot row, entries of the row left of the new pivot are
+// eliminated by rows above it. Returns ErrSingular and keeps B if the new
+// matrix can't be inversed
+func (f *LUFactor) Replace(index int, vector mat.Vector) error {
+	z := f.Solve(vector)
+	if math.Abs(z.AtVec(index)) <= f.tolerances.Pivot {
+		return ErrSingular
+	}
+
+	// residual of B z = vector before B changes says how accurate updates are
+	relative := relativeResidual(f.matrix, z, vector)
+	previous := mat.VecDenseCopyOf(f.matrix.ColView(index))
+	f.matrix.SetCol(index, mat.VecDenseCopyOf(vector).RawVector().Data)
+	if f.replacements+1 >= f.refactorEvery || relative > f.tolerances.Residual {
+		return f.refactorize(index, previous)
+	}
+
+	n, position := f.Len(), 0
+	for f.colOrder[position] != index {
+		position++
+	}
+	f.upper.SetCol(index, f.transform(vector).RawVector().Data)
+	row := f.rowOrder[position]
+	copy(f.rowOrder[position:], f.rowOrder[position+1:])
+	copy(f.colOrder[position:], f.colOrder[position+1:])
+	f.rowOrder[n-1], f.colOrder[n-1] = row, index
+
+	e := elimination{index: row}
+	for m := position; m < n-1; m++ {
+		other, col := f.rowOrder[m], f.colOrder[m]
+		value := f.upper.At(row, col)
+		if value == 0 {
+			continue
+		}
+		multiplier := value / f.upper.At(other, col)
+		for k := m + 1; k < n; k++ {
+			j := f.colOrder[k]
+			f.upper.Set(row, j, f.upper.At(row, j)-multiplier*f.upper.At(other, j))
+		}
+		f.upper.Set(row, col, 0)
+		e.indexes, e.values = append(e.indexes, other), append(e.values, multiplier)
+	}
+	if len(e.indexes) > 0 {
+		f.updates = append(f.updates, e)
+	}
+	f.replacements++
+
+	// pivot of the update is z[index] times the old one, it's only lost by
+	// rounding errors
+	if math.Abs(f.upper.At(row, index)) > f.tolerances.Pivot {
+		return nil
+	}
+	if err := f.refactorize(index, previous); err != nil {
+		// factors were updated, so B before replacement is factorized anew
+		f.factorize()
+		return err
+	}
+	return nil
+}
+
+// refactorize - factorizes B anew. If it's singular, column index of B gets
+// previous value back and factors of it are kept
+func (f *LUFactor) refactorize(index int, previous *mat.VecDense) error {
+	if err := f.factorize(); err != nil {
+		f.matrix.SetCol(index, previous.RawVector().Data)
+		return err
+	}
+	return nil
+}
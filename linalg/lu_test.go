@@ -0,0 +1,42 @@
+package linalg
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func newLUFactor(matrix *mat.Dense, refactorEvery int, tolerances FactorTolerances) (BasisFactor, error) {
+	return NewLUFactor(matrix, refactorEvery, tolerances)
+}
+
+func TestLUFactorReplace(t *testing.T) {
+	testReplaceSequence(t, newLUFactor)
+}
+
+func TestLUFactorSingularReplace(t *testing.T) {
+	testSingularReplace(t, newLUFactor)
+}
+
+func TestLUFactorRefactorization(t *testing.T) {
+	matrix := mat.NewDense(3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1})
+	factor, err := NewLUFactor(matrix, 3, testTolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for step, want := range []int{1, 2, 0, 1} {
+		replaceDiagonal(t, factor, step%3)
+		if factor.replacements != want {
+			t.Errorf("replacement %v: %v updates since factorization, want %v", step+1, factor.replacements, want)
+		}
+	}
+
+	factor, err = NewLUFactor(matrix, 100, inaccurateTolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaceDiagonal(t, factor, 1)
+	if factor.replacements != 0 || factor.updates != nil {
+		t.Errorf("inaccurate update is kept, %v updates since factorization", factor.replacements)
+	}
+}
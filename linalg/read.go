@@ -0,0 +1,90 @@
+package linalg
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// InverseUpdate - n×n Matrix, its inverse MatrixInv, column Vector and Index of
+// column to replace, arguments of InvOptimized. Exact holds them as fractions
+// of the problem file
+type InverseUpdate struct {
+	Matrix, MatrixInv *mat.Dense
+	Vector            *mat.VecDense
+	Index             int
+	Exact             *ExactInverseUpdate
+}
+
+// ExactInverseUpdate - values of InverseUpdate as they're written in the
+// problem file, arguments of InvOptimizedOf
+type ExactInverseUpdate struct {
+	Matrix, MatrixInv *Dense[*big.Rat]
+	Vector            []*big.Rat
+}
+
+// ParseMatrixMatrixInvVectorIndex - inverse update of block with rows of
+// matrix, rows of its inverse, a row for every column vector value and index
+// of column to replace (numeration starts from 1 in file)
+func ParseMatrixMatrixInvVectorIndex(block parse.Block) (InverseUpdate, error) {
+	var (
+		update InverseUpdate
+		err    error
+	)
+	n := len(block.Rows[0])
+	if len(block.Rows) != 3*n+1 {
+		return update, block.Errorf(0, "can't infer sizes: expected %v rows of matrix, %v rows of its inverse, %v rows of vector and index, got %v rows", n, n, n, len(block.Rows))
+	}
+	// reading matrix
+	if update.Matrix, err = block.Matrix(0, n, n); err != nil {
+		return update, err
+	}
+	// reading matrixInv
+	if update.MatrixInv, err = block.Matrix(n, n, n); err != nil {
+		return update, err
+	}
+	// reading vector
+	column, err := block.Matrix(2*n, n, 1)
+	if err != nil {
+		return update, err
+	}
+	update.Vector = mat.VecDenseCopyOf(column.ColView(0))
+	exact := ExactInverseUpdate{}
+	rows, err := block.RatMatrix(0, 2*n, n)
+	if err != nil {
+		return update, err
+	}
+	exact.Matrix, exact.MatrixInv = RatDenseOf(rows[:n]), RatDenseOf(rows[n:])
+	values, err := block.RatMatrix(2*n, n, 1)
+	if err != nil {
+		return update, err
+	}
+	for _, row := range values {
+		exact.Vector = append(exact.Vector, row[0])
+	}
+	update.Exact = &exact
+	// reading index
+	index, err := block.Indexes(3*n, 1)
+	if err != nil {
+		return update, err
+	}
+	if update.Index = index[0]; update.Index >= n {
+		return update, block.Errorf(3*n, "index %v is out of matrix", update.Index+1)
+	}
+	return update, nil
+}
+
+// ReadMatrixMatrixInvVectorIndex - inverse update of the first problem of
+// input, see ParseMatrixMatrixInvVectorIndex
+func ReadMatrixMatrixInvVectorIndex(input string) (InverseUpdate, error) {
+	blocks, err := parse.ReadBlocks(input)
+	if err != nil {
+		return InverseUpdate{}, err
+	}
+	if len(blocks) == 0 {
+		return InverseUpdate{}, errors.New(input + ": there's no problem")
+	}
+	return ParseMatrixMatrixInvVectorIndex(blocks[0])
+}
@@ -0,0 +1,380 @@
+package lp
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BoundedSimplexMethod - solves c'x -> max, Ax = b, lower <= x <= upper with
+// the bounded variables simplex method: nonbaseline variables sit at one of
+// their bounds, so upper bounds need no conditions of their own. Bounds may be
+// infinite, nonbaseline free variables are 0. Baseline plan is found by
+// preparation phase with artificial variables. Returns optim.ErrInfeasible,
+// optim.ErrUnbounded, optim.ErrSingularBasis or optim.ErrIterationLimit with a
+// non optimal Result. Optimal Result holds dual solution of the plan, see
+// boundedIterations
+func BoundedSimplexMethod(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense) (optim.Result, error) {
+	return BoundedSimplexMethodContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+}
+
+// BoundedSimplexMethodContext - BoundedSimplexMethod with limits of both
+// phases, see SimplexMainPhaseContext. Entering columns are chosen by
+// options.Pricing, Bland by default
+func BoundedSimplexMethodContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense) (optim.Result, error) {
+	limits := optim.NewLimits(ctx, options)
+	result, err := boundedSimplexMethod(limits, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+	return withInfeasibility(result, err, limits.Tolerances(), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+}
+
+func boundedSimplexMethod(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	if scalesVector.Len() != varNumber || freeVector.Len() != conditionsNumber || lowerBounds.Len() != varNumber || upperBounds.Len() != varNumber {
+		return optim.Result{}, fmt.Errorf("sizes of c, b and bounds don't match %v×%v matrix A", conditionsNumber, varNumber)
+	}
+	for j := 0; j < varNumber; j++ {
+		if lowerBounds.AtVec(j) > upperBounds.AtVec(j) || math.IsInf(lowerBounds.AtVec(j), 1) || math.IsInf(upperBounds.AtVec(j), -1) {
+			result := optim.Result{Status: optim.Infeasible}
+			return result, fmt.Errorf("%w: bounds %v <= x%v <= %v", optim.ErrInfeasible, lowerBounds.AtVec(j), j+1, upperBounds.AtVec(j))
+		}
+	}
+
+	// nonbaseline variables start at a finite bound, artificial variables
+	// make up the difference b - Ax with their signs
+	artificialLength := varNumber + conditionsNumber
+	x := mat.NewVecDense(artificialLength, nil)
+	lower := mat.NewVecDense(artificialLength, nil)
+	upper := mat.NewVecDense(artificialLength, nil)
+	for j := 0; j < varNumber; j++ {
+		lower.SetVec(j, lowerBounds.AtVec(j))
+		upper.SetVec(j, upperBounds.AtVec(j))
+		switch {
+		case !math.IsInf(lowerBounds.AtVec(j), -1):
+			x.SetVec(j, lowerBounds.AtVec(j))
+		case !math.IsInf(upperBounds.AtVec(j), 1):
+			x.SetVec(j, upperBounds.AtVec(j))
+		}
+	}
+	residual := mat.NewVecDense(conditionsNumber, nil)
+	residual.MulVec(conditionsMatrix, x.SliceVec(0, varNumber))
+	residual.SubVec(freeVector, residual)
+	artificialConditionsMatrix := mat.NewDense(conditionsNumber, artificialLength, nil)
+	artificialConditionsMatrix.Slice(0, conditionsNumber, 0, varNumber).(*mat.Dense).Copy(conditionsMatrix)
+	artificialScalesVector := mat.NewVecDense(artificialLength, nil)
+	basis := make([]int, conditionsNumber)
+	for i := 0; i < conditionsNumber; i++ {
+		sign := 1.0
+		if residual.AtVec(i) < 0 {
+			sign = -1
+		}
+		artificialConditionsMatrix.Set(i, varNumber+i, sign)
+		artificialScalesVector.SetVec(varNumber+i, -1)
+		x.SetVec(varNumber+i, math.Abs(residual.AtVec(i)))
+		upper.SetVec(varNumber+i, math.Inf(1))
+		basis[i] = varNumber + i
+	}
+
+	optim.Tracef("Preparation phase of the bounded simplex method\n")
+	preparation, err := boundedIterations(limits, artificialScalesVector, artificialConditionsMatrix, freeVector, lower, upper, x, basis)
+	if err != nil {
+		return boundedResult(preparation, varNumber), err
+	}
+	for i := varNumber; i < artificialLength; i++ {
+		if preparation.X.AtVec(i) > limits.Tolerances().PrimalFeasibility {
+			result := boundedResult(preparation, varNumber)
+			result.Status = optim.Infeasible
+			return result, optim.ErrInfeasible
+		}
+	}
+
+	// artificial variables are fixed at 0, the ones left in basis belong to
+	// linearly dependent conditions
+	x = preparation.X
+	for i := varNumber; i < artificialLength; i++ {
+		x.SetVec(i, 0)
+		upper.SetVec(i, 0)
+	}
+	mainScalesVector := mat.NewVecDense(artificialLength, nil)
+	mainScalesVector.SliceVec(0, varNumber).(*mat.VecDense).CopyVec(scalesVector)
+	optim.Tracef("Main phase of the bounded simplex method\n")
+	result, err := boundedIterations(limits, mainScalesVector, artificialConditionsMatrix, freeVector, lower, upper, x, preparation.Basis)
+	result.Iterations += preparation.Iterations
+	result.Degeneracy.Pivots += preparation.Degeneracy.Pivots
+	return boundedResult(result, varNumber), err
+}
+
+// boundedResult - result without artificial variables: X, Basis and
+// ReducedCosts are of the first varNumber variables
+func boundedResult(result optim.Result, varNumber int) optim.Result {
+	if result.X == nil {
+		return result
+	}
+	result.X = mat.VecDenseCopyOf(result.X.SliceVec(0, varNumber))
+	var basis []int
+	for _, index := range result.Basis {
+		if index < varNumber {
+			basis = append(basis, index)
+		}
+	}
+	result.Basis = basis
+	if result.ReducedCosts != nil {
+		result.ReducedCosts = mat.VecDenseCopyOf(result.ReducedCosts.SliceVec(0, varNumber))
+	}
+	var zeroBasic []int
+	for _, index := range result.Degeneracy.ZeroBasic {
+		if index < varNumber {
+			zeroBasic = append(zeroBasic, index)
+		}
+	}
+	result.Degeneracy.ZeroBasic = zeroBasic
+	return result
+}
+
+// boundedIterations - iterations of the bounded simplex method starting from
+// plan x with basis, nonbaseline variables of x are at their bounds or 0 for
+// free ones. Entering variable goes up from its lower bound if its delta is
+// negative and down from its upper bound if it's positive. Its step is limited
+// by baseline variables reaching their bounds and by its own other bound: then
+// it flips to that bound and basis stays the same. Degeneracy counts pivots
+// with zero step and baseline variables that were at a bound. Optimal Result
+// holds Duals u' = c_B'A_B^-1, ReducedCosts u'A - c, Slacks b - Ax, and
+// DualObjective u'b - sum of deltas times values of nonbaseline variables
+func boundedIterations(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lower, upper, x *mat.VecDense, basis []int) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	x = mat.VecDenseCopyOf(x)
+	basis, tolerances := append([]int{}, basis...), limits.Tolerances()
+	pricing := pricingRule(limits)
+	pricing.Start(varNumber)
+	var baselineFactor linalg.BasisFactor
+	replacedIndex := -1
+	degeneratePivots, zeroBasic := 0, make([]bool, varNumber)
+	observedInfeasibility := 0.0
+	for iteration := 0; ; iteration++ {
+		observedInfeasibility = math.Max(observedInfeasibility, primalInfeasibility(conditionsMatrix, freeVector, lower, upper, x))
+		for _, index := range basis {
+			if atBound(x.AtVec(index), lower.AtVec(index), upper.AtVec(index), tolerances.Zero) {
+				zeroBasic[index] = true
+			}
+		}
+		result := optim.Result{
+			Objective:           mat.Dot(scalesVector, x),
+			X:                   x,
+			Basis:               append([]int{}, basis...),
+			Iterations:          iteration,
+			Degeneracy:          degeneracy(degeneratePivots, zeroBasic),
+			PrimalInfeasibility: observedInfeasibility,
+		}
+
+		// factorization of baseline matrix is updated after pivots and kept
+		// after bound flips
+		switch {
+		case baselineFactor == nil:
+			factor, err := newBasisFactor(limits, linalg.Columns(conditionsMatrix, basis))
+			if err != nil {
+				result.Status = optim.SingularBasis
+				return result, optim.ErrSingularBasis
+			}
+			baselineFactor = factor
+		case replacedIndex >= 0:
+			if err := baselineFactor.Replace(replacedIndex, conditionsMatrix.ColView(basis[replacedIndex])); err != nil {
+				result.Status = optim.SingularBasis
+				return result, err
+			}
+		}
+
+		components := mat.NewVecDense(conditionsNumber, nil)
+		for i, index := range basis {
+			components.SetVec(i, scalesVector.AtVec(index))
+		}
+		potentials := baselineFactor.SolveTranspose(components)
+		scoreVector := linalg.VecMulMat(potentials, conditionsMatrix)
+		scoreVector.AddScaledVec(scoreVector, -1, scalesVector)
+
+		// pricing rule sees scores of improving directions as negative ones:
+		// -delta[j] for variables able to go down, fixed variables and
+		// variables at the bound delta pushes them to score 0
+		nonbasicIndexes := nonbasic(varNumber, basis)
+		scores := mat.NewVecDense(varNumber, nil)
+		for _, j := range nonbasicIndexes {
+			switch delta := scoreVector.AtVec(j); {
+			case delta < 0 && x.AtVec(j) < upper.AtVec(j):
+				scores.SetVec(j, delta)
+			case delta > 0 && x.AtVec(j) > lower.AtVec(j):
+				scores.SetVec(j, -delta)
+			}
+		}
+		state := optim.PricingState{
+			Scores:     scores,
+			Tolerance:  tolerances.DualFeasibility,
+			Basis:      result.Basis,
+			Nonbasic:   nonbasicIndexes,
+			Factor:     baselineFactor,
+			Conditions: conditionsMatrix,
+		}
+		entering := pricing.Entering(state)
+		if entering < 0 {
+			optim.Tracef("no nonbaseline variable improves objective, plan is optimal\n")
+			optim.TraceMatrix(x)
+			result.Status = optim.Optimal
+			result.Duals, result.ReducedCosts, result.InverseBasis = potentials, scoreVector, linalg.InverseOf(baselineFactor)
+			result.Slacks = mat.NewVecDense(conditionsNumber, nil)
+			result.Slacks.MulVec(conditionsMatrix, x)
+			result.Slacks.SubVec(freeVector, result.Slacks)
+			result.DualObjective = mat.Dot(potentials, freeVector)
+			for _, j := range nonbasicIndexes {
+				result.DualObjective -= scoreVector.AtVec(j) * x.AtVec(j)
+			}
+			return result, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+
+		// x[entering] changes by direction*theta, baseline values by
+		// -direction*theta*z
+		direction := 1.0
+		if scoreVector.AtVec(entering) > 0 {
+			direction = -1
+		}
+		zVector := baselineFactor.Solve(conditionsMatrix.ColView(entering))
+		minTheta, leaving, leavingValue := upper.AtVec(entering)-lower.AtVec(entering), -1, 0.0
+		for k, index := range basis {
+			// entries of z close to 0 are left by rounding errors
+			rate := -direction * zVector.AtVec(k)
+			theta, bound := math.Inf(1), 0.0
+			switch {
+			case rate < -tolerances.Pivot && !math.IsInf(lower.AtVec(index), -1):
+				bound = lower.AtVec(index)
+				theta = math.Max(x.AtVec(index)-bound, 0) / -rate
+			case rate > tolerances.Pivot && !math.IsInf(upper.AtVec(index), 1):
+				bound = upper.AtVec(index)
+				theta = math.Max(bound-x.AtVec(index), 0) / rate
+			}
+			if theta < minTheta {
+				minTheta, leaving, leavingValue = theta, k, bound
+			}
+		}
+		if math.IsInf(minTheta, 1) {
+			optim.Tracef("variable %v improves objective without bound\n", entering+1)
+			result.Status = optim.Unbounded
+			return result, optim.ErrUnbounded
+		}
+		if minTheta <= tolerances.Zero {
+			degeneratePivots++
+			optim.Tracef("step is 0, pivot is degenerate\n")
+		}
+
+		x = mat.VecDenseCopyOf(x)
+		for k, index := range basis {
+			x.SetVec(index, x.AtVec(index)-direction*minTheta*zVector.AtVec(k))
+		}
+		if leaving < 0 {
+			// bound flip, the other bound is exact
+			optim.Tracef("variable %v flips to its other bound\n", entering+1)
+			if direction > 0 {
+				x.SetVec(entering, upper.AtVec(entering))
+			} else {
+				x.SetVec(entering, lower.AtVec(entering))
+			}
+			replacedIndex = -1
+			continue
+		}
+		optim.Tracef("variable %v replaces baseline variable %v\n", entering+1, basis[leaving]+1)
+		pricing.Update(state, entering, leaving, zVector)
+		x.SetVec(entering, x.AtVec(entering)+direction*minTheta)
+		x.SetVec(basis[leaving], leavingValue)
+		basis[leaving], replacedIndex = entering, leaving
+	}
+}
+
+// atBound - whether value is within tolerance of a finite bound
+func atBound(value, lower, upper, tolerance float64) bool {
+	return math.Abs(value-lower) <= tolerance || math.Abs(value-upper) <= tolerance
+}
+
+// SolveBounded - solves problem with the bounded simplex method. Inequalities
+// get slack variables, bounds of variables stay bounds instead of becoming
+// conditions of canonical form. Result is of the original problem as
+// CanonicalProblem.Original makes it, Basis holds indexes of variables and
+// then slack variables of inequalities
+func (p GeneralProblem) SolveBounded() (optim.Result, error) {
+	return p.SolveBoundedContext(context.Background(), optim.Options{})
+}
+
+// SolveBoundedContext - SolveBounded with limits, see
+// BoundedSimplexMethodContext
+func (p GeneralProblem) SolveBoundedContext(ctx context.Context, options optim.Options) (optim.Result, error) {
+	conditionsNumber, varNumber := p.ConditionsMatrix.Dims()
+	slacksNumber := 0
+	for _, relation := range p.Relations {
+		if relation != Equal {
+			slacksNumber++
+		}
+	}
+	columnsNumber := varNumber + slacksNumber
+	sense := 1.
+	if p.Sense == Minimize {
+		sense = -1
+	}
+
+	scalesVector := mat.NewVecDense(columnsNumber, nil)
+	conditionsMatrix := mat.NewDense(conditionsNumber, columnsNumber, nil)
+	lowerBounds := mat.NewVecDense(columnsNumber, nil)
+	upperBounds := mat.NewVecDense(columnsNumber, nil)
+	for j := 0; j < varNumber; j++ {
+		scalesVector.SetVec(j, sense*p.ScalesVector.AtVec(j))
+		lowerBounds.SetVec(j, p.LowerBounds.AtVec(j))
+		upperBounds.SetVec(j, p.UpperBounds.AtVec(j))
+	}
+	slack := varNumber
+	for i := 0; i < conditionsNumber; i++ {
+		for j := 0; j < varNumber; j++ {
+			conditionsMatrix.Set(i, j, p.ConditionsMatrix.At(i, j))
+		}
+		switch p.Relations[i] {
+		case LessEqual:
+			conditionsMatrix.Set(i, slack, 1)
+		case GreaterEqual:
+			conditionsMatrix.Set(i, slack, -1)
+		default:
+			continue
+		}
+		upperBounds.SetVec(slack, math.Inf(1))
+		slack++
+	}
+
+	result, err := BoundedSimplexMethodContext(ctx, options, scalesVector, conditionsMatrix, p.FreeVector, lowerBounds, upperBounds)
+	if result.X == nil {
+		return result, err
+	}
+	x := mat.VecDenseCopyOf(result.X.SliceVec(0, varNumber))
+	result.X, result.Objective, result.InverseBasis = x, mat.Dot(p.ScalesVector, x), nil
+	if result.Duals == nil {
+		return result, err
+	}
+
+	// + 0 turns -0 left by rounding into 0
+	result.DualObjective *= sense
+	for i := 0; i < conditionsNumber; i++ {
+		result.Duals.SetVec(i, sense*result.Duals.AtVec(i)+0)
+	}
+	reducedCosts := mat.NewVecDense(varNumber, nil)
+	for j := 0; j < varNumber; j++ {
+		reducedCosts.SetVec(j, -sense*result.ReducedCosts.AtVec(j)+0)
+	}
+	slacks := mat.NewVecDense(conditionsNumber, nil)
+	slacks.MulVec(p.ConditionsMatrix, x)
+	slacks.SubVec(p.FreeVector, slacks)
+	for i, relation := range p.Relations {
+		if relation == GreaterEqual {
+			slacks.SetVec(i, -slacks.AtVec(i))
+		}
+	}
+	result.ReducedCosts, result.Slacks = reducedCosts, slacks
+	return result, err
+}
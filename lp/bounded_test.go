@@ -0,0 +1,75 @@
+package lp
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestBoundedIterationsFlips(t *testing.T) {
+	// x1 + x2 -> max, x1 + x2 + x3 = 10, x3 >= 0 is slack, starting from
+	// x = (0, 0, 10) with basis of x3. x1 and x2 flip to their upper bounds
+	// if they're reached before x3 gets 0, otherwise they replace x3
+	inf := math.Inf(1)
+	tests := []struct {
+		name       string
+		upper      []float64
+		x          []float64
+		basis      []int
+		iterations int
+	}{
+		{"both flip", []float64{2, 3, inf}, []float64{2, 3, 5}, []int{2}, 2},
+		{"x1 flips, x2 replaces x3", []float64{2, 20, inf}, []float64{2, 8, 0}, []int{1}, 2},
+		{"x1 replaces x3", []float64{20, 20, inf}, []float64{10, 0, 0}, []int{0}, 1},
+	}
+	for _, test := range tests {
+		limits := optim.NewLimits(context.Background(), optim.Options{})
+		result, err := boundedIterations(limits, mat.NewVecDense(3, []float64{1, 1, 0}), mat.NewDense(1, 3, []float64{1, 1, 1}),
+			mat.NewVecDense(1, []float64{10}), mat.NewVecDense(3, nil), mat.NewVecDense(3, test.upper),
+			mat.NewVecDense(3, []float64{0, 0, 10}), []int{2})
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if want := mat.NewVecDense(3, test.x); !mat.EqualApprox(result.X, want, 1e-9) || !equalInts(result.Basis, test.basis) || result.Iterations != test.iterations {
+			t.Errorf("%v: x = %v with basis %v after %v iterations, want %v with %v after %v", test.name,
+				mat.Formatted(result.X.T()), result.Basis, result.Iterations, mat.Formatted(want.T()), test.basis, test.iterations)
+		}
+		if math.Abs(result.DualObjective-result.Objective) > 1e-9 {
+			t.Errorf("%v: dual objective %v, objective %v", test.name, result.DualObjective, result.Objective)
+		}
+	}
+}
+
+func TestBoundedSimplexMethodMatchesCanonicalForm(t *testing.T) {
+	// upper bounds of the bounded problem are conditions x + s = upper of
+	// the canonical one
+	scalesVector := mat.NewVecDense(3, []float64{3, 2, 4})
+	conditionsMatrix := mat.NewDense(2, 3, []float64{1, 1, 2, 2, 0, 3})
+	freeVector := mat.NewVecDense(2, []float64{4, 5})
+	upperBounds := mat.NewVecDense(3, []float64{2, 3, 1})
+	result, err := BoundedSimplexMethod(scalesVector, conditionsMatrix, freeVector, mat.NewVecDense(3, nil), upperBounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canonicalScales := mat.NewVecDense(6, []float64{3, 2, 4, 0, 0, 0})
+	canonicalConditions := mat.NewDense(5, 6, []float64{
+		1, 1, 2, 0, 0, 0,
+		2, 0, 3, 0, 0, 0,
+		1, 0, 0, 1, 0, 0,
+		0, 1, 0, 0, 1, 0,
+		0, 0, 1, 0, 0, 1,
+	})
+	canonicalFree := mat.NewVecDense(5, []float64{4, 5, 2, 3, 1})
+	canonical, err := solveCanonical(optim.NewLimits(context.Background(), optim.Options{}), canonicalScales, canonicalConditions, canonicalFree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.Objective-canonical.Objective) > 1e-9 || !mat.EqualApprox(result.X, canonical.X.SliceVec(0, 3), 1e-9) {
+		t.Errorf("x = %v with objective %v, canonical form gives %v with %v", mat.Formatted(result.X.T()), result.Objective, mat.Formatted(canonical.X.T()), canonical.Objective)
+	}
+}
@@ -0,0 +1,35 @@
+// Package lp solves linear optimization problems in canonical form
+//
+//	c'x -> max, Ax = b, x >= 0
+//
+// with the main phase of the simplex method, its preparation (first) phase and
+// the dual simplex method. BoundedSimplexMethod solves problems with bounds
+// lower <= x <= upper in place of x >= 0 without conditions for the bounds,
+// BoundedDoubleSimplexMethod is the dual simplex method for them with bound
+// flipping ratio test. Both dual methods start from basis found by dual phase
+// 1, see DualFeasibleBasis, when the given one isn't dual feasible. Problems in general form with min objective,
+// inequality conditions and variable bounds are converted to canonical form by
+// GeneralProblem.Canonical. General form problems are read from the labs input
+// files, MPS files and CPLEX LP files. Entering columns of the main phase are
+// chosen by pricing rule of optim.Options: Bland, Dantzig, Partial, Devex or
+// SteepestEdge, ties of degenerate pivots are broken as optim.AntiCycling of
+// optim.Options asks. The primal, dual and two-phase simplex methods keep
+// baseline matrix as linalg.BasisFactor chosen by optim.Options.Factorization
+// and factorized anew every optim.Options.RefactorEvery updates.
+// Optimal basis is analysed by SensitivityAnalysis for
+// ranges of c and b keeping it optimal. ParametricObjective and
+// ParametricFreeValues follow the optimal basis while c or b change along a
+// direction and find breakpoints where it stops being optimal. Solver keeps
+// the last basis and re-optimizes from it after changes of c, b, columns and
+// rows. BranchAndBound solves problems with integer variables, relaxations of
+// its branches are solved with the dual simplex method from the optimal basis
+// of their parent, GomoryCuts solves pure integer problems with fractional
+// cuts. ExactSimplexMainPhase, ExactDoubleSimplexMethod and ExactGomoryCuts
+// compute with math/big.Rat fractions of linalg.Rational instead of float64
+// values, ExactProblem holds values of the problem file as such fractions.
+// Tolerances of optim.Options say which values are feasible, optimal, zero or
+// too small to pivot on, results report the greatest PrimalInfeasibility and
+// DualInfeasibility of their plans and of the iterations before them.
+// Optimal plans with PrimalInfeasibility beyond the tolerance aren't solved,
+// they're returned with optim.ErrPrimalInfeasibility.
+package lp
@@ -0,0 +1,361 @@
+package lp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DoubleSimplexMethod - solves optimization problem in canonical form with the
+// dual simplex method starting from dual feasible baselineIndexes. If they're
+// nil, singular or not dual feasible, the basis is found by dual phase 1, see
+// DualFeasibleBasis. Returns optim.ErrInfeasible if the problem has no plan,
+// optim.ErrUnbounded if it has plans but no dual plan, optim.ErrSingularBasis
+// or optim.ErrIterationLimit with a non optimal Result
+func DoubleSimplexMethod(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	return DoubleSimplexMethodContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector, baselineIndexes)
+}
+
+// DoubleSimplexMethodContext - DoubleSimplexMethod stopped with
+// optim.ErrCanceled when ctx is done or options.TimeLimit passes and with
+// optim.ErrIterationLimit after options.MaxIterations pivots. Result of stopped
+// run holds the pseudo plan of the last dual feasible basis
+func DoubleSimplexMethodContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	limits := optim.NewLimits(ctx, options)
+	_, varNumber := conditionsMatrix.Dims()
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	basis, start, err := startDual(limits, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds, baselineIndexes)
+	if basis == nil {
+		return withInfeasibility(start, err, limits.Tolerances(), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+	}
+	result, err := doubleSimplexMethod(limits, scalesVector, conditionsMatrix, freeVector, linalg.FloatVector(basis))
+	result.Iterations += start.Iterations
+	return withInfeasibility(result, err, limits.Tolerances(), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+}
+
+func doubleSimplexMethod(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, baselineIndexes *mat.VecDense) (optim.Result, error) {
+	var yVector *mat.VecDense
+	var baselineFactor linalg.BasisFactor
+	tolerances := limits.Tolerances()
+	replacedIndex := -1
+	lowerBounds, upperBounds := nonnegativeBounds(scalesVector.Len())
+	observedInfeasibility := 0.0
+	for iteration := 0; ; iteration++ {
+		optim.Tracef("New iteration\n")
+		// conditionsNumber - rows, varNumber - columns
+		conditionsNumber, varNumber := conditionsMatrix.Dims()
+		result := optim.Result{
+			Basis:      linalg.IntVector(baselineIndexes),
+			Iterations: iteration,
+		}
+		var nonBaseLineIndexes []int
+		for i := 0; i < varNumber; i++ {
+			if !linalg.FindInt(result.Basis, i) {
+				nonBaseLineIndexes = append(nonBaseLineIndexes, i)
+			}
+		}
+
+		// BaselineVector and factorization of baselinematrix, updated after
+		// pivots
+		baselineVector := mat.NewVecDense(conditionsNumber, nil)
+		for i := 0; i < conditionsNumber; i++ {
+			baselineVector.SetVec(i, scalesVector.AtVec(int(baselineIndexes.AtVec(i))))
+		}
+		if baselineFactor == nil {
+			factor, err := newBasisFactor(limits, linalg.Columns(conditionsMatrix, result.Basis))
+			if err != nil {
+				result.Status = optim.SingularBasis
+				return result, optim.ErrSingularBasis
+			}
+			baselineFactor = factor
+		} else if err := baselineFactor.Replace(replacedIndex, conditionsMatrix.ColView(result.Basis[replacedIndex])); err != nil {
+			result.Status = optim.SingularBasis
+			return result, err
+		}
+
+		// Vector Kappa
+		baselineKappa := baselineFactor.Solve(freeVector)
+
+		kappa := mat.NewVecDense(varNumber, nil)
+		for i := 0; i < conditionsNumber; i++ {
+			kappa.SetVec(int(baselineIndexes.AtVec(i)), baselineKappa.AtVec(i))
+		}
+		result.X = kappa
+		result.Objective = mat.Dot(scalesVector, kappa)
+
+		// every basis should keep deltas y'A - c >= 0, the greatest violation
+		// of them is reported
+		if yVector == nil {
+			yVector = baselineFactor.SolveTranspose(baselineVector)
+		}
+		deltas := linalg.VecMulMat(yVector, conditionsMatrix)
+		deltas.AddScaledVec(deltas, -1, scalesVector)
+		observedInfeasibility = math.Max(observedInfeasibility, dualInfeasibility(deltas, lowerBounds, upperBounds, kappa))
+		result.DualInfeasibility = observedInfeasibility
+
+		// Checking if kappa is optimal case
+		isOptimalCase, negativeBaselineIndex := true, -1
+		for i := 0; i < conditionsNumber; i++ {
+			if baselineKappa.AtVec(i) < -tolerances.PrimalFeasibility {
+				isOptimalCase = false
+				negativeBaselineIndex = i
+				break // if break is commented, last negative value will be observed, otherwise first
+			}
+		}
+		if isOptimalCase {
+			optim.Tracef("current kappa is positive everywhere, end.\n")
+			optim.TraceMatrix(kappa)
+			result.Status = optim.Optimal
+			return result, nil
+		}
+		optim.Tracef("current kappa is not positive everywhere\n")
+		optim.TraceMatrix(kappa)
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+
+		// y Deltavector is row of inversed baselinematrix with index of
+		// negative kappa value
+		yDeltaVector := linalg.InverseRow(baselineFactor, negativeBaselineIndex)
+		optim.Tracef("yDeltaVector\n")
+		optim.TraceMatrix(yDeltaVector)
+
+		muList := make([]float64, len(nonBaseLineIndexes))
+		// for nonbaseline indexes
+		for i, index := range nonBaseLineIndexes {
+			muList[i] = mat.Dot(yDeltaVector, conditionsMatrix.ColView(index))
+		}
+
+		// If there's nothing to change, problem is not consistent. Values above
+		// -tolerances.Pivot are left by rounding errors
+		isConsistent := false
+		for i := range muList {
+			if muList[i] < -tolerances.Pivot {
+				isConsistent = true
+				break
+			}
+		}
+		if !isConsistent {
+			result.Status = optim.Infeasible
+			return result, optim.ErrInfeasible
+		}
+
+		// Finding min sigma and its index
+		minSigma, minSigmaIndex := math.Inf(1), -1
+		for i, index := range nonBaseLineIndexes {
+			if muList[i] < -tolerances.Pivot {
+				Cj := scalesVector.AtVec(index)
+				Aj := mat.Dot(conditionsMatrix.ColView(index), yVector)
+				currentSigma := (Cj - Aj) / muList[i]
+				if currentSigma < minSigma {
+					minSigma, minSigmaIndex = currentSigma, index
+				}
+			}
+		}
+		// Changing dual plan (baseline indexes)
+		newBaselineIndexes := mat.VecDenseCopyOf(baselineIndexes)
+		newBaselineIndexes.SetVec(negativeBaselineIndex, float64(minSigmaIndex))
+		optim.Tracef("newBaselineIndexes\n")
+		optim.TraceMatrix(newBaselineIndexes)
+		yDeltaVector.ScaleVec(minSigma, yDeltaVector)
+
+		// Updating y vector by adding y vector and scaled y delta vector
+		yVector = mat.VecDenseCopyOf(yVector)
+		yVector.AddVec(yVector, yDeltaVector)
+
+		// Next iteration
+		baselineIndexes, replacedIndex = newBaselineIndexes, negativeBaselineIndex
+	}
+}
+
+// BoundedDoubleSimplexMethod - solves c'x -> max, Ax = b, lower <= x <= upper
+// with the dual simplex method starting from dual feasible baselineIndexes.
+// Nonbaseline variables with delta > 0 sit at lower bound, with delta < 0 at
+// upper one, so these bounds must be finite. Leaving variable is chosen by
+// the bound flipping ratio test: nonbaseline variables whose dual step is
+// shorter flip to their other bound while the leaving variable stays out of
+// its bounds after that. Result holds the last pseudo plan in X, dual plan y
+// in Duals and deltas y'A - c in ReducedCosts. baselineIndexes that are nil,
+// singular or not dual feasible are replaced by basis of dual phase 1, see
+// DualFeasibleBasis. Returns optim.ErrInfeasible if the problem has no plan,
+// optim.ErrUnbounded if it has plans but no dual plan, optim.ErrSingularBasis
+// or optim.ErrIterationLimit with a non optimal Result
+func BoundedDoubleSimplexMethod(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	return BoundedDoubleSimplexMethodContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds, baselineIndexes)
+}
+
+// BoundedDoubleSimplexMethodContext - BoundedDoubleSimplexMethod with limits,
+// see DoubleSimplexMethodContext
+func BoundedDoubleSimplexMethodContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	if scalesVector.Len() != varNumber || freeVector.Len() != conditionsNumber || lowerBounds.Len() != varNumber || upperBounds.Len() != varNumber {
+		return optim.Result{}, fmt.Errorf("sizes of c, b and bounds don't match %v×%v matrix A", conditionsNumber, varNumber)
+	}
+	limits := optim.NewLimits(ctx, options)
+	basis, start, err := startDual(limits, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds, baselineIndexes)
+	if basis == nil {
+		return withInfeasibility(start, err, limits.Tolerances(), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+	}
+	result, err := boundedDoubleSimplexMethod(limits, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds, basis)
+	result.Iterations += start.Iterations
+	return withInfeasibility(result, err, limits.Tolerances(), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+}
+
+func boundedDoubleSimplexMethod(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	if scalesVector.Len() != varNumber || freeVector.Len() != conditionsNumber || lowerBounds.Len() != varNumber || upperBounds.Len() != varNumber {
+		return optim.Result{}, fmt.Errorf("sizes of c, b and bounds don't match %v×%v matrix A", conditionsNumber, varNumber)
+	}
+	if len(baselineIndexes) != conditionsNumber {
+		return optim.Result{}, fmt.Errorf("basis has %v indexes for %v conditions", len(baselineIndexes), conditionsNumber)
+	}
+	basis, tolerances := append([]int{}, baselineIndexes...), limits.Tolerances()
+	var baselineFactor linalg.BasisFactor
+	var atUpper []bool
+	replacedIndex := -1
+	observedInfeasibility := 0.0
+	for iteration := 0; ; iteration++ {
+		result := optim.Result{Basis: append([]int{}, basis...), Iterations: iteration}
+		if baselineFactor == nil {
+			factor, err := newBasisFactor(limits, linalg.Columns(conditionsMatrix, basis))
+			if err != nil {
+				result.Status = optim.SingularBasis
+				return result, optim.ErrSingularBasis
+			}
+			baselineFactor = factor
+		} else if err := baselineFactor.Replace(replacedIndex, conditionsMatrix.ColView(basis[replacedIndex])); err != nil {
+			result.Status = optim.SingularBasis
+			return result, err
+		}
+
+		// dual plan y' = c_B'A_B^-1 and deltas y'A - c
+		components := mat.NewVecDense(conditionsNumber, nil)
+		for i, index := range basis {
+			components.SetVec(i, scalesVector.AtVec(index))
+		}
+		yVector := baselineFactor.SolveTranspose(components)
+		deltas := linalg.VecMulMat(yVector, conditionsMatrix)
+		deltas.AddScaledVec(deltas, -1, scalesVector)
+		nonbasicIndexes := nonbasic(varNumber, basis)
+
+		// nonbaseline variables are placed by signs of deltas on the first
+		// iteration and moved by pivots and flips after that
+		if atUpper == nil {
+			atUpper = make([]bool, varNumber)
+			for _, j := range nonbasicIndexes {
+				delta := deltas.AtVec(j)
+				atUpper[j] = delta < -tolerances.DualFeasibility || (delta <= tolerances.DualFeasibility && math.IsInf(lowerBounds.AtVec(j), -1))
+				if bound := nonbaselineValue(j, atUpper, lowerBounds, upperBounds); math.IsInf(bound, 0) {
+					result.Status = optim.NotSolved
+					return result, fmt.Errorf("basis isn't dual feasible: delta[%v] = %v and x%v has no bound to sit at", j+1, delta, j+1)
+				}
+			}
+		}
+
+		// pseudo plan: nonbaseline variables at their bounds, baseline ones
+		// solve A_B x_B = b - A_N x_N
+		kappa := mat.NewVecDense(varNumber, nil)
+		for _, j := range nonbasicIndexes {
+			kappa.SetVec(j, nonbaselineValue(j, atUpper, lowerBounds, upperBounds))
+		}
+		rest := mat.NewVecDense(conditionsNumber, nil)
+		rest.MulVec(conditionsMatrix, kappa)
+		rest.SubVec(freeVector, rest)
+		baselineKappa := baselineFactor.Solve(rest)
+		for i, index := range basis {
+			kappa.SetVec(index, baselineKappa.AtVec(i))
+		}
+		result.X, result.Objective = kappa, mat.Dot(scalesVector, kappa)
+		result.Duals, result.ReducedCosts = yVector, deltas
+		observedInfeasibility = math.Max(observedInfeasibility, dualInfeasibility(deltas, lowerBounds, upperBounds, kappa))
+		result.DualInfeasibility = observedInfeasibility
+		result.Slacks = mat.NewVecDense(conditionsNumber, nil)
+		result.Slacks.MulVec(conditionsMatrix, kappa)
+		result.Slacks.SubVec(freeVector, result.Slacks)
+		result.DualObjective = mat.Dot(yVector, freeVector)
+		for _, j := range nonbasicIndexes {
+			result.DualObjective -= deltas.AtVec(j) * kappa.AtVec(j)
+		}
+
+		// the first baseline variable out of its bounds leaves, mu is 1 if it
+		// goes to lower bound and -1 if to upper one
+		leaving, mu, infeasibility := -1, 0.0, 0.0
+		for i, index := range basis {
+			if value := kappa.AtVec(index); value < lowerBounds.AtVec(index)-tolerances.PrimalFeasibility {
+				leaving, mu, infeasibility = i, 1, lowerBounds.AtVec(index)-value
+			} else if value > upperBounds.AtVec(index)+tolerances.PrimalFeasibility {
+				leaving, mu, infeasibility = i, -1, value-upperBounds.AtVec(index)
+			}
+			if leaving >= 0 {
+				break
+			}
+		}
+		if leaving < 0 {
+			optim.Tracef("pseudo plan is within bounds, end.\n")
+			optim.TraceMatrix(kappa)
+			result.Status, result.InverseBasis = optim.Optimal, linalg.InverseOf(baselineFactor)
+			return result, nil
+		}
+		optim.Tracef("baseline variable %v = %v is out of its bounds\n", basis[leaving]+1, kappa.AtVec(basis[leaving]))
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+
+		// dual step along mu*row of A_B^-1 keeps deltas of nonbaseline
+		// variables of their signs up to sigma[j]
+		deltaY := linalg.InverseRow(baselineFactor, leaving)
+		deltaY.ScaleVec(mu, deltaY)
+		type candidate struct {
+			index        int
+			sigma, alpha float64
+		}
+		var candidates []candidate
+		for _, j := range nonbasicIndexes {
+			alpha := mat.Dot(deltaY, conditionsMatrix.ColView(j))
+			if (!atUpper[j] && alpha < -tolerances.Pivot) || (atUpper[j] && alpha > tolerances.Pivot) {
+				sigma := math.Max(-deltas.AtVec(j)/alpha, 0)
+				candidates = append(candidates, candidate{index: j, sigma: sigma, alpha: alpha})
+			}
+		}
+		if len(candidates) == 0 {
+			result.Status = optim.Infeasible
+			return result, optim.ErrInfeasible
+		}
+		sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].sigma < candidates[b].sigma })
+
+		// bound flipping: passing sigma of a candidate lowers slope of the dual
+		// objective by its alpha times its bounds range, candidates passed with
+		// positive slope left flip and the next one enters
+		slope, entering := infeasibility, len(candidates)-1
+		for k, c := range candidates {
+			slope -= math.Abs(c.alpha) * (upperBounds.AtVec(c.index) - lowerBounds.AtVec(c.index))
+			if slope <= tolerances.Zero || math.IsNaN(slope) {
+				entering = k
+				break
+			}
+		}
+		for _, c := range candidates[:entering] {
+			optim.Tracef("variable %v flips to its other bound\n", c.index+1)
+			atUpper[c.index] = !atUpper[c.index]
+		}
+		optim.Tracef("variable %v replaces baseline variable %v\n", candidates[entering].index+1, basis[leaving]+1)
+		atUpper[basis[leaving]] = mu < 0
+		atUpper[candidates[entering].index] = false
+		basis[leaving], replacedIndex = candidates[entering].index, leaving
+	}
+}
+
+// nonbaselineValue - bound nonbaseline variable j sits at
+func nonbaselineValue(j int, atUpper []bool, lowerBounds, upperBounds *mat.VecDense) float64 {
+	if atUpper[j] {
+		return upperBounds.AtVec(j)
+	}
+	return lowerBounds.AtVec(j)
+}
@@ -0,0 +1,53 @@
+package lp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestBoundedDoubleSimplexMethodRatioTest(t *testing.T) {
+	// -x1 - 2x2 -> max, x1 + x2 - x3 = b, 0 <= x1 <= 2, 0 <= x2 <= 5,
+	// x3 >= 0 starting from dual feasible basis of x3 = -b. x1 has the least
+	// dual step and enters if b fits its bounds range, otherwise passing its
+	// step keeps dual objective growing, so it flips to 2 and x2 enters
+	inf := math.Inf(1)
+	tests := []struct {
+		name  string
+		b     float64
+		x     []float64
+		basis []int
+	}{
+		{"x1 enters", 1.5, []float64{1.5, 0, 0}, []int{0}},
+		{"x1 flips, x2 enters", 3, []float64{2, 1, 0}, []int{1}},
+	}
+	for _, test := range tests {
+		limits := optim.NewLimits(context.Background(), optim.Options{})
+		result, err := boundedDoubleSimplexMethod(limits, mat.NewVecDense(3, []float64{-1, -2, 0}), mat.NewDense(1, 3, []float64{1, 1, -1}),
+			mat.NewVecDense(1, []float64{test.b}), mat.NewVecDense(3, nil), mat.NewVecDense(3, []float64{2, 5, inf}), []int{2})
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if want := mat.NewVecDense(3, test.x); result.Status != optim.Optimal || !mat.EqualApprox(result.X, want, 1e-9) || !equalInts(result.Basis, test.basis) || result.Iterations != 1 {
+			t.Errorf("%v: %v x = %v with basis %v after %v iterations, want optimal %v with %v after 1", test.name,
+				result.Status, mat.Formatted(result.X.T()), result.Basis, result.Iterations, mat.Formatted(want.T()), test.basis)
+		}
+		if math.Abs(result.DualObjective-result.Objective) > 1e-9 {
+			t.Errorf("%v: dual objective %v, objective %v", test.name, result.DualObjective, result.Objective)
+		}
+	}
+}
+
+func TestBoundedDoubleSimplexMethodInfeasible(t *testing.T) {
+	// x1 + x2 - x3 = 10 can't be reached with x1 <= 2, x2 <= 5, x3 >= 0
+	_, err := BoundedDoubleSimplexMethod(mat.NewVecDense(3, []float64{-1, -2, 0}), mat.NewDense(1, 3, []float64{1, 1, -1}),
+		mat.NewVecDense(1, []float64{10}), mat.NewVecDense(3, nil), mat.NewVecDense(3, []float64{2, 5, math.Inf(1)}), []int{2})
+	if !errors.Is(err, optim.ErrInfeasible) {
+		t.Errorf("error %v, want %v", err, optim.ErrInfeasible)
+	}
+}
@@ -0,0 +1,173 @@
+package lp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DualFeasibleBasis - dual feasible basis of c'x -> max, Ax = b,
+// lower <= x <= upper found by dual phase 1. Dual phase 1 solves auxiliary
+// problem c'x -> max, Ax = 0 with bounds [0, 1] of variables having only lower
+// bound, [-1, 0] having only upper one, [-1, 1] of free ones and [0, 0] of
+// the rest with the dual simplex method: every basis of it is dual feasible.
+// Its optimal basis is dual feasible for the problem unless the problem has no
+// dual plan. baselineIndexes are the basis to start from, they may be nil.
+// Returns optim.ErrUnbounded if there's no dual feasible basis: the problem is
+// unbounded or infeasible then
+func DualFeasibleBasis(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, lowerBounds, upperBounds *mat.VecDense, baselineIndexes []int) ([]int, error) {
+	result, err := dualFeasibleBasis(optim.NewLimits(context.Background(), optim.Options{}), scalesVector, conditionsMatrix, lowerBounds, upperBounds, baselineIndexes)
+	if err != nil {
+		return nil, err
+	}
+	return result.Basis, nil
+}
+
+// dualFeasibleBasis - DualFeasibleBasis with limits, Result of dual phase 1
+// holds the basis
+func dualFeasibleBasis(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, lowerBounds, upperBounds *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	basis := baselineIndexes
+	if !isBasis(conditionsMatrix, basis, limits.Tolerances()) {
+		var err error
+		if basis, err = independentColumns(conditionsMatrix, limits.Tolerances()); err != nil {
+			return optim.Result{}, err
+		}
+	}
+
+	auxiliaryLower := mat.NewVecDense(varNumber, nil)
+	auxiliaryUpper := mat.NewVecDense(varNumber, nil)
+	for j := 0; j < varNumber; j++ {
+		if math.IsInf(lowerBounds.AtVec(j), -1) {
+			auxiliaryLower.SetVec(j, -1)
+		}
+		if math.IsInf(upperBounds.AtVec(j), 1) {
+			auxiliaryUpper.SetVec(j, 1)
+		}
+	}
+	optim.Tracef("Dual phase 1 starting from basis %v\n", oneBasedIndexes(basis))
+	result, err := boundedDoubleSimplexMethod(limits, scalesVector, conditionsMatrix, mat.NewVecDense(conditionsNumber, nil), auxiliaryLower, auxiliaryUpper, basis)
+	if err != nil {
+		return result, fmt.Errorf("dual phase 1: %w", err)
+	}
+	if !isDualFeasible(scalesVector, conditionsMatrix, lowerBounds, upperBounds, result.Basis, limits.Tolerances()) {
+		optim.Tracef("auxiliary problem optimum %v > 0, there's no dual plan\n", result.Objective)
+		result.Status = optim.Unbounded
+		return result, fmt.Errorf("%w: there's no dual feasible basis", optim.ErrUnbounded)
+	}
+	optim.Tracef("dual feasible basis %v\n", oneBasedIndexes(result.Basis))
+	return result, nil
+}
+
+// startDual - dual feasible basis to start the dual simplex method from:
+// baselineIndexes if they're dual feasible, basis of dual phase 1 otherwise.
+// If there's no dual plan the problem is solved with the bounded simplex
+// method telling infeasible problem from unbounded one and its Result is
+// returned with nil basis
+func startDual(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense, baselineIndexes []int) ([]int, optim.Result, error) {
+	if isDualFeasible(scalesVector, conditionsMatrix, lowerBounds, upperBounds, baselineIndexes, limits.Tolerances()) {
+		return baselineIndexes, optim.Result{}, nil
+	}
+	optim.Tracef("baseline indexes %v aren't dual feasible basis\n", oneBasedIndexes(baselineIndexes))
+	result, err := dualFeasibleBasis(limits, scalesVector, conditionsMatrix, lowerBounds, upperBounds, baselineIndexes)
+	if errors.Is(err, optim.ErrUnbounded) {
+		optim.Tracef("Bounded simplex method for the problem without dual plan\n")
+		primal, err := boundedSimplexMethod(limits, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+		primal.Iterations += result.Iterations
+		return nil, primal, err
+	}
+	if err != nil {
+		return nil, optim.Result{Status: result.Status, Iterations: result.Iterations}, err
+	}
+	return result.Basis, optim.Result{Iterations: result.Iterations}, nil
+}
+
+// isDualFeasible - whether nonbaseline variables of basis have bounds their
+// deltas put them at: finite lower bound for delta > 0, finite upper one for
+// delta < 0 and any finite bound for delta = 0, deltas within
+// tolerances.DualFeasibility from 0 are 0
+func isDualFeasible(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, lowerBounds, upperBounds *mat.VecDense, basis []int, tolerances optim.Tolerances) bool {
+	if !isBasis(conditionsMatrix, basis, tolerances) {
+		return false
+	}
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	components := mat.NewVecDense(conditionsNumber, nil)
+	for i, index := range basis {
+		components.SetVec(i, scalesVector.AtVec(index))
+	}
+	potentials := mat.NewVecDense(conditionsNumber, nil)
+	if err := potentials.SolveVec(linalg.Columns(conditionsMatrix, basis).T(), components); err != nil {
+		return false
+	}
+	deltas := linalg.VecMulMat(potentials, conditionsMatrix)
+	deltas.AddScaledVec(deltas, -1, scalesVector)
+	for _, j := range nonbasic(varNumber, basis) {
+		hasLower, hasUpper := !math.IsInf(lowerBounds.AtVec(j), -1), !math.IsInf(upperBounds.AtVec(j), 1)
+		switch delta := deltas.AtVec(j); {
+		case delta > tolerances.DualFeasibility && !hasLower, delta < -tolerances.DualFeasibility && !hasUpper, !hasLower && !hasUpper:
+			return false
+		}
+	}
+	return true
+}
+
+// isBasis - whether basis holds as many different columns of A as there're
+// conditions and they're linearly independent: condition number of their
+// matrix is less than 1 / tolerances.Zero
+func isBasis(conditionsMatrix *mat.Dense, basis []int, tolerances optim.Tolerances) bool {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	if len(basis) != conditionsNumber {
+		return false
+	}
+	seen := make([]bool, varNumber)
+	for _, index := range basis {
+		if index < 0 || index >= varNumber || seen[index] {
+			return false
+		}
+		seen[index] = true
+	}
+	var lu mat.LU
+	lu.Factorize(linalg.Columns(conditionsMatrix, basis))
+	return lu.Cond() < 1/tolerances.Zero
+}
+
+// independentColumns - basis of linearly independent columns of A. Columns
+// are taken from the last one, where slack variables usually are
+func independentColumns(conditionsMatrix *mat.Dense, tolerances optim.Tolerances) ([]int, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	var basis []int
+	var orthonormal []*mat.VecDense
+	for j := varNumber - 1; j >= 0 && len(basis) < conditionsNumber; j-- {
+		column := mat.VecDenseCopyOf(conditionsMatrix.ColView(j))
+		for _, vector := range orthonormal {
+			column.AddScaledVec(column, -mat.Dot(column, vector), vector)
+		}
+		if norm := mat.Norm(column, 2); norm > tolerances.Zero {
+			column.ScaleVec(1/norm, column)
+			orthonormal = append(orthonormal, column)
+			basis = append(basis, j)
+		}
+	}
+	if len(basis) != conditionsNumber {
+		return nil, fmt.Errorf("conditions are linearly dependent, rank of A is %v", len(basis))
+	}
+	// increasing order like the rest of bases
+	for i, k := 0, len(basis)-1; i < k; i, k = i+1, k-1 {
+		basis[i], basis[k] = basis[k], basis[i]
+	}
+	return basis, nil
+}
+
+// oneBasedIndexes - indexes with numeration starting from 1 for the log
+func oneBasedIndexes(indexes []int) []int {
+	r := make([]int, len(indexes))
+	for i, index := range indexes {
+		r[i] = index + 1
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+package lp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDualFeasibleBasis(t *testing.T) {
+	// x1 + x2 -> max, x1 + x2 + x3 = 4, x2 <= 3: delta[1] = -1 of basis of
+	// slack x3 needs upper bound of x1, basis of x1 is dual feasible
+	inf := math.Inf(1)
+	scalesVector := mat.NewVecDense(3, []float64{1, 1, 0})
+	conditionsMatrix := mat.NewDense(1, 3, []float64{1, 1, 1})
+	lowerBounds, upperBounds := mat.NewVecDense(3, nil), mat.NewVecDense(3, []float64{inf, 3, inf})
+	tolerances := optim.DefaultTolerances
+	if isDualFeasible(scalesVector, conditionsMatrix, lowerBounds, upperBounds, []int{2}, tolerances) {
+		t.Fatalf("basis of slack is dual feasible")
+	}
+	for _, start := range [][]int{nil, {2}, {0, 1}} {
+		basis, err := DualFeasibleBasis(scalesVector, conditionsMatrix, lowerBounds, upperBounds, start)
+		if err != nil || !isDualFeasible(scalesVector, conditionsMatrix, lowerBounds, upperBounds, basis, tolerances) {
+			t.Errorf("from %v: basis %v isn't dual feasible, error %v", start, basis, err)
+		}
+	}
+
+	result, err := BoundedDoubleSimplexMethod(scalesVector, conditionsMatrix, mat.NewVecDense(1, []float64{4}), lowerBounds, upperBounds, []int{2})
+	if err != nil || math.Abs(result.Objective-4) > 1e-9 {
+		t.Errorf("%v with objective %v and error %v, want optimal 4", result.Status, result.Objective, err)
+	}
+}
+
+func TestDualFeasibleBasisFallback(t *testing.T) {
+	// without dual plan the bounded simplex method tells unbounded problem
+	// from infeasible one
+	inf := math.Inf(1)
+	tests := []struct {
+		name                     string
+		scalesVector             []float64
+		conditionsMatrix         []float64
+		freeVector               []float64
+		lowerBounds, upperBounds []float64
+		status                   optim.Status
+		err                      error
+	}{
+		{
+			// x1 -> max, x1 - x2 = 1 grows along x1 = x2
+			"unbounded", []float64{1, 0}, []float64{1, -1}, []float64{1},
+			[]float64{0, 0}, []float64{inf, inf}, optim.Unbounded, optim.ErrUnbounded,
+		},
+		{
+			// x3 -> max grows along x3 = x4, while x1 - x2 = 5 can't be
+			// reached with x1 <= 1
+			"infeasible", []float64{0, 0, 1, 0}, []float64{1, -1, 0, 0, 0, 0, 1, -1}, []float64{5, 0},
+			[]float64{-inf, 0, 0, 0}, []float64{1, inf, inf, inf}, optim.Infeasible, optim.ErrInfeasible,
+		},
+	}
+	for _, test := range tests {
+		varNumber := len(test.scalesVector)
+		scalesVector := mat.NewVecDense(varNumber, test.scalesVector)
+		conditionsMatrix := mat.NewDense(len(test.freeVector), varNumber, test.conditionsMatrix)
+		freeVector := mat.NewVecDense(len(test.freeVector), test.freeVector)
+		lowerBounds, upperBounds := mat.NewVecDense(varNumber, test.lowerBounds), mat.NewVecDense(varNumber, test.upperBounds)
+
+		limits := optim.NewLimits(context.Background(), optim.Options{})
+		if _, err := dualFeasibleBasis(limits, scalesVector, conditionsMatrix, lowerBounds, upperBounds, nil); !errors.Is(err, optim.ErrUnbounded) {
+			t.Errorf("%v: dual phase 1 error %v, want %v", test.name, err, optim.ErrUnbounded)
+		}
+		result, err := BoundedDoubleSimplexMethod(scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds, nil)
+		if result.Status != test.status || !errors.Is(err, test.err) {
+			t.Errorf("%v: %v with error %v, want %v with %v", test.name, result.Status, err, test.status, test.err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package lp
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+)
+
+// ExactSimplexMainPhase - SimplexMainPhase with rational arithmetic: every
+// value is a fraction of the numbers of input, so comparisons with zero are
+// exact. Entering column is chosen by Bland rule, leaving row by the least
+// ratio, ties go to the least baseline index. Result holds Exact values and
+// dual solution of optimal plan, the iterations log prints fractions. Values
+// are fractions as ExactProblem of the problem file holds them
+func ExactSimplexMainPhase(scalesVector []*big.Rat, conditionsMatrix *linalg.Dense[*big.Rat], baselineVector []*big.Rat, baselineIndexes []int) (optim.Result, error) {
+	return ExactSimplexMainPhaseContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, baselineVector, baselineIndexes)
+}
+
+// ExactSimplexMainPhaseContext - ExactSimplexMainPhase with limits, see
+// SimplexMainPhaseContext. options.Pricing and options.AntiCycling aren't used
+func ExactSimplexMainPhaseContext(ctx context.Context, options optim.Options, scalesVector []*big.Rat, conditionsMatrix *linalg.Dense[*big.Rat], baselineVector []*big.Rat, baselineIndexes []int) (optim.Result, error) {
+	field := linalg.Rational{}
+	freeVector := conditionsMatrix.MulVec(baselineVector)
+	limits := optim.NewLimits(ctx, options)
+	result, err := simplexMainPhaseOf[*big.Rat](limits, scalesVector, conditionsMatrix, append([]*big.Rat{}, baselineVector...), append([]int{}, baselineIndexes...))
+	_, varNumber := conditionsMatrix.Dims()
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	return withInfeasibility(result, err, limits.Tolerances(), linalg.FloatVectorOf[*big.Rat](field, scalesVector), conditionsMatrix.Float(), linalg.FloatVectorOf(field, freeVector), lowerBounds, upperBounds)
+}
+
+// ExactDoubleSimplexMethod - DoubleSimplexMethod with rational arithmetic,
+// see ExactSimplexMainPhase. If baselineIndexes aren't dual feasible basis,
+// the basis is found by dual phase 1 with float64 values. Baseline matrix is
+// inversed once, its inverse is updated after every pivot with
+// linalg.InvOptimizedOf
+func ExactDoubleSimplexMethod(scalesVector []*big.Rat, conditionsMatrix *linalg.Dense[*big.Rat], freeVector []*big.Rat, baselineIndexes []int) (optim.Result, error) {
+	return ExactDoubleSimplexMethodContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector, baselineIndexes)
+}
+
+// ExactDoubleSimplexMethodContext - ExactDoubleSimplexMethod with limits,
+// see DoubleSimplexMethodContext
+func ExactDoubleSimplexMethodContext(ctx context.Context, options optim.Options, scalesVector []*big.Rat, conditionsMatrix *linalg.Dense[*big.Rat], freeVector []*big.Rat, baselineIndexes []int) (optim.Result, error) {
+	limits, field := optim.NewLimits(ctx, options), linalg.Rational{}
+	floatScales, floatConditions, floatFree := linalg.FloatVectorOf[*big.Rat](field, scalesVector), conditionsMatrix.Float(), linalg.FloatVectorOf[*big.Rat](field, freeVector)
+	_, varNumber := conditionsMatrix.Dims()
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	basis, start, err := startDual(limits, floatScales, floatConditions, floatFree, lowerBounds, upperBounds, baselineIndexes)
+	if basis == nil {
+		return withInfeasibility(start, err, limits.Tolerances(), floatScales, floatConditions, floatFree, lowerBounds, upperBounds)
+	}
+	result, err := doubleSimplexMethodOf[*big.Rat](limits, scalesVector, conditionsMatrix, freeVector, append([]int{}, basis...))
+	result.Iterations += start.Iterations
+	return withInfeasibility(result, err, limits.Tolerances(), floatScales, floatConditions, floatFree, lowerBounds, upperBounds)
+}
+
+// simplexMainPhaseOf - main phase of the simplex method with values of any
+// linalg.Field
+func simplexMainPhaseOf[T any](limits optim.Limits, scalesVector []T, conditionsMatrix *linalg.Dense[T], baselineVector []T, baselineIndexes []int) (optim.Result, error) {
+	field := conditionsMatrix.Field
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	inversedBaselineMatrix, err := conditionsMatrix.Columns(baselineIndexes).Inverse()
+	if err != nil {
+		return optim.Result{Status: optim.SingularBasis, Basis: baselineIndexes}, optim.ErrSingularBasis
+	}
+	freeVector := conditionsMatrix.MulVec(baselineVector)
+	for iteration := 0; ; iteration++ {
+		result := resultOf(field, scalesVector, baselineVector, baselineIndexes, iteration)
+		optim.Tracef("iteration %v, plan %v, basis %v\n", iteration, linalg.FormatVector(field, baselineVector), oneBasedIndexes(baselineIndexes))
+		optim.Tracef("inversed baseline matrix\n")
+		inversedBaselineMatrix.Fprint(optim.Trace)
+
+		baselineScales := make([]T, conditionsNumber)
+		for i, index := range baselineIndexes {
+			baselineScales[i] = scalesVector[index]
+		}
+		potentials := inversedBaselineMatrix.VecMul(baselineScales)
+		scoreVector := conditionsMatrix.VecMul(potentials)
+		for j := range scoreVector {
+			scoreVector[j] = field.Sub(scoreVector[j], scalesVector[j])
+		}
+		optim.Tracef("potentials %v\nscores %v\n", linalg.FormatVector(field, potentials), linalg.FormatVector(field, scoreVector))
+
+		entering := -1
+		for _, j := range nonbasic(varNumber, baselineIndexes) {
+			if field.Sign(scoreVector[j]) < 0 {
+				entering = j
+				break
+			}
+		}
+		if entering < 0 {
+			optim.Tracef("scores are nonnegative, plan is optimal\n")
+			result.Status = optim.Optimal
+			result.Duals = linalg.FloatVectorOf(field, potentials)
+			result.ReducedCosts = linalg.FloatVectorOf(field, scoreVector)
+			result.InverseBasis = inversedBaselineMatrix.Float()
+			slacks := conditionsMatrix.MulVec(baselineVector)
+			for i := range slacks {
+				slacks[i] = field.Sub(freeVector[i], slacks[i])
+			}
+			result.Slacks = linalg.FloatVectorOf(field, slacks)
+			dualObjective := linalg.Dot(field, potentials, freeVector)
+			result.DualObjective = field.Float(dualObjective)
+			if result.Exact != nil {
+				result.Exact.Duals = linalg.RatVectorOf(field, potentials)
+				result.Exact.ReducedCosts = linalg.RatVectorOf(field, scoreVector)
+				result.Exact.Slacks = linalg.RatVectorOf(field, slacks)
+				result.Exact.DualObjective = field.Rat(dualObjective)
+			}
+			return result, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+
+		column := inversedBaselineMatrix.MulVec(conditionsMatrix.Col(entering))
+		leaving := -1
+		var minTheta T
+		for i, value := range column {
+			if field.Sign(value) <= 0 {
+				continue
+			}
+			theta := field.Div(baselineVector[baselineIndexes[i]], value)
+			if leaving < 0 || field.Sign(field.Sub(theta, minTheta)) < 0 || (field.Sign(field.Sub(theta, minTheta)) == 0 && baselineIndexes[i] < baselineIndexes[leaving]) {
+				leaving, minTheta = i, theta
+			}
+		}
+		if leaving < 0 {
+			optim.Tracef("column of x%v has no positive values, objective is unbounded\n", entering+1)
+			result.Status = optim.Unbounded
+			return result, optim.ErrUnbounded
+		}
+		optim.Tracef("x%v enters basis, x%v leaves it, theta %v\n", entering+1, baselineIndexes[leaving]+1, field.Format(minTheta))
+
+		for i, index := range baselineIndexes {
+			baselineVector[index] = field.Sub(baselineVector[index], field.Mul(minTheta, column[i]))
+		}
+		baselineVector[baselineIndexes[leaving]] = field.FromFloat(0)
+		baselineVector[entering] = minTheta
+		baselineMatrix := conditionsMatrix.Columns(baselineIndexes)
+		if inversedBaselineMatrix, err = linalg.InvOptimizedOf(baselineMatrix, inversedBaselineMatrix, conditionsMatrix.Col(entering), leaving); err != nil {
+			result.Status = optim.SingularBasis
+			return result, optim.ErrSingularBasis
+		}
+		baselineIndexes[leaving] = entering
+	}
+}
+
+// doubleSimplexMethodOf - dual simplex method with values of any
+// linalg.Field
+func doubleSimplexMethodOf[T any](limits optim.Limits, scalesVector []T, conditionsMatrix *linalg.Dense[T], freeVector []T, baselineIndexes []int) (optim.Result, error) {
+	field := conditionsMatrix.Field
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	inversedBaselineMatrix, err := conditionsMatrix.Columns(baselineIndexes).Inverse()
+	if err != nil {
+		return optim.Result{Status: optim.SingularBasis, Basis: baselineIndexes}, optim.ErrSingularBasis
+	}
+	for iteration := 0; ; iteration++ {
+		baselineKappa := inversedBaselineMatrix.MulVec(freeVector)
+		kappa := make([]T, varNumber)
+		for j := range kappa {
+			kappa[j] = field.FromFloat(0)
+		}
+		for i, index := range baselineIndexes {
+			kappa[index] = baselineKappa[i]
+		}
+		result := resultOf(field, scalesVector, kappa, baselineIndexes, iteration)
+		optim.Tracef("iteration %v, pseudo plan %v, basis %v\n", iteration, linalg.FormatVector(field, kappa), oneBasedIndexes(baselineIndexes))
+		optim.Tracef("inversed baseline matrix\n")
+		inversedBaselineMatrix.Fprint(optim.Trace)
+
+		leaving := -1
+		for i, value := range baselineKappa {
+			if field.Sign(value) < 0 {
+				leaving = i
+				break
+			}
+		}
+		if leaving < 0 {
+			optim.Tracef("pseudo plan is nonnegative, it's optimal\n")
+			result.Status = optim.Optimal
+			return result, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+
+		baselineScales := make([]T, conditionsNumber)
+		for i, index := range baselineIndexes {
+			baselineScales[i] = scalesVector[index]
+		}
+		yVector := inversedBaselineMatrix.VecMul(baselineScales)
+		yDeltaVector := inversedBaselineMatrix.Row(leaving)
+		entering := -1
+		var minSigma T
+		for _, j := range nonbasic(varNumber, baselineIndexes) {
+			column := conditionsMatrix.Col(j)
+			mu := linalg.Dot(field, yDeltaVector, column)
+			if field.Sign(mu) >= 0 {
+				continue
+			}
+			sigma := field.Div(field.Sub(scalesVector[j], linalg.Dot(field, column, yVector)), mu)
+			if entering < 0 || field.Sign(field.Sub(sigma, minSigma)) < 0 {
+				entering, minSigma = j, sigma
+			}
+		}
+		if entering < 0 {
+			optim.Tracef("row of x%v has no negative values, problem is infeasible\n", baselineIndexes[leaving]+1)
+			result.Status = optim.Infeasible
+			return result, optim.ErrInfeasible
+		}
+		optim.Tracef("x%v enters basis, x%v leaves it, sigma %v\n", entering+1, baselineIndexes[leaving]+1, field.Format(minSigma))
+
+		baselineMatrix := conditionsMatrix.Columns(baselineIndexes)
+		if inversedBaselineMatrix, err = linalg.InvOptimizedOf(baselineMatrix, inversedBaselineMatrix, conditionsMatrix.Col(entering), leaving); err != nil {
+			result.Status = optim.SingularBasis
+			return result, optim.ErrSingularBasis
+		}
+		baselineIndexes[leaving] = entering
+	}
+}
+
+// resultOf - Result of plan with its basis, Exact values are set if field is
+// exact
+func resultOf[T any](field linalg.Field[T], scalesVector, plan []T, baselineIndexes []int, iteration int) optim.Result {
+	objective := linalg.Dot(field, scalesVector, plan)
+	result := optim.Result{
+		Objective:  field.Float(objective),
+		X:          linalg.FloatVectorOf(field, plan),
+		Basis:      append([]int{}, baselineIndexes...),
+		Iterations: iteration,
+	}
+	if field.Exact() {
+		result.Exact = &optim.Exact{
+			Objective: field.Rat(objective),
+			X:         linalg.RatVectorOf(field, plan),
+		}
+	}
+	return result
+}
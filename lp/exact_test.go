@@ -0,0 +1,61 @@
+package lp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+)
+
+// parseBlock - the first problem of text
+func parseBlock(text string) parse.Block {
+	return parse.Blocks("input.txt", text)[0]
+}
+
+// parseProblem - the only problem of text, see ParseOptimizationProblem
+func parseProblem(t *testing.T, text string, preparationPhase bool) Problem {
+	t.Helper()
+	problem, err := ParseOptimizationProblem(parseBlock(text), preparationPhase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
+func TestExactSimplexMainPhaseFractions(t *testing.T) {
+	// x1/3 + x2 + x3 = 1 is x1 = 3 at optimum, exactly only if 1/3 isn't
+	// rounded to float64
+	problem := parseProblem(t, "1 1 0\n1/3 1 1\n1\n0 0 1\n", false)
+	exact := problem.Exact
+	result, err := ExactSimplexMainPhase(exact.ScalesVector, exact.ConditionsMatrix, exact.BaselineVector, problem.BaselineIndexes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != optim.Optimal {
+		t.Fatalf("status = %v, want optimal", result.Status)
+	}
+	if want := big.NewRat(3, 1); result.Exact.Objective.Cmp(want) != 0 {
+		t.Errorf("objective = %v, want 3", result.Exact.Objective.RatString())
+	}
+	for j, want := range []int64{3, 0, 0} {
+		if result.Exact.X[j].Cmp(big.NewRat(want, 1)) != 0 {
+			t.Errorf("x%v = %v, want %v", j+1, result.Exact.X[j].RatString(), want)
+		}
+	}
+}
+
+func TestExactDoubleSimplexMethodFractions(t *testing.T) {
+	problem, err := ParseDoubleOptimizationProblem(parse.Blocks("input.txt", "1 1 0\n1/3 1 1\n1\n")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	exact := problem.Exact
+	result, err := ExactDoubleSimplexMethod(exact.ScalesVector, exact.ConditionsMatrix, exact.FreeVector, problem.BaselineIndexes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewRat(3, 1); result.Exact.Objective.Cmp(want) != 0 {
+		t.Errorf("objective = %v, want 3", result.Exact.Objective.RatString())
+	}
+}
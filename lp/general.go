@@ -0,0 +1,440 @@
+package lp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Sense - direction of objective function optimization
+type Sense int
+
+const (
+	// Maximize - c'x -> max
+	Maximize Sense = iota
+	// Minimize - c'x -> min
+	Minimize
+)
+
+func (s Sense) String() string {
+	if s == Minimize {
+		return "min"
+	}
+	return "max"
+}
+
+// Relation - relation between condition value A[i]x and free value b[i]
+type Relation int
+
+const (
+	// LessEqual - A[i]x <= b[i]
+	LessEqual Relation = iota
+	// GreaterEqual - A[i]x >= b[i]
+	GreaterEqual
+	// Equal - A[i]x = b[i]
+	Equal
+)
+
+func (r Relation) String() string {
+	switch r {
+	case GreaterEqual:
+		return ">="
+	case Equal:
+		return "="
+	}
+	return "<="
+}
+
+// GeneralProblem - optimization problem in general form
+//
+//	c'x -> max (min), A[i]x <= b[i] (>= b[i], = b[i]), lower <= x <= upper
+//
+// Missing bounds are math.Inf(-1) in LowerBounds and math.Inf(1) in
+// UpperBounds, so free variables have both of them infinite. Name, VarNames
+// and ConditionNames are optional
+type GeneralProblem struct {
+	Sense            Sense
+	ScalesVector     *mat.VecDense
+	ConditionsMatrix *mat.Dense
+	Relations        []Relation
+	FreeVector       *mat.VecDense
+	LowerBounds      *mat.VecDense
+	UpperBounds      *mat.VecDense
+
+	Name           string
+	VarNames       []string
+	ConditionNames []string
+}
+
+// NewGeneralProblem - problem with bounds x >= 0
+func NewGeneralProblem(sense Sense, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, relations []Relation, freeVector *mat.VecDense) GeneralProblem {
+	varNumber := scalesVector.Len()
+	upperBounds := mat.NewVecDense(varNumber, nil)
+	for i := 0; i < varNumber; i++ {
+		upperBounds.SetVec(i, math.Inf(1))
+	}
+	return GeneralProblem{
+		Sense:            sense,
+		ScalesVector:     scalesVector,
+		ConditionsMatrix: conditionsMatrix,
+		Relations:        relations,
+		FreeVector:       freeVector,
+		LowerBounds:      mat.NewVecDense(varNumber, nil),
+		UpperBounds:      upperBounds,
+	}
+}
+
+// VarName - name of variable i, x1, x2, ... if problem has no names
+func (p GeneralProblem) VarName(i int) string {
+	if i < len(p.VarNames) {
+		return p.VarNames[i]
+	}
+	return fmt.Sprintf("x%v", i+1)
+}
+
+// ConditionName - name of condition i, c1, c2, ... if problem has no names
+func (p GeneralProblem) ConditionName(i int) string {
+	if i < len(p.ConditionNames) {
+		return p.ConditionNames[i]
+	}
+	return fmt.Sprintf("c%v", i+1)
+}
+
+// CanonicalProblem - GeneralProblem converted to canonical form c'x -> max,
+// Ax = b, x >= 0. Every variable of the original problem is shifted by its
+// finite bound, free variables are split into difference of two canonical
+// ones, inequalities get slack variables and variables bounded from both
+// sides get one more condition for their upper bound
+type CanonicalProblem struct {
+	ScalesVector     *mat.VecDense
+	ConditionsMatrix *mat.Dense
+	FreeVector       *mat.VecDense
+
+	problem   GeneralProblem
+	variables []canonicalVariable
+}
+
+// canonicalVariable - original variable as canonical ones:
+// x = offset + sign*x[column] - x[negativeColumn]
+type canonicalVariable struct {
+	offset, sign   float64
+	column         int
+	negativeColumn int // -1 unless the variable is free
+	boundRow       int // -1 unless the variable is bounded from both sides
+}
+
+// Canonical - canonical form of problem
+func (p GeneralProblem) Canonical() CanonicalProblem {
+	conditionsNumber, varNumber := p.ConditionsMatrix.Dims()
+	canonical := CanonicalProblem{problem: p, variables: make([]canonicalVariable, varNumber)}
+
+	// columns of original variables, then rows of upper bounds
+	columns := 0
+	var boundedIndexes []int
+	for i := range canonical.variables {
+		lower, upper := p.LowerBounds.AtVec(i), p.UpperBounds.AtVec(i)
+		variable := canonicalVariable{sign: 1, column: columns, negativeColumn: -1, boundRow: -1}
+		columns++
+		switch {
+		case !math.IsInf(lower, -1):
+			variable.offset = lower
+			if !math.IsInf(upper, 1) {
+				boundedIndexes = append(boundedIndexes, i)
+			}
+		case !math.IsInf(upper, 1):
+			variable.offset, variable.sign = upper, -1
+		default:
+			variable.negativeColumn = columns
+			columns++
+		}
+		canonical.variables[i] = variable
+	}
+
+	// slack variables
+	slacksNumber := len(boundedIndexes)
+	for _, relation := range p.Relations {
+		if relation != Equal {
+			slacksNumber++
+		}
+	}
+	rowsNumber, columnsNumber := conditionsNumber+len(boundedIndexes), columns+slacksNumber
+
+	canonical.ConditionsMatrix = mat.NewDense(rowsNumber, columnsNumber, nil)
+	canonical.FreeVector = mat.NewVecDense(rowsNumber, nil)
+	canonical.ScalesVector = mat.NewVecDense(columnsNumber, nil)
+	slack := columns
+	for i := 0; i < conditionsNumber; i++ {
+		free := p.FreeVector.AtVec(i)
+		for j, variable := range canonical.variables {
+			a := p.ConditionsMatrix.At(i, j)
+			free -= a * variable.offset
+			canonical.ConditionsMatrix.Set(i, variable.column, a*variable.sign)
+			if variable.negativeColumn != -1 {
+				canonical.ConditionsMatrix.Set(i, variable.negativeColumn, -a)
+			}
+		}
+		canonical.FreeVector.SetVec(i, free)
+		switch p.Relations[i] {
+		case LessEqual:
+			canonical.ConditionsMatrix.Set(i, slack, 1)
+			slack++
+		case GreaterEqual:
+			canonical.ConditionsMatrix.Set(i, slack, -1)
+			slack++
+		}
+	}
+	for k, index := range boundedIndexes {
+		canonical.variables[index].boundRow = conditionsNumber + k
+		canonical.ConditionsMatrix.Set(conditionsNumber+k, canonical.variables[index].column, 1)
+		canonical.ConditionsMatrix.Set(conditionsNumber+k, slack, 1)
+		canonical.FreeVector.SetVec(conditionsNumber+k, p.UpperBounds.AtVec(index)-p.LowerBounds.AtVec(index))
+		slack++
+	}
+
+	sense := 1.
+	if p.Sense == Minimize {
+		sense = -1
+	}
+	for j, variable := range canonical.variables {
+		c := sense * p.ScalesVector.AtVec(j)
+		canonical.ScalesVector.SetVec(variable.column, c*variable.sign)
+		if variable.negativeColumn != -1 {
+			canonical.ScalesVector.SetVec(variable.negativeColumn, -c)
+		}
+	}
+	return canonical
+}
+
+// Problem - canonical problem as Problem read by ParseOptimizationProblem
+// with preparationPhase set
+func (c CanonicalProblem) Problem() Problem {
+	return Problem{ScalesVector: c.ScalesVector, ConditionsMatrix: c.ConditionsMatrix, FreeVector: c.FreeVector}
+}
+
+// Original - result of canonical problem mapped back to the original one:
+// X holds values of original variables and Objective is c'x of the original
+// problem. Basis is left with indexes of canonical variables. Dual solution of
+// optimal result is of the original problem too: Duals and ReducedCosts are
+// changes of the optimal objective per unit increase of b[i] and x[i], Slacks
+// are b - A[i]x for <= and = conditions and A[i]x - b for >= ones
+func (c CanonicalProblem) Original(result optim.Result) optim.Result {
+	return c.original(result, optim.DefaultTolerances)
+}
+
+// original - Original with tolerances of dual solution
+func (c CanonicalProblem) original(result optim.Result, tolerances optim.Tolerances) optim.Result {
+	if result.X == nil {
+		return result
+	}
+	x := mat.NewVecDense(len(c.variables), nil)
+	offsetObjective := 0.
+	for i, variable := range c.variables {
+		value := variable.offset + variable.sign*result.X.AtVec(variable.column)
+		if variable.negativeColumn != -1 {
+			value -= result.X.AtVec(variable.negativeColumn)
+		}
+		x.SetVec(i, value)
+		offsetObjective += c.problem.ScalesVector.AtVec(i) * variable.offset
+	}
+	result.X = x
+	result.Objective = mat.Dot(c.problem.ScalesVector, x)
+	// inversed baseline matrix of the solver may be of conditions multiplied
+	// by -1 or with linearly dependent ones eliminated, see Sensitivity
+	result.InverseBasis = nil
+	if result.Duals == nil {
+		return result
+	}
+
+	sense := 1.
+	if c.problem.Sense == Minimize {
+		sense = -1
+	}
+	result.DualObjective = sense*result.DualObjective + offsetObjective
+	rowDuals, err := c.rowDuals(result, tolerances)
+	if err != nil {
+		result.Duals, result.ReducedCosts, result.Slacks = nil, nil, nil
+		return result
+	}
+	conditionsNumber, _ := c.problem.ConditionsMatrix.Dims()
+	duals := mat.VecDenseCopyOf(rowDuals.SliceVec(0, conditionsNumber))
+
+	// reduced cost of canonical column holds dual of the upper bound row,
+	// bounds aren't conditions of the original problem, so it's added back
+	reducedCosts := mat.NewVecDense(len(c.variables), nil)
+	for i, variable := range c.variables {
+		reducedCost := -sense * variable.sign * result.ReducedCosts.AtVec(variable.column)
+		if variable.boundRow != -1 {
+			reducedCost += variable.sign * rowDuals.AtVec(variable.boundRow)
+		}
+		reducedCosts.SetVec(i, reducedCost+0)
+	}
+	slacks := mat.NewVecDense(conditionsNumber, nil)
+	slacks.MulVec(c.problem.ConditionsMatrix, x)
+	slacks.SubVec(c.problem.FreeVector, slacks)
+	for i, relation := range c.problem.Relations {
+		if relation == GreaterEqual {
+			slacks.SetVec(i, -slacks.AtVec(i))
+		}
+	}
+	result.Duals, result.ReducedCosts, result.Slacks = duals, reducedCosts, slacks
+	return result
+}
+
+// Duals - dual values of the original problem conditions for Result of
+// Solve: change of the optimal objective per unit increase of b[i]. Dual
+// values of conditions eliminated as linearly dependent are zeros
+func (c CanonicalProblem) Duals(result optim.Result) (*mat.VecDense, error) {
+	return c.duals(result, optim.DefaultTolerances)
+}
+
+// duals - Duals with linearly dependent conditions found with tolerances
+func (c CanonicalProblem) duals(result optim.Result, tolerances optim.Tolerances) (*mat.VecDense, error) {
+	rowDuals, err := c.rowDuals(result, tolerances)
+	if err != nil {
+		return nil, err
+	}
+	conditionsNumber, _ := c.problem.ConditionsMatrix.Dims()
+	return mat.VecDenseCopyOf(rowDuals.SliceVec(0, conditionsNumber)), nil
+}
+
+// rowDuals - dual values of all rows of canonical problem, the ones of
+// upper bound rows follow the ones of conditions
+func (c CanonicalProblem) rowDuals(result optim.Result, tolerances optim.Tolerances) (*mat.VecDense, error) {
+	rowsNumber, _ := c.ConditionsMatrix.Dims()
+	potentialsVector, err := potentials(c.ScalesVector, c.ConditionsMatrix, result.Basis, tolerances)
+	if err != nil {
+		return nil, err
+	}
+	sense := 1.
+	if c.problem.Sense == Minimize {
+		sense = -1
+	}
+	duals := mat.NewVecDense(rowsNumber, nil)
+	for i := 0; i < rowsNumber; i++ {
+		// + 0 turns -0 left by rounding into 0
+		duals.SetVec(i, sense*potentialsVector.AtVec(i)+0)
+	}
+	return duals, nil
+}
+
+// Solve - solves canonical problem with preparation and main phases of the
+// simplex method, see Original for what Result holds
+func (c CanonicalProblem) Solve() (optim.Result, error) {
+	return c.SolveContext(context.Background(), optim.Options{})
+}
+
+// SolveContext - Solve with limits of both phases, see SimplexMainPhaseContext
+func (c CanonicalProblem) SolveContext(ctx context.Context, options optim.Options) (optim.Result, error) {
+	limits := optim.NewLimits(ctx, options)
+	result, err := solveCanonical(limits, c.ScalesVector, c.ConditionsMatrix, c.FreeVector)
+	_, varNumber := c.ConditionsMatrix.Dims()
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	result, err = withInfeasibility(result, err, limits.Tolerances(), c.ScalesVector, c.ConditionsMatrix, c.FreeVector, lowerBounds, upperBounds)
+	return c.original(result, limits.Tolerances()), err
+}
+
+// SolveGeneralProblem - solves problem with preparation and main phases of the
+// simplex method for its canonical form, see CanonicalProblem.Original for
+// what Result holds
+func SolveGeneralProblem(problem GeneralProblem) (optim.Result, error) {
+	return problem.Canonical().Solve()
+}
+
+// SolveGeneralProblemContext - SolveGeneralProblem with limits of both
+// phases, see SimplexMainPhaseContext
+func SolveGeneralProblemContext(ctx context.Context, options optim.Options, problem GeneralProblem) (optim.Result, error) {
+	return problem.Canonical().SolveContext(ctx, options)
+}
+
+// solveCanonical - finds baseline plan with preparation phase and then solves
+// the problem left after elimination of linearly dependent conditions with the
+// main phase
+func solveCanonical(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (optim.Result, error) {
+	preparation, conditionsMatrix, _, err := simplexPreparationPhase(limits, scalesVector, mat.DenseCopyOf(conditionsMatrix), mat.VecDenseCopyOf(freeVector))
+	if err != nil {
+		return preparation, err
+	}
+	result, err := simplexMainPhase(limits, scalesVector, conditionsMatrix, mat.VecDenseCopyOf(preparation.X), linalg.FloatVector(preparation.Basis))
+	result.Iterations += preparation.Iterations
+	result.Degeneracy = mergeDegeneracy(preparation.Degeneracy, result.Degeneracy)
+
+	// preparation phase multiplies conditions with b[i] < 0 by -1, dual
+	// solution of the rest of them is of the given conditions
+	if result.Duals != nil && result.Duals.Len() == freeVector.Len() {
+		for i := 0; i < freeVector.Len(); i++ {
+			if freeVector.AtVec(i) < 0 {
+				result.Duals.SetVec(i, -result.Duals.AtVec(i)+0)
+				result.Slacks.SetVec(i, -result.Slacks.AtVec(i)+0)
+				for k := range result.Basis {
+					result.InverseBasis.Set(k, i, -result.InverseBasis.At(k, i))
+				}
+			}
+		}
+	}
+	return result, err
+}
+
+// mergeDegeneracy - degeneracy of both phases, ZeroBasic is the union of
+// theirs
+func mergeDegeneracy(preparation, main optim.Degeneracy) optim.Degeneracy {
+	zeroBasic := append(append([]int{}, preparation.ZeroBasic...), main.ZeroBasic...)
+	sort.Ints(zeroBasic)
+	merged := optim.Degeneracy{Pivots: preparation.Pivots + main.Pivots}
+	for i, index := range zeroBasic {
+		if i == 0 || index != zeroBasic[i-1] {
+			merged.ZeroBasic = append(merged.ZeroBasic, index)
+		}
+	}
+	return merged
+}
+
+// potentials - u' = c_B'A_B^-1 for baselineIndexes. If there're less baseline
+// indexes than conditions (linearly dependent conditions were eliminated),
+// potentials are found for linearly independent conditions and are zeros for
+// the rest of them. Rows of norm up to tolerances.Zero left by
+// orthonormalization are linearly dependent
+func potentials(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, baselineIndexes []int, tolerances optim.Tolerances) (*mat.VecDense, error) {
+	conditionsNumber, _ := conditionsMatrix.Dims()
+	baselineMatrix := linalg.Columns(conditionsMatrix, baselineIndexes)
+
+	// linearly independent rows of baseline matrix, rows of orthonormal are
+	// orthonormalized rows taken so far
+	var rows []int
+	var orthonormal []*mat.VecDense
+	for i := 0; i < conditionsNumber && len(rows) < len(baselineIndexes); i++ {
+		row := mat.VecDenseCopyOf(baselineMatrix.RowView(i))
+		for _, vector := range orthonormal {
+			row.AddScaledVec(row, -mat.Dot(row, vector), vector)
+		}
+		if norm := mat.Norm(row, 2); norm > tolerances.Zero {
+			row.ScaleVec(1/norm, row)
+			orthonormal = append(orthonormal, row)
+			rows = append(rows, i)
+		}
+	}
+	if len(rows) != len(baselineIndexes) {
+		return nil, optim.ErrSingularBasis
+	}
+
+	// u[rows]' B = c_B' where B - rows of baseline matrix
+	square := mat.NewDense(len(rows), len(rows), nil)
+	scales := mat.NewVecDense(len(rows), nil)
+	for i, row := range rows {
+		square.SetRow(i, baselineMatrix.RawRowView(row))
+		scales.SetVec(i, scalesVector.AtVec(baselineIndexes[i]))
+	}
+	var u mat.VecDense
+	if err := u.SolveVec(square.T(), scales); err != nil {
+		return nil, optim.ErrSingularBasis
+	}
+	potentialsVector := mat.NewVecDense(conditionsNumber, nil)
+	for i, row := range rows {
+		potentialsVector.SetVec(row, u.AtVec(i))
+	}
+	return potentialsVector, nil
+}
@@ -0,0 +1,76 @@
+package lp
+
+import (
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSolveGeneralProblemReducedCostsOfBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{
+			"binding upper bound",
+			`Maximize
+ profit: 3 x_wood + 2 x_iron - 0.5 x_glue
+Subject To
+ timber: x_wood + x_iron <= 4
+ labour: x_wood + 3 x_iron <= 6
+ glue: x_glue - x_iron = 0
+Bounds
+ x_wood <= 3
+End
+`,
+		},
+		{
+			"minimize with binding upper and lower bounds",
+			`Minimize
+ - 2 x1 + x2 - x3
+Subject To
+ x1 + x2 + x3 <= 10
+ x1 - x3 >= -4
+Bounds
+ 1 <= x1 <= 2
+ 1 <= x2 <= 5
+ -1 <= x3 <= 3
+End
+`,
+		},
+		{
+			"binding upper bound without lower one",
+			`Maximize
+ x1 + x2
+Subject To
+ x1 + 2 x2 <= 6
+Bounds
+ -inf <= x1 <= 2
+End
+`,
+		},
+	}
+	for _, test := range tests {
+		problem, err := ReadLP(strings.NewReader(test.text), "test.lp")
+		if err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+		canonical, err := SolveGeneralProblem(problem)
+		if err != nil {
+			t.Fatalf("%v: lp: %v", test.name, err)
+		}
+		bounded, err := problem.SolveBounded()
+		if err != nil {
+			t.Fatalf("%v: bounded: %v", test.name, err)
+		}
+		switch {
+		case !mat.EqualApprox(canonical.X, bounded.X, 1e-9):
+			t.Errorf("%v: x = %v, bounded gives %v", test.name, mat.Formatted(canonical.X.T()), mat.Formatted(bounded.X.T()))
+		case !mat.EqualApprox(canonical.Duals, bounded.Duals, 1e-9):
+			t.Errorf("%v: duals %v, bounded gives %v", test.name, mat.Formatted(canonical.Duals.T()), mat.Formatted(bounded.Duals.T()))
+		case !mat.EqualApprox(canonical.ReducedCosts, bounded.ReducedCosts, 1e-9):
+			t.Errorf("%v: reduced costs %v, bounded gives %v", test.name, mat.Formatted(canonical.ReducedCosts.T()), mat.Formatted(bounded.ReducedCosts.T()))
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package lp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// GomoryCut - fractional cut sum Coefficients[j]*x[j] >= FreeValue made of
+// tableau row of baseline variable Variable. Coefficients are of the problem
+// variables followed by slack variables of the cuts kept by then, the cut is
+// added with its own slack variable as sum Coefficients[j]*x[j] - s =
+// FreeValue. Cuts are removed with their slack variables when the slack
+// variable becomes baseline one
+type GomoryCut struct {
+	Variable     int
+	Coefficients *mat.VecDense
+	FreeValue    float64
+
+	varNumber int
+	slacks    []int      // numbers of cuts of slack variables starting from 1
+	exact     []*big.Rat // coefficients and free value of exact cut
+}
+
+// name - name of variable j of the cut, x1, x2, ... of the problem and s1,
+// s2, ... of the slack variables numbered as their cuts
+func (c GomoryCut) name(j int) string {
+	if j >= c.varNumber {
+		return fmt.Sprintf("s%v", c.slacks[j-c.varNumber])
+	}
+	return fmt.Sprintf("x%v", j+1)
+}
+
+// String - cut with variables x1, x2, ... of the problem and s1, s2, ... of
+// the previous cuts, exact cut has fractions
+func (c GomoryCut) String() string {
+	var terms []string
+	for j := 0; j < c.Coefficients.Len(); j++ {
+		if c.Coefficients.AtVec(j) == 0 {
+			continue
+		}
+		coefficient := fmt.Sprint(c.Coefficients.AtVec(j))
+		if c.exact != nil {
+			coefficient = c.exact[j].RatString()
+		}
+		terms = append(terms, coefficient+"*"+c.name(j))
+	}
+	freeValue := fmt.Sprint(c.FreeValue)
+	if c.exact != nil {
+		freeValue = c.exact[len(c.exact)-1].RatString()
+	}
+	return strings.Join(terms, " + ") + " >= " + freeValue
+}
+
+// GomoryCuts - solves c'x -> max, Ax = b, x >= 0 with every x[j] integer by
+// the fractional cutting plane method: while optimal plan of the relaxation
+// has fractional baseline value, cut made of its tableau row is added as a
+// row and the relaxation is re-optimized with the dual simplex method.
+// Conditions must
+// be linearly independent. Returns cuts made in their order, Result holds the
+// integer plan without slack variables of the cuts. Returns
+// optim.ErrInfeasible if there's no integer plan, optim.ErrUnbounded if the
+// relaxation is unbounded
+func GomoryCuts(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (optim.Result, []GomoryCut, error) {
+	return GomoryCutsContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector)
+}
+
+// GomoryCutsContext - GomoryCuts stopped with optim.ErrCanceled when ctx is
+// done or options.TimeLimit passes and with optim.ErrIterationLimit after
+// options.MaxIterations pivots of a re-optimization or options.MaxIterations
+// cuts. Result of stopped run holds the last plan of the relaxation
+func GomoryCutsContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (optim.Result, []GomoryCut, error) {
+	return gomoryCuts(optim.NewLimits(ctx, options), scalesVector, conditionsMatrix, freeVector, nil, nil)
+}
+
+// ExactGomoryCuts - GomoryCuts with tableau rows, cuts and baseline values
+// computed with rational numbers of linalg.Rational, so cuts aren't corrupted
+// by rounding errors, and the bases the dual simplex method finds are checked
+// with them. Values are fractions as ExactProblem of the problem file holds
+// them, the relaxations are solved with their float64 values
+func ExactGomoryCuts(scalesVector []*big.Rat, conditionsMatrix *linalg.Dense[*big.Rat], freeVector []*big.Rat) (optim.Result, []GomoryCut, error) {
+	return ExactGomoryCutsContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector)
+}
+
+// ExactGomoryCutsContext - ExactGomoryCuts with limits, see GomoryCutsContext
+func ExactGomoryCutsContext(ctx context.Context, options optim.Options, scalesVector []*big.Rat, conditionsMatrix *linalg.Dense[*big.Rat], freeVector []*big.Rat) (optim.Result, []GomoryCut, error) {
+	field := linalg.Rational{}
+	return gomoryCuts(optim.NewLimits(ctx, options), linalg.FloatVectorOf[*big.Rat](field, scalesVector), conditionsMatrix.Float(), linalg.FloatVectorOf[*big.Rat](field, freeVector), conditionsMatrix, freeVector)
+}
+
+// gomoryCuts - the fractional cutting plane method, exactConditions and
+// exactFree are nil unless tableau rows are computed with them
+func gomoryCuts(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, exactConditions *linalg.Dense[*big.Rat], exactFree []*big.Rat) (optim.Result, []GomoryCut, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	result, err := solveCanonical(limits, scalesVector, conditionsMatrix, freeVector)
+	if err != nil {
+		return result, nil, err
+	}
+	if !isBasisOf(result.Basis, conditionsNumber, varNumber) {
+		return result, nil, fmt.Errorf("conditions are linearly dependent, basis has %v indexes for %v conditions", len(result.Basis), conditionsNumber)
+	}
+
+	p := gomoryProblem{
+		scalesVector:     scalesVector,
+		conditionsMatrix: conditionsMatrix,
+		freeVector:       freeVector,
+		exactConditions:  exactConditions,
+		conditionsNumber: conditionsNumber,
+		varNumber:        varNumber,
+	}
+	if exactFree != nil {
+		p.exactFree = append([]*big.Rat{}, exactFree...)
+	}
+	var cuts []GomoryCut
+	iterations := result.Iterations
+	basis := result.Basis
+	for {
+		tableau, err := newGomoryTableau(p.conditionsMatrix, p.freeVector, p.exactConditions, p.exactFree, basis, limits.Tolerances().Integrality)
+		if err != nil {
+			result.Status = optim.SingularBasis
+			return result, cuts, err
+		}
+		row := tableau.fractionalRow()
+		if row < 0 {
+			lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+			result = infeasibilityOf(tableau.integerPlan(scalesVector, varNumber), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+			result.Iterations = iterations
+			optim.Tracef("plan is integral after %v cuts\n", len(cuts))
+			optim.TraceMatrix(result.X)
+			return result, cuts, nil
+		}
+		if status, err := limits.Check(len(cuts)); err != nil {
+			result.Status = status
+			return result, cuts, err
+		}
+
+		cut := tableau.cut(row, varNumber, p.cuts)
+		cuts = append(cuts, cut)
+		optim.Tracef("cut %v from row of %v: %v\n", len(cuts), cut.name(cut.Variable), cut)
+		basis = p.add(cut, len(cuts), basis)
+		result, err = doubleSimplexMethod(limits, p.scalesVector, p.conditionsMatrix, p.freeVector, linalg.FloatVector(basis))
+		iterations += result.Iterations
+		result.Iterations = iterations
+		if errors.Is(err, optim.ErrInfeasible) {
+			return result, cuts, fmt.Errorf("%w: there's no integer plan", err)
+		}
+		if err != nil {
+			return result, cuts, err
+		}
+		basis = p.removeBaselineSlacks(result.Basis)
+	}
+}
+
+// gomoryProblem - the problem with rows of the kept cuts and their slack
+// variables, exactConditions and exactFree are its rational A and b if it's
+// exact. cuts holds numbers of the kept cuts starting from 1
+type gomoryProblem struct {
+	scalesVector     *mat.VecDense
+	conditionsMatrix *mat.Dense
+	freeVector       *mat.VecDense
+	exactConditions  *linalg.Dense[*big.Rat]
+	exactFree        []*big.Rat
+	conditionsNumber int
+	varNumber        int
+	cuts             []int
+}
+
+// add - adds cut number with its slack variable, returns basis with the slack
+// variable
+func (p *gomoryProblem) add(cut GomoryCut, number int, basis []int) []int {
+	conditionsNumber, columnsNumber := p.conditionsMatrix.Dims()
+	conditionsMatrix := mat.NewDense(conditionsNumber+1, columnsNumber+1, nil)
+	conditionsMatrix.Slice(0, conditionsNumber, 0, columnsNumber).(*mat.Dense).Copy(p.conditionsMatrix)
+	conditionsMatrix.SetRow(conditionsNumber, append(linalg.RawVector(cut.Coefficients), -1))
+	p.conditionsMatrix = conditionsMatrix
+	p.scalesVector = mat.NewVecDense(columnsNumber+1, append(linalg.RawVector(p.scalesVector), 0))
+	p.freeVector = mat.NewVecDense(conditionsNumber+1, append(linalg.RawVector(p.freeVector), cut.FreeValue))
+	if p.exactConditions != nil {
+		field := p.exactConditions.Field
+		exactConditions := linalg.NewDenseOf(field, conditionsNumber+1, columnsNumber+1)
+		for i := 0; i < conditionsNumber; i++ {
+			for j := 0; j < columnsNumber; j++ {
+				exactConditions.Set(i, j, p.exactConditions.At(i, j))
+			}
+		}
+		for j := 0; j < columnsNumber; j++ {
+			exactConditions.Set(conditionsNumber, j, cut.exact[j])
+		}
+		exactConditions.Set(conditionsNumber, columnsNumber, field.FromFloat(-1))
+		p.exactConditions, p.exactFree = exactConditions, append(p.exactFree, cut.exact[columnsNumber])
+	}
+	p.cuts = append(p.cuts, number)
+	return append(append([]int{}, basis...), columnsNumber)
+}
+
+// removeBaselineSlacks - removes cuts whose slack variables are baseline
+// ones, returns basis without them. Slack variable of the cut is first
+// eliminated from the later cuts by adding the cut row to them, so it's
+// the only variable having nonzero value in its column and the rest of the
+// basis stays optimal
+func (p *gomoryProblem) removeBaselineSlacks(basis []int) []int {
+	for k := len(p.cuts) - 1; k >= 0; k-- {
+		slack := p.varNumber + k
+		if !linalg.FindInt(basis, slack) {
+			continue
+		}
+		optim.Tracef("s%v is baseline, cut %v is removed\n", p.cuts[k], p.cuts[k])
+		row := p.conditionsNumber + k
+		conditionsNumber, columnsNumber := p.conditionsMatrix.Dims()
+		for i := row + 1; i < conditionsNumber; i++ {
+			if value := p.conditionsMatrix.At(i, slack); value != 0 {
+				cutRow := p.conditionsMatrix.RowView(i).(*mat.VecDense)
+				cutRow.AddScaledVec(cutRow, value, p.conditionsMatrix.RowView(row))
+				p.freeVector.SetVec(i, p.freeVector.AtVec(i)+value*p.freeVector.AtVec(row))
+			}
+			if p.exactConditions == nil {
+				continue
+			}
+			field, value := p.exactConditions.Field, p.exactConditions.At(i, slack)
+			if field.Sign(value) == 0 {
+				continue
+			}
+			for j := 0; j < columnsNumber; j++ {
+				p.exactConditions.Set(i, j, field.Add(p.exactConditions.At(i, j), field.Mul(value, p.exactConditions.At(row, j))))
+			}
+			p.exactFree[i] = field.Add(p.exactFree[i], field.Mul(value, p.exactFree[row]))
+		}
+		conditionsMatrix := mat.NewDense(conditionsNumber-1, columnsNumber-1, nil)
+		scalesVector := mat.NewVecDense(columnsNumber-1, nil)
+		freeVector := mat.NewVecDense(conditionsNumber-1, nil)
+		for i, r := 0, 0; i < conditionsNumber; i++ {
+			if i == row {
+				continue
+			}
+			for j, c := 0, 0; j < columnsNumber; j++ {
+				if j != slack {
+					conditionsMatrix.Set(r, c, p.conditionsMatrix.At(i, j))
+					c++
+				}
+			}
+			freeVector.SetVec(r, p.freeVector.AtVec(i))
+			r++
+		}
+		for j, c := 0, 0; j < columnsNumber; j++ {
+			if j != slack {
+				scalesVector.SetVec(c, p.scalesVector.AtVec(j))
+				c++
+			}
+		}
+		p.conditionsMatrix, p.scalesVector, p.freeVector = conditionsMatrix, scalesVector, freeVector
+		if p.exactConditions != nil {
+			exactConditions := linalg.NewDenseOf(p.exactConditions.Field, conditionsNumber-1, columnsNumber-1)
+			for i, r := 0, 0; i < conditionsNumber; i++ {
+				if i == row {
+					continue
+				}
+				for j, c := 0, 0; j < columnsNumber; j++ {
+					if j != slack {
+						exactConditions.Set(r, c, p.exactConditions.At(i, j))
+						c++
+					}
+				}
+				r++
+			}
+			p.exactConditions = exactConditions
+			p.exactFree = append(p.exactFree[:row:row], p.exactFree[row+1:]...)
+		}
+		p.cuts = append(p.cuts[:k:k], p.cuts[k+1:]...)
+
+		var kept []int
+		for _, index := range basis {
+			switch {
+			case index > slack:
+				kept = append(kept, index-1)
+			case index < slack:
+				kept = append(kept, index)
+			}
+		}
+		basis = kept
+	}
+	return basis
+}
+
+// gomoryTableau - rows of simplex tableau A_B^-1 A of basis with baseline
+// values A_B^-1 b, rational ones if the problem is exact. float64 values within
+// integrality from an integer are integral
+type gomoryTableau struct {
+	basis       []int
+	rows        *mat.Dense
+	values      *mat.VecDense
+	integrality float64
+
+	exactRows   *linalg.Dense[*big.Rat]
+	exactValues []*big.Rat
+}
+
+// newGomoryTableau - tableau of basis, exactConditions and exactFree are nil
+// unless the problem is exact. Exact baseline values must be nonnegative, the
+// basis is a rounding error of the dual simplex method otherwise
+func newGomoryTableau(conditionsMatrix *mat.Dense, freeVector *mat.VecDense, exactConditions *linalg.Dense[*big.Rat], exactFree []*big.Rat, basis []int, integrality float64) (gomoryTableau, error) {
+	t := gomoryTableau{basis: basis, integrality: integrality}
+	if exactConditions != nil {
+		inverse, err := exactConditions.Columns(basis).Inverse()
+		if err != nil {
+			return t, optim.ErrSingularBasis
+		}
+		t.exactRows, t.exactValues = inverse.Mul(exactConditions), inverse.MulVec(exactFree)
+		for i, value := range t.exactValues {
+			if value.Sign() < 0 {
+				return t, fmt.Errorf("%w: baseline value of x%v is %v in exact arithmetic", optim.ErrSingularBasis, basis[i]+1, value.RatString())
+			}
+		}
+		return t, nil
+	}
+	conditionsNumber, columnsNumber := conditionsMatrix.Dims()
+	inverse := mat.NewDense(conditionsNumber, conditionsNumber, nil)
+	if err := inverse.Inverse(linalg.Columns(conditionsMatrix, basis)); err != nil {
+		return t, optim.ErrSingularBasis
+	}
+	t.rows, t.values = mat.NewDense(conditionsNumber, columnsNumber, nil), mat.NewVecDense(conditionsNumber, nil)
+	t.rows.Mul(inverse, conditionsMatrix)
+	t.values.MulVec(inverse, freeVector)
+	return t, nil
+}
+
+// fractionalRow - row of baseline value with the greatest fractional part, -1
+// if all of them are integral
+func (t gomoryTableau) fractionalRow() int {
+	row, greatest := -1, 0.0
+	for i := range t.basis {
+		var part float64
+		if t.exactValues != nil {
+			part, _ = ratFraction(t.exactValues[i]).Float64()
+		} else {
+			part = fraction(t.values.AtVec(i), t.integrality)
+		}
+		if part > greatest {
+			row, greatest = i, part
+		}
+	}
+	return row
+}
+
+// cut - cut of tableau row, fractional parts of its nonbaseline values.
+// slacks are numbers of cuts of the slack variables
+func (t gomoryTableau) cut(row, varNumber int, slacks []int) GomoryCut {
+	if t.exactRows != nil {
+		field := t.exactRows.Field
+		_, columnsNumber := t.exactRows.Dims()
+		cut := GomoryCut{Variable: t.basis[row], Coefficients: mat.NewVecDense(columnsNumber, nil), varNumber: varNumber, slacks: append([]int{}, slacks...)}
+		for j, value := range t.exactRows.Row(row) {
+			part := ratFraction(value)
+			if linalg.FindInt(t.basis, j) {
+				part = new(big.Rat)
+			}
+			cut.exact = append(cut.exact, part)
+			cut.Coefficients.SetVec(j, field.Float(part))
+		}
+		part := ratFraction(t.exactValues[row])
+		cut.exact = append(cut.exact, part)
+		cut.FreeValue = field.Float(part)
+		return cut
+	}
+	_, columnsNumber := t.rows.Dims()
+	cut := GomoryCut{Variable: t.basis[row], Coefficients: mat.NewVecDense(columnsNumber, nil), FreeValue: fraction(t.values.AtVec(row), t.integrality), varNumber: varNumber, slacks: append([]int{}, slacks...)}
+	for j := 0; j < columnsNumber; j++ {
+		if !linalg.FindInt(t.basis, j) {
+			cut.Coefficients.SetVec(j, fraction(t.rows.At(row, j), t.integrality))
+		}
+	}
+	return cut
+}
+
+// integerPlan - Result of integral baseline values without slack variables of
+// the cuts
+func (t gomoryTableau) integerPlan(scalesVector *mat.VecDense, varNumber int) optim.Result {
+	x := mat.NewVecDense(varNumber, nil)
+	for i, index := range t.basis {
+		if index >= varNumber {
+			continue
+		}
+		if t.exactValues != nil {
+			x.SetVec(index, t.exactRows.Field.Float(t.exactValues[i]))
+		} else {
+			x.SetVec(index, math.Round(t.values.AtVec(i))+0)
+		}
+	}
+	return optim.Result{
+		Status:    optim.Optimal,
+		Objective: mat.Dot(scalesVector.SliceVec(0, varNumber), x),
+		X:         x,
+	}
+}
+
+// fraction - fractional part of value, 0 for values within tolerance from an
+// integer
+func fraction(value, tolerance float64) float64 {
+	if math.Abs(value-math.Round(value)) <= tolerance {
+		return 0
+	}
+	return value - math.Floor(value)
+}
+
+// ratFraction - fractional part of value, value - floor(value)
+func ratFraction(value *big.Rat) *big.Rat {
+	floor := new(big.Int).Div(value.Num(), value.Denom())
+	return new(big.Rat).Sub(value, new(big.Rat).SetInt(floor))
+}
@@ -0,0 +1,67 @@
+package lp
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestGomoryCutsDecimals(t *testing.T) {
+	// slacks x3 and x4 are integral only at x1 = x2 = 0, 0.1 and 0.3 must be
+	// 1/10 and 3/10 for the exact tableau to find it
+	problem := parseProblem(t, "1 1 0 0\n0.1 0.3 1 0\n0.3 0.1 0 1\n1 1\n", true)
+	floatResult, _, err := GomoryCuts(problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector)
+	if err != nil {
+		t.Fatalf("GomoryCuts: %v", err)
+	}
+	exact := problem.Exact
+	exactResult, cuts, err := ExactGomoryCuts(exact.ScalesVector, exact.ConditionsMatrix, exact.FreeVector)
+	if err != nil {
+		t.Fatalf("ExactGomoryCuts: %v", err)
+	}
+	want := mat.NewVecDense(4, []float64{0, 0, 1, 1})
+	for _, result := range []optim.Result{floatResult, exactResult} {
+		if result.Status != optim.Optimal || result.Objective != 0 || !mat.Equal(result.X, want) {
+			t.Errorf("result = %v, objective %v, x %v, want optimal, 0, %v", result.Status, result.Objective, mat.Formatted(result.X.T()), mat.Formatted(want.T()))
+		}
+	}
+	if got := cuts[0].String(); got != "3/4*x3 + 3/4*x4 >= 1/2" {
+		t.Errorf("exact cut 1 = %v", got)
+	}
+}
+
+func TestGomoryCutsKnapsack(t *testing.T) {
+	problem := parseProblem(t, knapsack, true)
+	floatResult, _, err := GomoryCuts(problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector)
+	if err != nil {
+		t.Fatalf("GomoryCuts: %v", err)
+	}
+	exact := problem.Exact
+	exactResult, cuts, err := ExactGomoryCuts(exact.ScalesVector, exact.ConditionsMatrix, exact.FreeVector)
+	if err != nil {
+		t.Fatalf("ExactGomoryCuts: %v", err)
+	}
+	want := mat.NewVecDense(7, []float64{1, 0, 1, 0, 0, 1, 0})
+	for _, result := range []optim.Result{floatResult, exactResult} {
+		if result.Status != optim.Optimal || math.Abs(result.Objective-4) > 1e-9 || !mat.EqualApprox(result.X, want, 1e-9) {
+			t.Errorf("result = %v, objective %v, x %v, want optimal, 4, %v", result.Status, result.Objective, mat.Formatted(result.X.T()), mat.Formatted(want.T()))
+		}
+	}
+	if len(cuts) == 0 {
+		t.Errorf("relaxation 4.25 is integral without cuts")
+	}
+}
+
+func TestGomoryCutsInfeasible(t *testing.T) {
+	problem := parseProblem(t, noIntegerPlan, true)
+	if result, _, err := GomoryCuts(problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector); !errors.Is(err, optim.ErrInfeasible) {
+		t.Errorf("GomoryCuts = %v, %v, want infeasible", result.Status, err)
+	}
+	exact := problem.Exact
+	if result, _, err := ExactGomoryCuts(exact.ScalesVector, exact.ConditionsMatrix, exact.FreeVector); !errors.Is(err, optim.ErrInfeasible) {
+		t.Errorf("ExactGomoryCuts = %v, %v, want infeasible", result.Status, err)
+	}
+}
@@ -0,0 +1,97 @@
+package lp
+
+import (
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// nonnegativeBounds - bounds 0 <= x < inf of varNumber variables
+func nonnegativeBounds(varNumber int) (*mat.VecDense, *mat.VecDense) {
+	lowerBounds, upperBounds := mat.NewVecDense(varNumber, nil), mat.NewVecDense(varNumber, nil)
+	for j := 0; j < varNumber; j++ {
+		upperBounds.SetVec(j, math.Inf(1))
+	}
+	return lowerBounds, upperBounds
+}
+
+// withInfeasibility - infeasibilityOf result of solver run ended with err,
+// optimal result whose plan violates conditions or bounds beyond
+// tolerances.PrimalFeasibility is NotSolved, see optim.CheckPrimalFeasibility
+func withInfeasibility(result optim.Result, err error, tolerances optim.Tolerances, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense) (optim.Result, error) {
+	return optim.CheckPrimalFeasibility(infeasibilityOf(result, scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds), err, tolerances)
+}
+
+// infeasibilityOf - result with PrimalInfeasibility of its plan, the greatest
+// of |b - Ax| and violations of bounds, and DualInfeasibility of its basis,
+// see dualInfeasibility. Infeasibility the iterations of the solver observed
+// and left in result is kept if it's greater. Deltas are found anew for basis
+// of A, ReducedCosts of result are taken if it has no such basis, dual
+// infeasibility is left as it is if it has neither
+func infeasibilityOf(result optim.Result, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds *mat.VecDense) optim.Result {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	if result.X == nil || result.X.Len() != varNumber {
+		return result
+	}
+	result.PrimalInfeasibility = math.Max(result.PrimalInfeasibility, primalInfeasibility(conditionsMatrix, freeVector, lowerBounds, upperBounds, result.X))
+
+	deltas := result.ReducedCosts
+	if isBasisOf(result.Basis, conditionsNumber, varNumber) {
+		components := mat.NewVecDense(conditionsNumber, nil)
+		for i, index := range result.Basis {
+			components.SetVec(i, scalesVector.AtVec(index))
+		}
+		yVector := mat.NewVecDense(conditionsNumber, nil)
+		if err := yVector.SolveVec(linalg.Columns(conditionsMatrix, result.Basis).T(), components); err == nil {
+			deltas = linalg.VecMulMat(yVector, conditionsMatrix)
+			deltas.AddScaledVec(deltas, -1, scalesVector)
+		}
+	}
+	if deltas == nil || deltas.Len() != varNumber {
+		return result
+	}
+	result.DualInfeasibility = math.Max(result.DualInfeasibility, dualInfeasibility(deltas, lowerBounds, upperBounds, result.X))
+	return result
+}
+
+// primalInfeasibility - the greatest of |b - Ax| and violations of bounds by
+// plan x
+func primalInfeasibility(conditionsMatrix *mat.Dense, freeVector, lowerBounds, upperBounds, x *mat.VecDense) float64 {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	residuals := mat.NewVecDense(conditionsNumber, nil)
+	residuals.MulVec(conditionsMatrix, x)
+	residuals.SubVec(freeVector, residuals)
+	infeasibility := 0.0
+	for i := 0; i < conditionsNumber; i++ {
+		infeasibility = math.Max(infeasibility, math.Abs(residuals.AtVec(i)))
+	}
+	for j := 0; j < varNumber; j++ {
+		value := x.AtVec(j)
+		infeasibility = math.Max(infeasibility, math.Max(lowerBounds.AtVec(j)-value, value-upperBounds.AtVec(j)))
+	}
+	return infeasibility
+}
+
+// dualInfeasibility - the greatest violation of signs of deltas y'A - c of
+// plan x: negative delta of variable at lower bound, positive one at upper
+// bound and any nonzero one of variable without bounds. Deltas of baseline
+// and fixed variables aren't violations
+func dualInfeasibility(deltas, lowerBounds, upperBounds, x *mat.VecDense) float64 {
+	infeasibility := 0.0
+	for j := 0; j < deltas.Len(); j++ {
+		value, lower, upper, delta := x.AtVec(j), lowerBounds.AtVec(j), upperBounds.AtVec(j), deltas.AtVec(j)
+		switch {
+		case lower == upper:
+			delta = 0
+		case !math.IsInf(lower, -1) && math.Abs(value-lower) <= math.Abs(value-upper):
+			delta = -delta
+		case !math.IsInf(upper, 1):
+		default:
+			delta = math.Abs(delta)
+		}
+		infeasibility = math.Max(infeasibility, delta)
+	}
+	return infeasibility
+}
@@ -0,0 +1,104 @@
+package lp
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestInfeasibilityOfKeepsObserved(t *testing.T) {
+	// x1 + x2 = 2, x >= 0
+	c, A, b := mat.NewVecDense(2, []float64{1, 1}), mat.NewDense(1, 2, []float64{1, 1}), mat.NewVecDense(1, []float64{2})
+	lowerBounds, upperBounds := nonnegativeBounds(2)
+	tests := []struct {
+		name     string
+		x        []float64
+		observed float64
+		want     float64
+	}{
+		{"observed is greater", []float64{1, 1}, 0.25, 0.25},
+		{"final plan is greater", []float64{3, 0}, 0.25, 1},
+		{"bound is violated", []float64{2.5, -0.5}, 0, 0.5},
+	}
+	for _, test := range tests {
+		result := optim.Result{X: mat.NewVecDense(2, test.x), PrimalInfeasibility: test.observed}
+		if got := infeasibilityOf(result, c, A, b, lowerBounds, upperBounds).PrimalInfeasibility; got != test.want {
+			t.Errorf("%v: primal infeasibility = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWithInfeasibilityOfOptimalPlan(t *testing.T) {
+	// x1 + x2 = 2, x >= 0
+	c, A, b := mat.NewVecDense(2, []float64{1, 1}), mat.NewDense(1, 2, []float64{1, 1}), mat.NewVecDense(1, []float64{2})
+	lowerBounds, upperBounds := nonnegativeBounds(2)
+	tests := []struct {
+		name   string
+		status optim.Status
+		x      []float64
+		want   optim.Status
+	}{
+		{"feasible plan", optim.Optimal, []float64{1, 1}, optim.Optimal},
+		{"rounding errors", optim.Optimal, []float64{1, 1 + 1e-12}, optim.Optimal},
+		{"violated condition", optim.Optimal, []float64{1, 2}, optim.NotSolved},
+		{"violated bound", optim.Optimal, []float64{3, -1}, optim.NotSolved},
+		{"stopped run", optim.IterationLimit, []float64{1, 2}, optim.IterationLimit},
+	}
+	for _, test := range tests {
+		var err error
+		if test.status == optim.IterationLimit {
+			err = optim.ErrIterationLimit
+		}
+		result := optim.Result{Status: test.status, X: mat.NewVecDense(2, test.x)}
+		got, gotErr := withInfeasibility(result, err, optim.DefaultTolerances, c, A, b, lowerBounds, upperBounds)
+		if got.Status != test.want || (test.want == optim.NotSolved) != errors.Is(gotErr, optim.ErrPrimalInfeasibility) {
+			t.Errorf("%v: %v with %v, want %v", test.name, got.Status, gotErr, test.want)
+		}
+	}
+}
+
+func TestDualInfeasibility(t *testing.T) {
+	inf := math.Inf(1)
+	lowerBounds := mat.NewVecDense(4, []float64{0, 0, 1, -inf})
+	upperBounds := mat.NewVecDense(4, []float64{inf, 5, 1, inf})
+	x := mat.NewVecDense(4, []float64{0, 5, 1, 0})
+	tests := []struct {
+		deltas []float64
+		want   float64
+	}{
+		{[]float64{1, -1, 0, 0}, 0},
+		{[]float64{-0.5, 0, 0, 0}, 0.5},
+		{[]float64{0, 2, 0, 0}, 2},
+		// fixed x3 can't improve the objective by any delta
+		{[]float64{0, 0, -3, 0}, 0},
+		{[]float64{0, 0, 0, -0.75}, 0.75},
+	}
+	for _, test := range tests {
+		if got := dualInfeasibility(mat.NewVecDense(4, test.deltas), lowerBounds, upperBounds, x); got != test.want {
+			t.Errorf("deltas %v: dual infeasibility = %v, want %v", test.deltas, got, test.want)
+		}
+	}
+}
+
+func TestSimplexIterationsInfeasibility(t *testing.T) {
+	problem := parseProblem(t, "1 1 0 0\n1 3 1 0\n3 1 0 1\n6 6\n0 0 6 6\n", false)
+	for name, solve := range map[string]func() (optim.Result, error){
+		"simplex": func() (optim.Result, error) {
+			return SimplexMainPhase(problem.ScalesVector, problem.ConditionsMatrix, problem.BaselineVector, problem.BaselineIndexes)
+		},
+		"dual": func() (optim.Result, error) {
+			return DoubleSimplexMethod(problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, nil)
+		},
+	} {
+		result, err := solve()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if math.Abs(result.Objective-3) > 1e-9 || result.PrimalInfeasibility > 1e-9 || result.DualInfeasibility > 1e-9 {
+			t.Errorf("%v: objective %v, infeasibility %v and %v, want 3 and feasible", name, result.Objective, result.PrimalInfeasibility, result.DualInfeasibility)
+		}
+	}
+}
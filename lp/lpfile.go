@@ -0,0 +1,437 @@
+package lp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// lpKeywords - section keywords of CPLEX LP file
+var lpKeywords = map[string]string{
+	"maximize": "maximize",
+	"maximum":  "maximize",
+	"max":      "maximize",
+	"minimize": "minimize",
+	"minimum":  "minimize",
+	"min":      "minimize",
+	"st":       "subject to",
+	"s.t.":     "subject to",
+	"st.":      "subject to",
+	"bounds":   "bounds",
+	"bound":    "bounds",
+	"general":  "integers",
+	"generals": "integers",
+	"gen":      "integers",
+	"binary":   "integers",
+	"binaries": "integers",
+	"bin":      "integers",
+	"end":      "end",
+}
+
+// lpToken kinds
+const (
+	lpName = iota
+	lpNumber
+	lpSign
+	lpRelation
+	lpColon
+)
+
+// lpToken - token of CPLEX LP file
+type lpToken struct {
+	parse.Token
+	kind int
+}
+
+// lpReader - problem of CPLEX LP file read so far
+type lpReader struct {
+	file string
+
+	sense       Sense
+	objective   map[int]float64
+	colNames    []string
+	cols        map[string]int
+	rowNames    []string
+	conditions  []map[int]float64
+	relations   []Relation
+	rhs         []float64
+	lowerBounds []float64
+	upperBounds []float64
+	lowerSet    []bool
+}
+
+// ReadLPFile - problem of CPLEX LP file input
+func ReadLPFile(input string) (GeneralProblem, error) {
+	file, err := os.Open(input)
+	if err != nil {
+		return GeneralProblem{}, err
+	}
+	defer file.Close()
+	return ReadLP(file, input)
+}
+
+// ReadLP - problem of CPLEX LP file read from r, file is used in errors.
+// Sections Maximize (Minimize), Subject To, Bounds and End are supported with
+// names of variables and conditions kept in the problem. Unnamed conditions
+// are named c1, c2, ... by their number
+func ReadLP(r io.Reader, file string) (GeneralProblem, error) {
+	reader := lpReader{file: file, cols: map[string]int{}}
+
+	// tokens of every section
+	sections := map[string][]lpToken{}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if comment := strings.IndexByte(text, '\\'); comment != -1 {
+			text = text[:comment]
+		}
+		keyword, rest := lpKeyword(text)
+		if keyword != "" {
+			if _, ok := sections[keyword]; ok {
+				return GeneralProblem{}, &parse.Error{File: file, Line: line, Column: 1, Msg: fmt.Sprintf("section %v is repeated", keyword)}
+			}
+			section = keyword
+			sections[section] = nil
+		}
+		if section == "end" {
+			break
+		}
+		tokens, err := lpTokens(file, line, len(text)-len(rest), rest)
+		if err != nil {
+			return GeneralProblem{}, err
+		}
+		if section == "" && len(tokens) > 0 {
+			return GeneralProblem{}, tokens[0].Errorf("expected Maximize or Minimize section")
+		}
+		sections[section] = append(sections[section], tokens...)
+	}
+	if err := scanner.Err(); err != nil {
+		return GeneralProblem{}, err
+	}
+
+	objective, ok := sections["maximize"]
+	if _, minimize := sections["minimize"]; minimize == ok {
+		return GeneralProblem{}, fmt.Errorf("%v: expected one of Maximize and Minimize sections", file)
+	} else if minimize {
+		objective, reader.sense = sections["minimize"], Minimize
+	}
+	if tokens := sections["integers"]; len(tokens) > 0 {
+		return GeneralProblem{}, tokens[0].Errorf("integer variables aren't supported")
+	}
+	if err := reader.readObjective(objective); err != nil {
+		return GeneralProblem{}, err
+	}
+	if err := reader.readConditions(sections["subject to"]); err != nil {
+		return GeneralProblem{}, err
+	}
+	if err := reader.readBounds(sections["bounds"]); err != nil {
+		return GeneralProblem{}, err
+	}
+	return reader.problem()
+}
+
+// lpKeyword - section keyword line starts with and the rest of line
+func lpKeyword(text string) (string, string) {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	lower := strings.ToLower(trimmed)
+	for word, keyword := range lpKeywords {
+		if !strings.HasPrefix(lower, word) {
+			continue
+		}
+		rest := trimmed[len(word):]
+		if rest == "" || unicode.IsSpace(rune(rest[0])) {
+			return keyword, rest
+		}
+	}
+	// words of "subject to" may be separated by any spaces
+	if fields := strings.Fields(lower); len(fields) >= 2 && (fields[0] == "subject" && fields[1] == "to" || fields[0] == "such" && fields[1] == "that") {
+		index := strings.Index(lower, fields[1]) + len(fields[1])
+		return "subject to", trimmed[index:]
+	}
+	return "", text
+}
+
+// lpTokens - tokens of text starting at column offset+1 of line
+func lpTokens(file string, line, offset int, text string) ([]lpToken, error) {
+	var tokens []lpToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		token := lpToken{Token: parse.Token{File: file, Line: line, Column: offset + i + 1}}
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '+' || c == '-':
+			token.kind = lpSign
+			i++
+		case c == ':':
+			token.kind = lpColon
+			i++
+		case c == '<' || c == '>' || c == '=':
+			token.kind = lpRelation
+			i++
+			if i < len(text) && (text[i] == '=' || text[i] == '<' || text[i] == '>') {
+				i++
+			}
+		case c >= '0' && c <= '9' || c == '.':
+			token.kind = lpNumber
+			for i < len(text) && (text[i] >= '0' && text[i] <= '9' || text[i] == '.') {
+				i++
+			}
+			// exponent
+			if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+				j := i + 1
+				if j < len(text) && (text[j] == '+' || text[j] == '-') {
+					j++
+				}
+				if j < len(text) && text[j] >= '0' && text[j] <= '9' {
+					for i = j; i < len(text) && text[i] >= '0' && text[i] <= '9'; i++ {
+					}
+				}
+			}
+		case isLPNameChar(c):
+			token.kind = lpName
+			for i < len(text) && (isLPNameChar(text[i]) || text[i] >= '0' && text[i] <= '9' || text[i] == '.') {
+				i++
+			}
+		default:
+			token.Text = string(c)
+			return nil, token.Errorf("unexpected %q", c)
+		}
+		token.Text = text[start:i]
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// isLPNameChar - whether c may start variable or condition name
+func isLPNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || strings.IndexByte("!\"#$%&()/,;?@_`'{}|~", c) != -1
+}
+
+// column - index of variable name, the variable is added if it's new
+func (r *lpReader) column(name string) int {
+	col, ok := r.cols[name]
+	if !ok {
+		col = len(r.colNames)
+		r.cols[name] = col
+		r.colNames = append(r.colNames, name)
+		r.lowerBounds = append(r.lowerBounds, 0)
+		r.upperBounds = append(r.upperBounds, math.Inf(1))
+		r.lowerSet = append(r.lowerSet, false)
+	}
+	return col
+}
+
+// label - name of condition or objective if tokens start with "name:"
+func label(tokens []lpToken) (string, []lpToken) {
+	if len(tokens) >= 2 && tokens[0].kind == lpName && tokens[1].kind == lpColon {
+		return tokens[0].Text, tokens[2:]
+	}
+	return "", tokens
+}
+
+// expression - coefficients of linear expression at the start of tokens and
+// tokens after it. Expression ends at relation or at the end of tokens
+func (r *lpReader) expression(tokens []lpToken) (map[int]float64, []lpToken, error) {
+	coefficients := map[int]float64{}
+	for len(tokens) > 0 && tokens[0].kind != lpRelation {
+		sign := 1.
+		for ; len(tokens) > 0 && tokens[0].kind == lpSign; tokens = tokens[1:] {
+			if tokens[0].Text == "-" {
+				sign = -sign
+			}
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		coefficient := 1.
+		if tokens[0].kind == lpNumber {
+			number, err := tokens[0].Number()
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(tokens) == 1 || tokens[1].kind != lpName {
+				return nil, nil, tokens[0].Errorf("constant terms aren't supported")
+			}
+			coefficient, tokens = number, tokens[1:]
+		}
+		if tokens[0].kind != lpName {
+			return nil, nil, tokens[0].Errorf("expected variable, got %q", tokens[0].Text)
+		}
+		coefficients[r.column(tokens[0].Text)] += sign * coefficient
+		tokens = tokens[1:]
+	}
+	return coefficients, tokens, nil
+}
+
+func (r *lpReader) readObjective(tokens []lpToken) error {
+	_, tokens = label(tokens)
+	objective, tokens, err := r.expression(tokens)
+	if err != nil {
+		return err
+	}
+	if len(tokens) > 0 {
+		return tokens[0].Errorf("unexpected %q in objective", tokens[0].Text)
+	}
+	r.objective = objective
+	return nil
+}
+
+func (r *lpReader) readConditions(tokens []lpToken) error {
+	for len(tokens) > 0 {
+		name, rest := label(tokens)
+		if name == "" {
+			name = fmt.Sprintf("c%v", len(r.rowNames)+1)
+		}
+		condition, rest, err := r.expression(rest)
+		if err != nil {
+			return err
+		}
+		if len(rest) == 0 {
+			return tokens[0].Errorf("expected <=, >= or = in condition %v", name)
+		}
+		relation := lpRelationOf(rest[0])
+		value, rest, err := lpValue(rest[1:], rest[0])
+		if err != nil {
+			return err
+		}
+		r.rowNames = append(r.rowNames, name)
+		r.conditions = append(r.conditions, condition)
+		r.relations = append(r.relations, relation)
+		r.rhs = append(r.rhs, value)
+		tokens = rest
+	}
+	return nil
+}
+
+func (r *lpReader) readBounds(tokens []lpToken) error {
+	for len(tokens) > 0 {
+		// x free
+		if len(tokens) >= 2 && tokens[0].kind == lpName && strings.EqualFold(tokens[1].Text, "free") {
+			col := r.column(tokens[0].Text)
+			r.lowerBounds[col], r.upperBounds[col], r.lowerSet[col] = math.Inf(-1), math.Inf(1), true
+			tokens = tokens[2:]
+			continue
+		}
+
+		// x rel value
+		if tokens[0].kind == lpName && !isLPInfinity(tokens[0].Text) {
+			col := r.column(tokens[0].Text)
+			if len(tokens) < 2 || tokens[1].kind != lpRelation {
+				return tokens[0].Errorf("expected <=, >=, = or free after %v", tokens[0].Text)
+			}
+			value, rest, err := lpValue(tokens[2:], tokens[1])
+			if err != nil {
+				return err
+			}
+			r.bound(col, lpRelationOf(tokens[1]), value)
+			tokens = rest
+			continue
+		}
+
+		// value rel x [rel value]
+		value, rest, err := lpValue(tokens, tokens[0])
+		if err != nil {
+			return err
+		}
+		if len(rest) < 2 || rest[0].kind != lpRelation || rest[1].kind != lpName {
+			return tokens[0].Errorf("expected bound \"value <= x\"")
+		}
+		col := r.column(rest[1].Text)
+		// value <= x is x >= value
+		r.bound(col, [...]Relation{GreaterEqual, LessEqual, Equal}[lpRelationOf(rest[0])], value)
+		tokens = rest[2:]
+		if len(tokens) > 0 && tokens[0].kind == lpRelation {
+			relation := lpRelationOf(tokens[0])
+			if value, tokens, err = lpValue(tokens[1:], tokens[0]); err != nil {
+				return err
+			}
+			r.bound(col, relation, value)
+		}
+	}
+	return nil
+}
+
+// bound - sets bound x[col] relation value
+func (r *lpReader) bound(col int, relation Relation, value float64) {
+	switch relation {
+	case GreaterEqual:
+		r.lowerBounds[col], r.lowerSet[col] = value, true
+	case LessEqual:
+		r.upperBounds[col] = value
+		if value < 0 && !r.lowerSet[col] {
+			r.lowerBounds[col] = math.Inf(-1)
+		}
+	case Equal:
+		r.lowerBounds[col], r.upperBounds[col], r.lowerSet[col] = value, value, true
+	}
+}
+
+// lpRelationOf - Relation of relation token, < and > are <= and >=
+func lpRelationOf(token lpToken) Relation {
+	switch token.Text {
+	case "=":
+		return Equal
+	case ">=", "=>", ">":
+		return GreaterEqual
+	}
+	return LessEqual
+}
+
+func isLPInfinity(text string) bool {
+	return strings.EqualFold(text, "inf") || strings.EqualFold(text, "infinity")
+}
+
+// lpValue - signed number or infinity at the start of tokens that go after
+// token previous
+func lpValue(tokens []lpToken, previous lpToken) (float64, []lpToken, error) {
+	sign := 1.
+	if len(tokens) > 0 && tokens[0].kind == lpSign {
+		if tokens[0].Text == "-" {
+			sign = -1
+		}
+		previous, tokens = tokens[0], tokens[1:]
+	}
+	switch {
+	case len(tokens) == 0:
+		return 0, nil, previous.Errorf("expected number after %q", previous.Text)
+	case tokens[0].kind == lpName && isLPInfinity(tokens[0].Text):
+		return sign * math.Inf(1), tokens[1:], nil
+	case tokens[0].kind != lpNumber:
+		return 0, nil, tokens[0].Errorf("expected number, got %q", tokens[0].Text)
+	}
+	number, err := tokens[0].Number()
+	return sign * number, tokens[1:], err
+}
+
+// problem - GeneralProblem of conditions and variables read
+func (r *lpReader) problem() (GeneralProblem, error) {
+	if len(r.rowNames) == 0 || len(r.colNames) == 0 {
+		return GeneralProblem{}, fmt.Errorf("%v: problem has no conditions or no variables", r.file)
+	}
+	scalesVector := mat.NewVecDense(len(r.colNames), nil)
+	for col, value := range r.objective {
+		scalesVector.SetVec(col, value)
+	}
+	conditionsMatrix := mat.NewDense(len(r.rowNames), len(r.colNames), nil)
+	for i, condition := range r.conditions {
+		for col, value := range condition {
+			conditionsMatrix.Set(i, col, value)
+		}
+	}
+	problem := NewGeneralProblem(r.sense, scalesVector, conditionsMatrix, r.relations, mat.NewVecDense(len(r.rhs), r.rhs))
+	problem.LowerBounds = mat.NewVecDense(len(r.lowerBounds), r.lowerBounds)
+	problem.UpperBounds = mat.NewVecDense(len(r.upperBounds), r.upperBounds)
+	problem.VarNames, problem.ConditionNames = r.colNames, r.rowNames
+	return problem, nil
+}
@@ -0,0 +1,117 @@
+package lp
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadLP(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		name string
+		text string
+		want GeneralProblem
+	}{
+		{
+			"continuation lines and unnamed conditions",
+			`\ objective and condition lim go on the next lines
+Maximize
+ obj: 3 x1 + 2 x2
+   - x3
+Subject To
+ lim: x1 + x2
+   + x3 <= 4
+ x1 - x2 >= -2
+ 2 x2
+   = 1
+End
+`,
+			generalProblem(Maximize, []float64{3, 2, -1},
+				[][]float64{{1, 1, 1}, {1, -1, 0}, {0, 2, 0}},
+				[]Relation{LessEqual, GreaterEqual, Equal}, []float64{4, -2, 1},
+				[]float64{0, 0, 0}, []float64{inf, inf, inf},
+				[]string{"x1", "x2", "x3"}, []string{"lim", "c2", "c3"}),
+		},
+		{
+			"bounds",
+			`Minimize
+ x1 + x2 + x3 + x4 + x5
+Subject To
+ x1 + x2 + x3 + x4 + x5 >= 1
+Bounds
+ -inf <= x1 <= 5
+ x2 free
+ x3 <= -1
+ -2 <= x4
+ x5 = 3
+End
+`,
+			generalProblem(Minimize, []float64{1, 1, 1, 1, 1},
+				[][]float64{{1, 1, 1, 1, 1}},
+				[]Relation{GreaterEqual}, []float64{1},
+				[]float64{-inf, -inf, -inf, -2, 3}, []float64{5, inf, -1, inf, 3},
+				[]string{"x1", "x2", "x3", "x4", "x5"}, []string{"c1"}),
+		},
+	}
+	for _, test := range tests {
+		problem, err := ReadLP(strings.NewReader(test.text), "test.lp")
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		checkProblem(t, test.name, problem, test.want)
+	}
+}
+
+func TestReadLPErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Maximize\n x1\nSubject To\n x1 <= 1\nBinary\n x1\nEnd\n", "test.lp:6:2: integer variables aren't supported"},
+		{"Maximize\n x1\nSubject To\n x1 <= 1\nGeneral\n x1\nEnd\n", "test.lp:6:2: integer variables aren't supported"},
+		{"Maximize\n x1\nSubject To\n x1 + 3 <= 4\nEnd\n", "test.lp:4:7: constant terms aren't supported"},
+		{"Maximize\n x1\nSubject To\n c1: x1 + x2\nEnd\n", "test.lp:4:2: expected <=, >= or = in condition c1"},
+		{"Maximize\n x1\nSubject To\n x1 <= 1\nBounds\n x1 <=\nEnd\n", "test.lp:6:5: expected number after \"<=\""},
+		{" x1 + x2\n", "test.lp:1:2: expected Maximize or Minimize section"},
+	}
+	for _, test := range tests {
+		_, err := ReadLP(strings.NewReader(test.text), "test.lp")
+		if err == nil || err.Error() != test.want {
+			t.Errorf("ReadLP(%q) = %v, want %v", test.text, err, test.want)
+		}
+	}
+}
+
+func TestLPToMPSRoundTrip(t *testing.T) {
+	text := `Maximize
+ obj: 3 x1 + 2 x2 - x3 + 0.125 x4
+Subject To
+ lim: x1 + x2 + x3 <= 4
+ x1 - x2 >= -2
+ fix: 2 x2 + x4 = 1.5
+Bounds
+ -inf <= x1 <= 5
+ x2 free
+ x3 <= -1
+ -2 <= x4 <= 1e6
+End
+`
+	problem, err := ReadLP(strings.NewReader(text), "test.lp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, free := range []bool{true, false} {
+		var mps bytes.Buffer
+		if err := WriteMPS(&mps, problem, free); err != nil {
+			t.Fatal(err)
+		}
+		written, err := ReadMPS(&mps, "test.mps", free)
+		if err != nil {
+			t.Fatalf("free %v: %v\n%v", free, err, mps.String())
+		}
+		checkProblem(t, map[bool]string{true: "free MPS", false: "fixed MPS"}[free], written, problem)
+	}
+}
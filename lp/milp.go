@@ -0,0 +1,268 @@
+package lp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// NodeSelection - which open node branch and bound solves next
+type NodeSelection int
+
+const (
+	// BestFirst - node with the best bound, the objective of its parent
+	BestFirst NodeSelection = iota
+	// DepthFirst - the last node branched, it finds integer plans sooner
+	DepthFirst
+)
+
+func (s NodeSelection) String() string {
+	switch s {
+	case BestFirst:
+		return "best"
+	case DepthFirst:
+		return "depth"
+	}
+	return "unknown"
+}
+
+// IntegerResult - what branch and bound returns. Result holds the best integer
+// plan found (incumbent) without basis and dual solution, Bound is the best
+// objective integer plans may have, Gap is (Bound - Objective) / max(1,
+// |Objective|) and is +Inf while there's no incumbent, Nodes is the number of
+// solved relaxations
+type IntegerResult struct {
+	Result optim.Result
+	Bound  float64
+	Gap    float64
+	Nodes  int
+}
+
+// branchRow - bound of branch, x[index] <= value or x[index] >= value, added
+// as a row with its slack variable
+type branchRow struct {
+	index    int
+	relation Relation
+	value    float64
+}
+
+// branchNode - relaxation of branch and bound with rows of its branches. Its
+// parent's optimal basis with the slack of the last row is dual feasible for
+// it, bound is the parent's objective
+type branchNode struct {
+	rows  []branchRow
+	basis []int
+	bound float64
+	depth int
+}
+
+// BranchAndBound - solves c'x -> max, Ax = b, x >= 0, x[j] integer for j of
+// integerIndexes with branch and bound. Relaxation of the root is solved with
+// both phases of the simplex method, relaxations of the branches with the dual
+// simplex method starting from their parent's optimal basis. Branches are made
+// on the most fractional variable. Returns optim.ErrInfeasible if there's no
+// integer plan and optim.ErrUnbounded if the relaxation is unbounded
+func BranchAndBound(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, integerIndexes []int, selection NodeSelection) (IntegerResult, error) {
+	return BranchAndBoundContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector, integerIndexes, selection)
+}
+
+// BranchAndBoundContext - BranchAndBound stopped with optim.ErrCanceled when
+// ctx is done or options.TimeLimit of the whole search passes and with
+// optim.ErrIterationLimit after options.MaxIterations pivots of a relaxation.
+// Result of stopped search holds the incumbent if there's one, Bound and Gap
+// say how far from optimal it may be
+func BranchAndBoundContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, integerIndexes []int, selection NodeSelection) (IntegerResult, error) {
+	_, varNumber := conditionsMatrix.Dims()
+	for _, index := range integerIndexes {
+		if index < 0 || index >= varNumber {
+			return IntegerResult{Gap: math.Inf(1)}, fmt.Errorf("there's no variable %v", index+1)
+		}
+	}
+	limits := optim.NewLimits(ctx, options)
+	tolerances := limits.Tolerances()
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	incumbent := IntegerResult{Result: optim.Result{Status: optim.Infeasible, Objective: math.Inf(-1)}, Bound: math.Inf(1)}
+	open := []branchNode{{bound: math.Inf(1)}}
+	for len(open) > 0 {
+		var node branchNode
+		node, open = nextNode(open, selection)
+		if isPruned(node.bound, incumbent.Result.Objective, tolerances) {
+			continue
+		}
+		if status, err := limits.Check(0); err != nil {
+			return incumbent.stopped(status, node, open), err
+		}
+
+		relaxation, err := node.solve(limits, scalesVector, conditionsMatrix, freeVector)
+		incumbent.Nodes++
+		incumbent.Result.Iterations += relaxation.Iterations
+		optim.Tracef("node %v at depth %v: %v, objective %v\n", incumbent.Nodes, node.depth, relaxation.Status, relaxation.Objective)
+		switch {
+		case errors.Is(err, optim.ErrInfeasible):
+			continue
+		case errors.Is(err, optim.ErrUnbounded):
+			incumbent.Result = optim.Result{Status: optim.Unbounded, Iterations: incumbent.Result.Iterations}
+			incumbent.Bound, incumbent.Gap = math.Inf(1), math.Inf(1)
+			return incumbent, fmt.Errorf("%w: relaxation is unbounded", optim.ErrUnbounded)
+		case err != nil:
+			return incumbent.stopped(relaxation.Status, node, open), err
+		}
+		if isPruned(relaxation.Objective, incumbent.Result.Objective, tolerances) {
+			continue
+		}
+
+		branching := mostFractional(relaxation.X, integerIndexes, tolerances.Integrality)
+		if branching < 0 {
+			incumbent.Result = infeasibilityOf(integerPlan(scalesVector, relaxation.X, integerIndexes, varNumber, incumbent.Result.Iterations), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+			optim.Tracef("new incumbent %v\n", incumbent.Result.Objective)
+			continue
+		}
+		value := relaxation.X.AtVec(branching)
+		optim.Tracef("branching on x%v = %v\n", branching+1, value)
+		down := node.child(branchRow{index: branching, relation: LessEqual, value: math.Floor(value)}, relaxation)
+		up := node.child(branchRow{index: branching, relation: GreaterEqual, value: math.Ceil(value)}, relaxation)
+		// depth first search goes to the nearer integer first
+		if value-math.Floor(value) > 0.5 {
+			open = append(open, down, up)
+		} else {
+			open = append(open, up, down)
+		}
+	}
+
+	if incumbent.Result.X == nil {
+		incumbent.Result.Objective, incumbent.Gap = 0, math.Inf(1)
+		return incumbent, fmt.Errorf("%w: there's no integer plan", optim.ErrInfeasible)
+	}
+	incumbent.Result.Status = optim.Optimal
+	incumbent.Bound, incumbent.Gap = incumbent.Result.Objective, 0
+	return incumbent, nil
+}
+
+// isPruned - whether node with bound can't improve incumbent objective by
+// more than tolerances.DualityGap relative to max(1, |objective|)
+func isPruned(bound, objective float64, tolerances optim.Tolerances) bool {
+	if math.IsInf(objective, -1) {
+		return false
+	}
+	return bound <= objective+tolerances.DualityGap*math.Max(1, math.Abs(objective))
+}
+
+// nextNode - node of open to solve next and the rest of them
+func nextNode(open []branchNode, selection NodeSelection) (branchNode, []branchNode) {
+	next := len(open) - 1
+	if selection == BestFirst {
+		for i := range open {
+			if open[i].bound > open[next].bound {
+				next = i
+			}
+		}
+	}
+	node := open[next]
+	return node, append(open[:next], open[next+1:]...)
+}
+
+// isBasisOf - whether basis has index of a column for every row, baseline
+// plans of the preparation phase have artificial indexes or fewer of them
+func isBasisOf(basis []int, rowsNumber, columnsNumber int) bool {
+	if len(basis) != rowsNumber {
+		return false
+	}
+	for _, index := range basis {
+		if index >= columnsNumber {
+			return false
+		}
+	}
+	return true
+}
+
+// solve - solves relaxation of node with the dual simplex method from its
+// basis, with both phases if it has no basis or the basis is singular
+func (node branchNode) solve(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	rowsNumber, columnsNumber := conditionsNumber+len(node.rows), varNumber+len(node.rows)
+	extendedConditions := mat.NewDense(rowsNumber, columnsNumber, nil)
+	extendedConditions.Slice(0, conditionsNumber, 0, varNumber).(*mat.Dense).Copy(conditionsMatrix)
+	extendedScales := mat.NewVecDense(columnsNumber, nil)
+	extendedScales.SliceVec(0, varNumber).(*mat.VecDense).CopyVec(scalesVector)
+	extendedFree := mat.NewVecDense(rowsNumber, nil)
+	extendedFree.SliceVec(0, conditionsNumber).(*mat.VecDense).CopyVec(freeVector)
+	for k, row := range node.rows {
+		extendedConditions.Set(conditionsNumber+k, row.index, 1)
+		extendedConditions.Set(conditionsNumber+k, varNumber+k, 1)
+		if row.relation == GreaterEqual {
+			extendedConditions.Set(conditionsNumber+k, varNumber+k, -1)
+		}
+		extendedFree.SetVec(conditionsNumber+k, row.value)
+	}
+
+	if isBasisOf(node.basis, rowsNumber, columnsNumber) {
+		result, err := doubleSimplexMethod(limits, extendedScales, extendedConditions, extendedFree, linalg.FloatVector(node.basis))
+		if !errors.Is(err, optim.ErrSingularBasis) {
+			return result, err
+		}
+		optim.Tracef("basis %v is singular, solving anew\n", oneBasedIndexes(node.basis))
+	}
+	return solveCanonical(limits, extendedScales, extendedConditions, extendedFree)
+}
+
+// child - node with one more branch row whose basis is basis of parent's
+// relaxation with the slack variable of the row
+func (node branchNode) child(row branchRow, relaxation optim.Result) branchNode {
+	return branchNode{
+		rows:  append(append([]branchRow{}, node.rows...), row),
+		basis: append(append([]int{}, relaxation.Basis...), relaxation.X.Len()),
+		bound: relaxation.Objective,
+		depth: node.depth + 1,
+	}
+}
+
+// stopped - incumbent of search stopped with status before solving node,
+// Bound is the best bound of node and open ones
+func (incumbent IntegerResult) stopped(status optim.Status, node branchNode, open []branchNode) IntegerResult {
+	incumbent.Bound = node.bound
+	for _, other := range open {
+		incumbent.Bound = math.Max(incumbent.Bound, other.bound)
+	}
+	incumbent.Gap = math.Inf(1)
+	if incumbent.Result.X == nil {
+		incumbent.Result.Objective = 0
+	} else {
+		incumbent.Bound = math.Max(incumbent.Bound, incumbent.Result.Objective)
+		incumbent.Gap = (incumbent.Bound - incumbent.Result.Objective) / math.Max(1, math.Abs(incumbent.Result.Objective))
+	}
+	incumbent.Result.Status = status
+	return incumbent
+}
+
+// mostFractional - index of integerIndexes whose value of plan is the
+// farthest from integers, -1 if all of them are within tolerance from integers
+func mostFractional(plan *mat.VecDense, integerIndexes []int, tolerance float64) int {
+	branching, farthest := -1, tolerance
+	for _, index := range integerIndexes {
+		value := plan.AtVec(index)
+		if distance := math.Abs(value - math.Round(value)); distance > farthest {
+			branching, farthest = index, distance
+		}
+	}
+	return branching
+}
+
+// integerPlan - Result of integer plan of relaxation without slack variables
+// of branch rows. Integer values are rounded
+func integerPlan(scalesVector, plan *mat.VecDense, integerIndexes []int, varNumber, iterations int) optim.Result {
+	x := mat.VecDenseCopyOf(plan.SliceVec(0, varNumber))
+	for _, index := range integerIndexes {
+		x.SetVec(index, math.Round(x.AtVec(index))+0)
+	}
+	return optim.Result{
+		Status:     optim.Optimal,
+		Objective:  mat.Dot(scalesVector, x),
+		X:          x,
+		Iterations: iterations,
+	}
+}
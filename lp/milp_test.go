@@ -0,0 +1,89 @@
+package lp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// knapsack - 2x1 + 3x2 + 2x3 -> max, 3x1 + 4x2 + 2x3 <= 5, x <= 1 with
+// slacks. Relaxation is 4.25 at x = (0, 3/4, 1), integer plan is 4 at
+// x = (1, 0, 1)
+const knapsack = `2 3 2 0 0 0 0
+3 4 2 1 0 0 0
+1 0 0 0 1 0 0
+0 1 0 0 0 1 0
+0 0 1 0 0 0 1
+5 1 1 1
+`
+
+// noIntegerPlan - x1 + x2 -> max, 2x1 + 2x2 = 3 has plans but no integer
+// ones
+const noIntegerPlan = `1 1
+2 2
+3
+`
+
+func TestBranchAndBound(t *testing.T) {
+	problem, integerIndexes, err := ParseIntegerProblem(parseBlock(knapsack + "1 2 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mat.NewVecDense(7, []float64{1, 0, 1, 0, 0, 1, 0})
+	for _, selection := range []NodeSelection{BestFirst, DepthFirst} {
+		result, err := BranchAndBound(problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, integerIndexes, selection)
+		if err != nil {
+			t.Fatalf("%v: %v", selection, err)
+		}
+		if result.Result.Status != optim.Optimal || math.Abs(result.Result.Objective-4) > 1e-9 || !mat.EqualApprox(result.Result.X, want, 1e-9) {
+			t.Errorf("%v: %v, objective %v, x %v, want optimal, 4, %v", selection, result.Result.Status, result.Result.Objective, mat.Formatted(result.Result.X.T()), mat.Formatted(want.T()))
+		}
+		if result.Gap != 0 || result.Bound != result.Result.Objective || result.Nodes < 2 {
+			t.Errorf("%v: bound %v, gap %v, %v nodes, want bound of the objective, gap 0 and branches", selection, result.Bound, result.Gap, result.Nodes)
+		}
+	}
+}
+
+func TestBranchAndBoundInfeasible(t *testing.T) {
+	problem, integerIndexes, err := ParseIntegerProblem(parseBlock(noIntegerPlan + "1 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, selection := range []NodeSelection{BestFirst, DepthFirst} {
+		result, err := BranchAndBound(problem.ScalesVector, problem.ConditionsMatrix, problem.FreeVector, integerIndexes, selection)
+		if !errors.Is(err, optim.ErrInfeasible) || result.Result.Status != optim.Infeasible || !math.IsInf(result.Gap, 1) {
+			t.Errorf("%v: %v, gap %v, error %v, want infeasible", selection, result.Result.Status, result.Gap, err)
+		}
+	}
+}
+
+func TestBranchAndBoundRelativeGap(t *testing.T) {
+	// 13x1 + 11x2 + 12x3 -> max, 7x1 + 2x2 + 5x3 <= 13 is 66 at x2 = 6.
+	// Branches within 5% of the incumbent are skipped the same way whatever
+	// the scale of c is
+	problem, integerIndexes, err := ParseIntegerProblem(parseBlock("13 11 12 0\n7 2 5 1\n13\n1 2 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	solve := func(scale, gap float64) IntegerResult {
+		scalesVector := mat.NewVecDense(problem.ScalesVector.Len(), nil)
+		scalesVector.ScaleVec(scale, problem.ScalesVector)
+		options := optim.Options{Tolerances: optim.Tolerances{DualityGap: gap}}
+		result, err := BranchAndBoundContext(context.Background(), options, scalesVector, problem.ConditionsMatrix, problem.FreeVector, integerIndexes, DepthFirst)
+		if err != nil {
+			t.Fatalf("scale %v, gap %v: %v", scale, gap, err)
+		}
+		return result
+	}
+	exact := solve(1, 1e-9)
+	for _, scale := range []float64{1, 1000} {
+		result := solve(scale, 0.05)
+		if math.Abs(result.Result.Objective-66*scale) > 1e-9*scale || result.Nodes >= exact.Nodes {
+			t.Errorf("scale %v: objective %v in %v nodes, want %v in fewer than %v", scale, result.Result.Objective, result.Nodes, 66*scale, exact.Nodes)
+		}
+	}
+}
@@ -0,0 +1,564 @@
+package lp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// mpsFields - first and last columns (numeration starts from 1) of the six
+// fields of fixed MPS line
+var mpsFields = [6][2]int{{2, 3}, {5, 12}, {15, 22}, {25, 36}, {40, 47}, {50, 61}}
+
+// mpsInfinity - bounds of this absolute value or greater are infinite
+const mpsInfinity = 1e30
+
+// mpsReader - problem of MPS file read so far
+type mpsReader struct {
+	file string
+	free bool
+
+	name      string
+	sense     Sense
+	objective string
+	freeRows  map[string]bool // N rows besides the objective one are ignored
+	sets      map[string]string
+
+	rowNames  []string
+	rows      map[string]int
+	relations []Relation
+	rhs       map[int]float64
+	ranges    map[int]float64
+
+	colNames    []string
+	cols        map[string]int
+	scales      []float64
+	conditions  []map[int]float64 // values of column by row
+	lowerBounds []float64
+	upperBounds []float64
+	lowerSet    []bool
+}
+
+// ReadMPSFile - problem of MPS file input. The file is read as free MPS and,
+// if that fails, as fixed one
+func ReadMPSFile(input string) (GeneralProblem, error) {
+	text, err := ioutil.ReadFile(input)
+	if err != nil {
+		return GeneralProblem{}, err
+	}
+	problem, err := ReadMPS(bytes.NewReader(text), input, true)
+	if err != nil {
+		if fixedProblem, fixedErr := ReadMPS(bytes.NewReader(text), input, false); fixedErr == nil {
+			return fixedProblem, nil
+		}
+	}
+	return problem, err
+}
+
+// ReadMPS - problem of fixed or free MPS read from r, file is used in errors.
+// Sections NAME, OBJSENSE, ROWS, COLUMNS, RHS, RANGES, BOUNDS and ENDATA are
+// supported, only the first vector of RHS, RANGES and BOUNDS is used. Ranged
+// condition l <= A[i]x <= u becomes A[i]x >= l and A[i]x <= u named with
+// _range suffix. Integer columns and objective constant aren't supported
+func ReadMPS(r io.Reader, file string, free bool) (GeneralProblem, error) {
+	reader := mpsReader{
+		file:     file,
+		free:     free,
+		sense:    Minimize,
+		freeRows: map[string]bool{},
+		sets:     map[string]string{},
+		rows:     map[string]int{},
+		rhs:      map[int]float64{},
+		ranges:   map[int]float64{},
+		cols:     map[string]int{},
+	}
+	scanner := bufio.NewScanner(r)
+	section := ""
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace)
+		if text == "" || text[0] == '*' {
+			continue
+		}
+
+		// section header
+		if text[0] != ' ' && text[0] != '\t' {
+			tokens := mpsTokens(file, line, text)
+			section = tokens[0].Text
+			switch section {
+			case "NAME":
+				reader.name = strings.TrimSpace(text[len(section):])
+			case "OBJSENSE":
+				if len(tokens) > 1 {
+					if err := reader.readSense(tokens[1]); err != nil {
+						return GeneralProblem{}, err
+					}
+				}
+			case "ROWS", "COLUMNS", "RHS", "RANGES", "BOUNDS":
+			case "ENDATA":
+				return reader.problem()
+			default:
+				return GeneralProblem{}, tokens[0].Errorf("unknown section %v", section)
+			}
+			continue
+		}
+
+		if section == "OBJSENSE" {
+			if err := reader.readSense(mpsTokens(file, line, text)[0]); err != nil {
+				return GeneralProblem{}, err
+			}
+			continue
+		}
+		fields, err := reader.fields(section, line, text)
+		if err != nil {
+			return GeneralProblem{}, err
+		}
+		switch section {
+		case "ROWS":
+			err = reader.readRow(fields)
+		case "COLUMNS":
+			err = reader.readColumn(fields)
+		case "RHS", "RANGES":
+			err = reader.readValues(section, fields)
+		case "BOUNDS":
+			err = reader.readBound(fields)
+		default:
+			err = fields[0].Errorf("data line outside of section")
+		}
+		if err != nil {
+			return GeneralProblem{}, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return GeneralProblem{}, err
+	}
+	return reader.problem()
+}
+
+// mpsTokens - whitespace separated fields of free MPS line
+func mpsTokens(file string, line int, text string) []parse.Token {
+	var tokens []parse.Token
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsSpace(r) {
+			if start != -1 {
+				tokens = append(tokens, parse.Token{Text: text[start:i], File: file, Line: line, Column: start + 1})
+				start = -1
+			}
+		} else if start == -1 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// fields - six fields of MPS data line, absent fields have empty Text. Fields
+// of free MPS line are placed by their number and section
+func (r *mpsReader) fields(section string, line int, text string) ([6]parse.Token, error) {
+	var fields [6]parse.Token
+	for i := range fields {
+		fields[i] = parse.Token{File: r.file, Line: line, Column: mpsFields[i][0]}
+	}
+	if !r.free {
+		for i, columns := range mpsFields {
+			if len(text) < columns[0] {
+				break
+			}
+			field := text[columns[0]-1 : min(columns[1], len(text))]
+			trimmed := strings.TrimLeft(field, " ")
+			fields[i].Column += len(field) - len(trimmed)
+			fields[i].Text = strings.TrimSpace(trimmed)
+		}
+		return fields, nil
+	}
+
+	tokens := mpsTokens(r.file, line, text)
+	first := 0
+	switch section {
+	case "COLUMNS":
+		first = 1
+	case "RHS", "RANGES":
+		// set name may be omitted
+		first = 2 - len(tokens)%2
+	case "BOUNDS":
+		// set name may be omitted, FR, MI, PL and BV bounds have no value
+		withValue := 0
+		switch tokens[0].Text {
+		case "UP", "LO", "FX", "LI", "UI", "SC":
+			withValue = 1
+		}
+		if len(tokens) == 3+withValue {
+			copy(fields[:], tokens)
+			return fields, nil
+		}
+		fields[0] = tokens[0]
+		tokens, first = tokens[1:], 2
+	}
+	if first+len(tokens) > len(fields) {
+		return fields, tokens[len(fields)-first].Errorf("unexpected field %q", tokens[len(fields)-first].Text)
+	}
+	copy(fields[first:], tokens)
+	return fields, nil
+}
+
+func (r *mpsReader) readSense(token parse.Token) error {
+	switch token.Text {
+	case "MAX", "MAXIMIZE":
+		r.sense = Maximize
+	case "MIN", "MINIMIZE":
+		r.sense = Minimize
+	default:
+		return token.Errorf("expected MAX or MIN, got %q", token.Text)
+	}
+	return nil
+}
+
+func (r *mpsReader) readRow(fields [6]parse.Token) error {
+	name := fields[1].Text
+	if name == "" {
+		return fields[1].Errorf("expected row name")
+	}
+	if _, ok := r.rows[name]; ok || r.freeRows[name] || name == r.objective {
+		return fields[1].Errorf("row %v is already defined", name)
+	}
+	var relation Relation
+	switch fields[0].Text {
+	case "N":
+		if r.objective == "" {
+			r.objective = name
+		} else {
+			r.freeRows[name] = true
+		}
+		return nil
+	case "L":
+		relation = LessEqual
+	case "G":
+		relation = GreaterEqual
+	case "E":
+		relation = Equal
+	default:
+		return fields[0].Errorf("expected row type N, L, G or E, got %q", fields[0].Text)
+	}
+	r.rows[name] = len(r.rowNames)
+	r.rowNames = append(r.rowNames, name)
+	r.relations = append(r.relations, relation)
+	return nil
+}
+
+func (r *mpsReader) readColumn(fields [6]parse.Token) error {
+	if fields[2].Text == "'MARKER'" {
+		return fields[2].Errorf("integer columns aren't supported")
+	}
+	name := fields[1].Text
+	col, ok := r.cols[name]
+	if !ok {
+		col = len(r.colNames)
+		r.cols[name] = col
+		r.colNames = append(r.colNames, name)
+		r.scales = append(r.scales, 0)
+		r.conditions = append(r.conditions, map[int]float64{})
+		r.lowerBounds = append(r.lowerBounds, 0)
+		r.upperBounds = append(r.upperBounds, math.Inf(1))
+		r.lowerSet = append(r.lowerSet, false)
+	}
+	return r.readPairs(fields, func(row int, value float64) {
+		if row == -1 {
+			r.scales[col] = value
+		} else {
+			r.conditions[col][row] = value
+		}
+	})
+}
+
+// readValues - values of RHS or RANGES line
+func (r *mpsReader) readValues(section string, fields [6]parse.Token) error {
+	if !r.inSet(section, fields[1].Text) {
+		return nil
+	}
+	var objectiveErr error
+	err := r.readPairs(fields, func(row int, value float64) {
+		switch {
+		case row == -1 && value != 0:
+			objectiveErr = fields[2].Errorf("objective constant isn't supported")
+		case row == -1:
+		case section == "RHS":
+			r.rhs[row] = value
+		default:
+			r.ranges[row] = value
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return objectiveErr
+}
+
+// readPairs - calls set for (row name, value) pairs of fields 3-4 and 5-6,
+// row is -1 for the objective, values of free rows are skipped
+func (r *mpsReader) readPairs(fields [6]parse.Token, set func(row int, value float64)) error {
+	for i := 2; i < 6; i += 2 {
+		name := fields[i].Text
+		if name == "" {
+			if i == 2 {
+				return fields[i].Errorf("expected row name")
+			}
+			break
+		}
+		value, err := fields[i+1].Number()
+		if err != nil {
+			return err
+		}
+		row, ok := r.rows[name]
+		switch {
+		case name == r.objective:
+			row = -1
+		case r.freeRows[name]:
+			continue
+		case !ok:
+			return fields[i].Errorf("unknown row %v", name)
+		}
+		set(row, value)
+	}
+	return nil
+}
+
+// inSet - whether line of set of section is used: the first set met is used,
+// as well as lines of free MPS with omitted set name
+func (r *mpsReader) inSet(section, set string) bool {
+	if set == "" {
+		return true
+	}
+	first, ok := r.sets[section]
+	if !ok {
+		r.sets[section] = set
+		return true
+	}
+	return first == set
+}
+
+func (r *mpsReader) readBound(fields [6]parse.Token) error {
+	if !r.inSet("BOUNDS", fields[1].Text) {
+		return nil
+	}
+	col, ok := r.cols[fields[2].Text]
+	if !ok {
+		return fields[2].Errorf("unknown column %q", fields[2].Text)
+	}
+	value := 0.
+	switch fields[0].Text {
+	case "UP", "LO", "FX":
+		var err error
+		if value, err = fields[3].Number(); err != nil {
+			return err
+		}
+		if value >= mpsInfinity {
+			value = math.Inf(1)
+		} else if value <= -mpsInfinity {
+			value = math.Inf(-1)
+		}
+	}
+	switch fields[0].Text {
+	case "UP":
+		r.upperBounds[col] = value
+		if value < 0 && !r.lowerSet[col] {
+			r.lowerBounds[col] = math.Inf(-1)
+		}
+	case "LO":
+		r.lowerBounds[col] = value
+		r.lowerSet[col] = true
+	case "FX":
+		r.lowerBounds[col], r.upperBounds[col] = value, value
+		r.lowerSet[col] = true
+	case "FR":
+		r.lowerBounds[col], r.upperBounds[col] = math.Inf(-1), math.Inf(1)
+		r.lowerSet[col] = true
+	case "MI":
+		r.lowerBounds[col] = math.Inf(-1)
+		r.lowerSet[col] = true
+	case "PL":
+		r.upperBounds[col] = math.Inf(1)
+	case "BV", "LI", "UI", "SC":
+		return fields[0].Errorf("integer bounds aren't supported")
+	default:
+		return fields[0].Errorf("unknown bound type %q", fields[0].Text)
+	}
+	return nil
+}
+
+// problem - GeneralProblem of rows and columns read
+func (r *mpsReader) problem() (GeneralProblem, error) {
+	if len(r.rowNames) == 0 || len(r.colNames) == 0 {
+		return GeneralProblem{}, fmt.Errorf("%v: problem has no conditions or no variables", r.file)
+	}
+	rowNames, relations := r.rowNames, r.relations
+	rhs := make([]float64, len(rowNames))
+	rowOf := make([]int, len(rowNames)) // row of the file for every condition
+	for i := range rowNames {
+		rhs[i], rowOf[i] = r.rhs[i], i
+	}
+	for i, name := range r.rowNames {
+		width, ok := r.ranges[i]
+		if !ok {
+			continue
+		}
+		lower, upper := rhs[i], rhs[i]
+		switch {
+		case relations[i] == LessEqual:
+			lower -= math.Abs(width)
+		case relations[i] == GreaterEqual:
+			upper += math.Abs(width)
+		case width < 0:
+			lower += width
+		default:
+			upper += width
+		}
+		relations[i], rhs[i] = GreaterEqual, lower
+		rowNames = append(rowNames, name+"_range")
+		relations = append(relations, LessEqual)
+		rhs = append(rhs, upper)
+		rowOf = append(rowOf, i)
+	}
+
+	conditionsMatrix := mat.NewDense(len(rowNames), len(r.colNames), nil)
+	for i, row := range rowOf {
+		for j, column := range r.conditions {
+			conditionsMatrix.Set(i, j, column[row])
+		}
+	}
+	problem := NewGeneralProblem(r.sense, mat.NewVecDense(len(r.scales), r.scales), conditionsMatrix, relations, mat.NewVecDense(len(rhs), rhs))
+	problem.LowerBounds = mat.NewVecDense(len(r.lowerBounds), r.lowerBounds)
+	problem.UpperBounds = mat.NewVecDense(len(r.upperBounds), r.upperBounds)
+	problem.Name, problem.VarNames, problem.ConditionNames = r.name, r.colNames, rowNames
+	return problem, nil
+}
+
+// WriteMPS - writes problem to w in fixed or free MPS format. Names must have
+// no spaces and, in fixed format, fit 8 characters
+func WriteMPS(w io.Writer, problem GeneralProblem, free bool) error {
+	conditionsNumber, varNumber := problem.ConditionsMatrix.Dims()
+	writer := mpsWriter{w: bufio.NewWriter(w), free: free}
+
+	writer.header("NAME", problem.Name)
+	if problem.Sense == Maximize {
+		writer.header("OBJSENSE", "")
+		writer.line("", "MAX")
+	}
+
+	writer.header("ROWS", "")
+	objective := "obj"
+	writer.line("N", objective)
+	for i := 0; i < conditionsNumber; i++ {
+		writer.line([...]string{"L", "G", "E"}[problem.Relations[i]], problem.ConditionName(i))
+	}
+
+	writer.header("COLUMNS", "")
+	for j := 0; j < varNumber; j++ {
+		name := problem.VarName(j)
+		if c := problem.ScalesVector.AtVec(j); c != 0 {
+			writer.line("", name, objective, writer.number(c))
+		}
+		empty := problem.ScalesVector.AtVec(j) == 0
+		for i := 0; i < conditionsNumber; i++ {
+			if a := problem.ConditionsMatrix.At(i, j); a != 0 {
+				writer.line("", name, problem.ConditionName(i), writer.number(a))
+				empty = false
+			}
+		}
+		if empty {
+			// column must be mentioned to exist
+			writer.line("", name, objective, "0")
+		}
+	}
+
+	writer.header("RHS", "")
+	for i := 0; i < conditionsNumber; i++ {
+		if b := problem.FreeVector.AtVec(i); b != 0 {
+			writer.line("", "RHS", problem.ConditionName(i), writer.number(b))
+		}
+	}
+
+	writer.header("BOUNDS", "")
+	for j := 0; j < varNumber; j++ {
+		name := problem.VarName(j)
+		lower, upper := problem.LowerBounds.AtVec(j), problem.UpperBounds.AtVec(j)
+		switch {
+		case lower == upper:
+			writer.line("FX", "BND", name, writer.number(lower))
+			continue
+		case math.IsInf(lower, -1) && math.IsInf(upper, 1):
+			writer.line("FR", "BND", name)
+			continue
+		case math.IsInf(lower, -1):
+			writer.line("MI", "BND", name)
+		case lower != 0 || upper < 0:
+			writer.line("LO", "BND", name, writer.number(lower))
+		}
+		if !math.IsInf(upper, 1) {
+			writer.line("UP", "BND", name, writer.number(upper))
+		}
+	}
+	writer.header("ENDATA", "")
+
+	if writer.err != nil {
+		return writer.err
+	}
+	return writer.w.Flush()
+}
+
+// mpsWriter - writer of MPS lines, keeps the first error
+type mpsWriter struct {
+	w    *bufio.Writer
+	free bool
+	err  error
+}
+
+func (w *mpsWriter) header(section, value string) {
+	if value == "" {
+		fmt.Fprintln(w.w, section)
+	} else if w.free {
+		fmt.Fprintln(w.w, section, value)
+	} else {
+		fmt.Fprintf(w.w, "%-14v%v\n", section, value)
+	}
+}
+
+// line - data line of fields starting from the first one
+func (w *mpsWriter) line(fields ...string) {
+	var line strings.Builder
+	for i, field := range fields {
+		if field == "" {
+			continue
+		}
+		if strings.IndexFunc(field, unicode.IsSpace) != -1 || (!w.free && len(field) > mpsFields[i][1]-mpsFields[i][0]+1) {
+			if w.err == nil {
+				w.err = fmt.Errorf("%q doesn't fit MPS field", field)
+			}
+		}
+		if w.free {
+			line.WriteString(" " + field)
+		} else {
+			if pad := mpsFields[i][0] - 1 - line.Len(); pad > 0 {
+				line.WriteString(strings.Repeat(" ", pad))
+			}
+			line.WriteString(field)
+		}
+	}
+	fmt.Fprintln(w.w, line.String())
+}
+
+// number - value formatted to fit MPS field
+func (w *mpsWriter) number(value float64) string {
+	text := strconv.FormatFloat(value, 'g', -1, 64)
+	for precision := 12; !w.free && len(text) > 12; precision-- {
+		text = strconv.FormatFloat(value, 'g', precision, 64)
+	}
+	return text
+}
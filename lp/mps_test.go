@@ -0,0 +1,112 @@
+package lp
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// generalProblem - problem of rows of A and bounds, for tests
+func generalProblem(sense Sense, c []float64, rows [][]float64, relations []Relation, b, lower, upper []float64, varNames, conditionNames []string) GeneralProblem {
+	conditionsMatrix := mat.NewDense(len(rows), len(c), nil)
+	for i, row := range rows {
+		conditionsMatrix.SetRow(i, row)
+	}
+	problem := NewGeneralProblem(sense, mat.NewVecDense(len(c), c), conditionsMatrix, relations, mat.NewVecDense(len(b), b))
+	problem.LowerBounds, problem.UpperBounds = mat.NewVecDense(len(lower), lower), mat.NewVecDense(len(upper), upper)
+	problem.VarNames, problem.ConditionNames = varNames, conditionNames
+	return problem
+}
+
+// checkProblem - got is the same problem as want
+func checkProblem(t *testing.T, name string, got, want GeneralProblem) {
+	t.Helper()
+	switch {
+	case got.Sense != want.Sense || got.Name != want.Name:
+		t.Errorf("%v: sense %v, name %q, want %v, %q", name, got.Sense, got.Name, want.Sense, want.Name)
+	case !mat.Equal(got.ScalesVector, want.ScalesVector):
+		t.Errorf("%v: c = %v, want %v", name, mat.Formatted(got.ScalesVector.T()), mat.Formatted(want.ScalesVector.T()))
+	case !mat.Equal(got.ConditionsMatrix, want.ConditionsMatrix):
+		t.Errorf("%v: A =\n%v\nwant\n%v", name, mat.Formatted(got.ConditionsMatrix), mat.Formatted(want.ConditionsMatrix))
+	case !mat.Equal(got.FreeVector, want.FreeVector):
+		t.Errorf("%v: b = %v, want %v", name, mat.Formatted(got.FreeVector.T()), mat.Formatted(want.FreeVector.T()))
+	case !mat.Equal(got.LowerBounds, want.LowerBounds) || !mat.Equal(got.UpperBounds, want.UpperBounds):
+		t.Errorf("%v: bounds %v .. %v, want %v .. %v", name, mat.Formatted(got.LowerBounds.T()), mat.Formatted(got.UpperBounds.T()), mat.Formatted(want.LowerBounds.T()), mat.Formatted(want.UpperBounds.T()))
+	case strings.Join(got.VarNames, " ") != strings.Join(want.VarNames, " ") || strings.Join(got.ConditionNames, " ") != strings.Join(want.ConditionNames, " "):
+		t.Errorf("%v: names %v and %v, want %v and %v", name, got.VarNames, got.ConditionNames, want.VarNames, want.ConditionNames)
+	}
+	for i := range want.Relations {
+		if i >= len(got.Relations) || got.Relations[i] != want.Relations[i] {
+			t.Errorf("%v: relations %v, want %v", name, got.Relations, want.Relations)
+			break
+		}
+	}
+}
+
+func TestReadMPSBounds(t *testing.T) {
+	inf := math.Inf(1)
+	text := `* x1 is free, x2 and x3 have no lower bound, x5 has no upper one
+NAME          TEST
+OBJSENSE
+    MAX
+ROWS
+ N  obj
+ L  lim
+ G  low
+ E  eq
+COLUMNS
+    x1  obj  1  lim  1
+    x1  low  1
+    x2  obj  2  lim  1
+    x2  eq  1
+    x3  obj  -1  eq  1
+    x4  lim  1
+    x5  low  2
+RHS
+    RHS  lim  10  low  1
+    RHS  eq  3
+BOUNDS
+ FR BND x1
+ MI BND x2
+ UP BND x2 4
+ UP BND x3 -2
+ LO BND x4 -1
+ UP BND x4 -0.5
+ UP BND x5 7
+ PL BND x5
+ENDATA
+`
+	want := generalProblem(Maximize, []float64{1, 2, -1, 0, 0},
+		[][]float64{{1, 1, 0, 1, 0}, {1, 0, 0, 0, 2}, {0, 1, 1, 0, 0}},
+		[]Relation{LessEqual, GreaterEqual, Equal}, []float64{10, 1, 3},
+		[]float64{-inf, -inf, -inf, -1, 0}, []float64{inf, 4, -2, -0.5, inf},
+		[]string{"x1", "x2", "x3", "x4", "x5"}, []string{"lim", "low", "eq"})
+	want.Name = "TEST"
+	problem, err := ReadMPS(strings.NewReader(text), "test.mps", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkProblem(t, "free MPS", problem, want)
+}
+
+func TestReadMPSErrors(t *testing.T) {
+	rows := "ROWS\n N obj\n L lim\nCOLUMNS\n"
+	tests := []struct {
+		text string
+		want string
+	}{
+		{rows + " M1 'MARKER' 'INTORG'\n", "test.mps:5:5: integer columns aren't supported"},
+		{rows + " x1 lim 1\nBOUNDS\n BV BND x1\n", "test.mps:7:2: integer bounds aren't supported"},
+		{rows + " x1 lim 1\nBOUNDS\n UP BND x2 1\n", "test.mps:7:9: unknown column \"x2\""},
+		{rows + " x1 other 1\n", "test.mps:5:5: unknown row other"},
+		{rows + " x1 lim 1\nRHS\n RHS obj 5\n", "test.mps:7:6: objective constant isn't supported"},
+	}
+	for _, test := range tests {
+		_, err := ReadMPS(strings.NewReader(test.text), "test.mps", true)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("ReadMPS(%q) = %v, want %v", test.text, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package lp
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ParametricInterval - interval From <= t <= To of parametric problem with
+// one optimal basis. Objective is Constant + Slope*t over the interval, X is
+// the optimal plan for t = From. Interval past the last breakpoint where the
+// problem has no optimal plan has Unbounded or Infeasible Status and no Basis
+type ParametricInterval struct {
+	From, To  float64
+	Status    optim.Status
+	Basis     []int
+	Constant  float64
+	Slope     float64
+	X         *mat.VecDense
+	Iteration int
+}
+
+// ParametricObjective - solves (c + t*d)'x -> max, Ax = b, x >= 0 for every
+// t from tFrom to tTo, tTo may be infinite. Optimal basis of tFrom is found
+// with both phases of the simplex method, at every breakpoint the basis stops
+// being optimal and one pivot of the primal simplex method finds the next one.
+// Conditions must be linearly independent. Intervals found so far are
+// returned with error if the solver stops
+func ParametricObjective(ctx context.Context, options optim.Options, scalesVector, directionVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, tFrom, tTo float64) ([]ParametricInterval, error) {
+	limits := optim.NewLimits(ctx, options)
+	tolerances := limits.Tolerances()
+	start := mat.VecDenseCopyOf(scalesVector)
+	start.AddScaledVec(start, tFrom, directionVector)
+	result, err := startParametric(limits, start, conditionsMatrix, freeVector, tFrom, tTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var intervals []ParametricInterval
+	basis, t := result.Basis, tFrom
+	for iteration := result.Iterations; ; iteration++ {
+		a, err := analyse(scalesVector, conditionsMatrix, freeVector, optim.Result{Status: optim.Optimal, Basis: basis}, tolerances)
+		if err != nil {
+			return intervals, err
+		}
+		directionDeltas := a.deltas(directionVector, conditionsMatrix)
+
+		// deltas delta + t*directionDelta of nonbaseline columns stay
+		// nonnegative up to the breakpoint
+		breakpoint, entering := math.Inf(1), -1
+		for _, j := range a.nonbasic {
+			if slope := directionDeltas.AtVec(j); slope < -tolerances.DualFeasibility {
+				if value := -a.scoreVector.AtVec(j) / slope; value < breakpoint {
+					breakpoint, entering = math.Max(value, t), j
+				}
+			}
+		}
+		to := math.Min(breakpoint, tTo)
+		if to > t || tFrom == tTo {
+			x := a.plan(basis)
+			intervals = append(intervals, ParametricInterval{
+				From:      t,
+				To:        to,
+				Status:    optim.Optimal,
+				Basis:     append([]int{}, basis...),
+				Constant:  mat.Dot(scalesVector, x),
+				Slope:     mat.Dot(directionVector, x),
+				X:         x,
+				Iteration: iteration,
+			})
+			optim.Tracef("basis %v is optimal for %v <= t <= %v\n", basis, t, to)
+		}
+		if breakpoint >= tTo {
+			return intervals, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			return intervals, fmt.Errorf("%v at t = %v: %w", status, breakpoint, err)
+		}
+
+		// primal simplex pivot with entering column
+		leaving, minTheta := -1, math.Inf(1)
+		for k := range basis {
+			if z := a.tableau.At(k, entering); z > tolerances.Pivot {
+				if theta := a.baselineValues.AtVec(k) / z; theta < minTheta {
+					leaving, minTheta = k, theta
+				}
+			}
+		}
+		if leaving < 0 {
+			optim.Tracef("objective is unbounded for t > %v\n", breakpoint)
+			intervals = append(intervals, ParametricInterval{From: breakpoint, To: tTo, Status: optim.Unbounded, Iteration: iteration})
+			return intervals, nil
+		}
+		optim.Tracef("t = %v: column %v replaces column %v\n", breakpoint, entering+1, basis[leaving]+1)
+		basis = append([]int{}, basis...)
+		basis[leaving], t = entering, breakpoint
+	}
+}
+
+// ParametricFreeValues - solves c'x -> max, Ax = b + t*g, x >= 0 for every t
+// from tFrom to tTo, tTo may be infinite. Optimal basis of tFrom is found with
+// both phases of the simplex method, at every breakpoint the basis stops being
+// feasible and one pivot of the dual simplex method finds the next one.
+// Conditions must be linearly independent. Intervals found so far are returned
+// with error if the solver stops
+func ParametricFreeValues(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector, directionVector *mat.VecDense, tFrom, tTo float64) ([]ParametricInterval, error) {
+	limits := optim.NewLimits(ctx, options)
+	tolerances := limits.Tolerances()
+	start := mat.VecDenseCopyOf(freeVector)
+	start.AddScaledVec(start, tFrom, directionVector)
+	result, err := startParametric(limits, scalesVector, conditionsMatrix, start, tFrom, tTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var intervals []ParametricInterval
+	basis, t := result.Basis, tFrom
+	for iteration := result.Iterations; ; iteration++ {
+		a, err := analyse(scalesVector, conditionsMatrix, freeVector, optim.Result{Status: optim.Optimal, Basis: basis}, tolerances)
+		if err != nil {
+			return intervals, err
+		}
+		directionValues := mat.NewVecDense(len(basis), nil)
+		directionValues.MulVec(a.inversedBaselineMatrix, directionVector)
+
+		// baseline values x + t*directionValue stay nonnegative up to the
+		// breakpoint
+		breakpoint, leaving := math.Inf(1), -1
+		for k := range basis {
+			if slope := directionValues.AtVec(k); slope < -tolerances.PrimalFeasibility {
+				if value := -a.baselineValues.AtVec(k) / slope; value < breakpoint {
+					breakpoint, leaving = math.Max(value, t), k
+				}
+			}
+		}
+		to := math.Min(breakpoint, tTo)
+		if to > t || tFrom == tTo {
+			x := a.plan(basis)
+			for k, index := range basis {
+				x.SetVec(index, x.AtVec(index)+t*directionValues.AtVec(k))
+			}
+			intervals = append(intervals, ParametricInterval{
+				From:      t,
+				To:        to,
+				Status:    optim.Optimal,
+				Basis:     append([]int{}, basis...),
+				Constant:  mat.Dot(a.potentials, freeVector),
+				Slope:     mat.Dot(a.potentials, directionVector),
+				X:         x,
+				Iteration: iteration,
+			})
+			optim.Tracef("basis %v is optimal for %v <= t <= %v\n", basis, t, to)
+		}
+		if breakpoint >= tTo {
+			return intervals, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			return intervals, fmt.Errorf("%v at t = %v: %w", status, breakpoint, err)
+		}
+
+		// dual simplex pivot with leaving row
+		entering, minSigma := -1, math.Inf(1)
+		for _, j := range a.nonbasic {
+			if alpha := a.tableau.At(leaving, j); alpha < -tolerances.Pivot {
+				if sigma := math.Max(a.scoreVector.AtVec(j), 0) / -alpha; sigma < minSigma {
+					entering, minSigma = j, sigma
+				}
+			}
+		}
+		if entering < 0 {
+			optim.Tracef("conditions have no plan for t > %v\n", breakpoint)
+			intervals = append(intervals, ParametricInterval{From: breakpoint, To: tTo, Status: optim.Infeasible, Iteration: iteration})
+			return intervals, nil
+		}
+		optim.Tracef("t = %v: column %v replaces column %v\n", breakpoint, entering+1, basis[leaving]+1)
+		basis = append([]int{}, basis...)
+		basis[leaving], t = entering, breakpoint
+	}
+}
+
+// startParametric - optimal basis of the problem for t = tFrom
+func startParametric(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, tFrom, tTo float64) (optim.Result, error) {
+	if math.IsInf(tFrom, 0) || math.IsNaN(tFrom) || tTo < tFrom {
+		return optim.Result{}, fmt.Errorf("t should go from finite value up, got %v to %v", tFrom, tTo)
+	}
+	result, err := solveCanonical(limits, scalesVector, conditionsMatrix, freeVector)
+	if err != nil {
+		return result, fmt.Errorf("t = %v: %w", tFrom, err)
+	}
+	return result, nil
+}
+
+// deltas - u'A - scalesVector for potentials u of scalesVector
+func (a analysis) deltas(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense) *mat.VecDense {
+	components := mat.NewVecDense(len(a.position), nil)
+	for index, k := range a.position {
+		components.SetVec(k, scalesVector.AtVec(index))
+	}
+	potentials := mat.NewVecDense(len(a.position), nil)
+	potentials.MulVec(a.inversedBaselineMatrix.T(), components)
+	deltas := mat.NewVecDense(scalesVector.Len(), nil)
+	deltas.MulVec(conditionsMatrix.T(), potentials)
+	deltas.SubVec(deltas, scalesVector)
+	return deltas
+}
+
+// plan - baseline plan of basis
+func (a analysis) plan(basis []int) *mat.VecDense {
+	x := mat.NewVecDense(a.scoreVector.Len(), nil)
+	for k, index := range basis {
+		x.SetVec(index, a.baselineValues.AtVec(k))
+	}
+	return x
+}
+
+// WriteBreakpoints - writes intervals as csv table with columns from, to,
+// status, objective constant and slope and basis with indexes starting from 1
+func WriteBreakpoints(w io.Writer, intervals []ParametricInterval) error {
+	table := csv.NewWriter(w)
+	table.Write([]string{"from", "to", "status", "constant", "slope", "basis"})
+	for _, interval := range intervals {
+		basis := make([]string, len(interval.Basis))
+		for i, index := range interval.Basis {
+			basis[i] = strconv.Itoa(index + 1)
+		}
+		table.Write([]string{
+			formatFloat(interval.From),
+			formatFloat(interval.To),
+			interval.Status.String(),
+			formatFloat(interval.Constant),
+			formatFloat(interval.Slope),
+			strings.Join(basis, " "),
+		})
+	}
+	table.Flush()
+	return table.Error()
+}
+
+// formatFloat - shortest representation of value, inf and -inf for infinite
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
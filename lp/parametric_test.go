@@ -0,0 +1,94 @@
+package lp
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// x1 + x2 + x3 = 4, x1 + x4 = 3 for ParametricObjective, x1 + x4 = 1 for
+// ParametricFreeValues, x3 and x4 are slacks
+var parametricConditions = mat.NewDense(2, 4, []float64{
+	1, 1, 1, 0,
+	1, 0, 0, 1,
+})
+
+func TestParametricObjective(t *testing.T) {
+	// (1 + t)x1 + 2x2 -> max: x2 = 4 is optimal up to t = 1, where x1
+	// becomes as profitable as x2, then x1 = 3, x2 = 1
+	intervals, err := ParametricObjective(context.Background(), optim.Options{},
+		mat.NewVecDense(4, []float64{1, 2, 0, 0}), mat.NewVecDense(4, []float64{1, 0, 0, 0}),
+		parametricConditions, mat.NewVecDense(2, []float64{4, 3}), 0, math.Inf(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ParametricInterval{
+		{From: 0, To: 1, Status: optim.Optimal, Basis: []int{1, 3}, Constant: 8, Slope: 0, X: mat.NewVecDense(4, []float64{0, 4, 0, 3})},
+		{From: 1, To: math.Inf(1), Status: optim.Optimal, Basis: []int{1, 0}, Constant: 5, Slope: 3, X: mat.NewVecDense(4, []float64{3, 1, 0, 0})},
+	}
+	checkIntervals(t, intervals, want)
+
+	var table bytes.Buffer
+	if err := WriteBreakpoints(&table, intervals); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := "from,to,status,constant,slope,basis\n" +
+		"0,1,optimal,8,0,2 4\n" +
+		"1,inf,optimal,5,3,2 1\n"
+	if table.String() != wantTable {
+		t.Errorf("breakpoints table\n%v\nwant\n%v", table.String(), wantTable)
+	}
+}
+
+func TestParametricFreeValues(t *testing.T) {
+	// 2x1 + x2 -> max with b = (4 - t, 1): x2 = 3 - t leaves the basis at
+	// t = 3, then x1 = 4 - t leaves no plan past t = 4
+	intervals, err := ParametricFreeValues(context.Background(), optim.Options{},
+		mat.NewVecDense(4, []float64{2, 1, 0, 0}), parametricConditions,
+		mat.NewVecDense(2, []float64{4, 1}), mat.NewVecDense(2, []float64{-1, 0}), 0, math.Inf(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ParametricInterval{
+		{From: 0, To: 3, Status: optim.Optimal, Basis: []int{1, 0}, Constant: 5, Slope: -1, X: mat.NewVecDense(4, []float64{1, 3, 0, 0})},
+		{From: 3, To: 4, Status: optim.Optimal, Basis: []int{3, 0}, Constant: 8, Slope: -2, X: mat.NewVecDense(4, []float64{1, 0, 0, 0})},
+		{From: 4, To: math.Inf(1), Status: optim.Infeasible},
+	}
+	checkIntervals(t, intervals, want)
+
+	var table bytes.Buffer
+	if err := WriteBreakpoints(&table, intervals); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := "from,to,status,constant,slope,basis\n" +
+		"0,3,optimal,5,-1,2 1\n" +
+		"3,4,optimal,8,-2,4 1\n" +
+		"4,inf,infeasible,0,0,\n"
+	if table.String() != wantTable {
+		t.Errorf("breakpoints table\n%v\nwant\n%v", table.String(), wantTable)
+	}
+}
+
+func checkIntervals(t *testing.T, intervals, want []ParametricInterval) {
+	t.Helper()
+	if len(intervals) != len(want) {
+		t.Fatalf("%v intervals, want %v", len(intervals), len(want))
+	}
+	for i, interval := range intervals {
+		w := want[i]
+		switch {
+		case interval.From != w.From || interval.To != w.To || interval.Status != w.Status:
+			t.Errorf("interval %v: %v <= t <= %v is %v, want %v <= t <= %v is %v", i+1, interval.From, interval.To, interval.Status, w.From, w.To, w.Status)
+		case !equalInts(interval.Basis, w.Basis):
+			t.Errorf("interval %v: basis %v, want %v", i+1, interval.Basis, w.Basis)
+		case math.Abs(interval.Constant-w.Constant) > 1e-9 || math.Abs(interval.Slope-w.Slope) > 1e-9:
+			t.Errorf("interval %v: objective %v + %v*t, want %v + %v*t", i+1, interval.Constant, interval.Slope, w.Constant, w.Slope)
+		case w.X != nil && !mat.EqualApprox(interval.X, w.X, 1e-9):
+			t.Errorf("interval %v: x = %v, want %v", i+1, mat.Formatted(interval.X.T()), mat.Formatted(w.X.T()))
+		}
+	}
+}
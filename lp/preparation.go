@@ -0,0 +1,168 @@
+package lp
+
+import (
+	"context"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SimplexPreparationPhase - returns baseline plan with its baseline indexes for
+// conditions Ax = b, x >= 0. Linearly dependent conditions are eliminated, so
+// Result.Basis may be shorter than b. Returns optim.ErrInfeasible if
+// conditions have no feasible plan
+func SimplexPreparationPhase(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (optim.Result, error) {
+	return SimplexPreparationPhaseContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector)
+}
+
+// SimplexPreparationPhaseContext - SimplexPreparationPhase with limits of
+// the main phase solving artificial problem, see SimplexMainPhaseContext
+func SimplexPreparationPhaseContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (optim.Result, error) {
+	limits := optim.NewLimits(ctx, options)
+	result, _, _, err := simplexPreparationPhase(limits, scalesVector, mat.DenseCopyOf(conditionsMatrix), mat.VecDenseCopyOf(freeVector))
+	_, varNumber := conditionsMatrix.Dims()
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	return withInfeasibility(result, err, limits.Tolerances(), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+}
+
+// simplexPreparationPhase - returns Result of preparation phase together with
+// conditions left after elimination of linearly dependent ones
+func simplexPreparationPhase(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (optim.Result, *mat.Dense, *mat.VecDense, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+
+	// row[i]*=-1 of conditional matrix where b[i] < 0
+	for i := 0; i < conditionsNumber; i++ {
+		if freeVector.AtVec(i) < 0 {
+			freeVector.SetVec(i, freeVector.AtVec(i)*-1)
+			condition := mat.NewVecDense(varNumber, nil)
+			condition.ScaleVec(-1, conditionsMatrix.RowView(i))
+			conditionsMatrix.SetRow(i, linalg.RawVector(condition))
+		}
+	}
+
+	// creating artificial (искусственных) scalesVector, conditions matrix,
+	// baselineVector, baselineIndexes for main simplex phase
+	artificialLength := varNumber + conditionsNumber
+	artificialScalesVector := mat.NewVecDense(artificialLength, nil)
+	artificialBaselineIndexes := make([]int, conditionsNumber)
+	artificialBaselineVector := mat.NewVecDense(artificialLength, nil) // all elements are zeros
+	for i := varNumber; i < artificialLength; i++ {
+		artificialScalesVector.SetVec(i, -1)
+		artificialBaselineIndexes[i-varNumber] = i
+		artificialBaselineVector.SetVec(i, freeVector.AtVec(i-varNumber)) // set b[i] for artificial values
+	}
+	artificialConditionsMatrix := mat.NewDense(conditionsNumber, artificialLength, nil)
+	for i := 0; i < conditionsNumber; i++ {
+		for j := 0; j < varNumber; j++ {
+			artificialConditionsMatrix.Set(i, j, conditionsMatrix.At(i, j))
+		}
+		artificialConditionsMatrix.Set(i, varNumber+i, 1)
+	}
+
+	optim.Tracef("Artificial problem, scales vector:\n")
+	optim.TraceMatrix(artificialScalesVector)
+	optim.Tracef("Artificial conditions matrix:\n")
+	optim.TraceMatrix(artificialConditionsMatrix)
+	artificialResult, err := simplexMainPhase(limits, artificialScalesVector, artificialConditionsMatrix, artificialBaselineVector, linalg.FloatVector(artificialBaselineIndexes))
+	if err != nil {
+		return artificialResult, conditionsMatrix, freeVector, err
+	}
+	artificialBaselineVector, artificialBaselineIndexes = artificialResult.X, artificialResult.Basis
+	optim.Tracef("Solved artificial problem\n")
+	optim.TraceMatrix(artificialBaselineVector)
+	optim.Tracef("%v\n", artificialBaselineIndexes)
+
+	result := optim.Result{
+		X:          mat.VecDenseCopyOf(artificialBaselineVector.SliceVec(0, varNumber)),
+		Basis:      artificialBaselineIndexes,
+		Iterations: artificialResult.Iterations,
+		Degeneracy: optim.Degeneracy{Pivots: artificialResult.Degeneracy.Pivots},
+	}
+	result.Objective = mat.Dot(scalesVector, result.X)
+	for _, index := range artificialResult.Degeneracy.ZeroBasic {
+		if index < varNumber {
+			result.Degeneracy.ZeroBasic = append(result.Degeneracy.ZeroBasic, index)
+		}
+	}
+
+	// Any artificial value left positive means there's no feasible plan,
+	// degenerate artificial values may be left a little above 0
+	for i := varNumber; i < artificialLength; i++ {
+		if artificialBaselineVector.AtVec(i) > limits.Tolerances().PrimalFeasibility {
+			result.Status = optim.Infeasible
+			return result, conditionsMatrix, freeVector, optim.ErrInfeasible
+		}
+	}
+
+	var nonBaselineOwnIndexes []int
+	for i := 0; i < varNumber; i++ {
+		if !linalg.FindInt(artificialBaselineIndexes, i) {
+			nonBaselineOwnIndexes = append(nonBaselineOwnIndexes, i)
+		}
+	}
+
+	// factorization of artificial baseline matrix is updated after own
+	// indexes replace artificial ones
+	artificialBaselineFactor, err := newBasisFactor(limits, linalg.Columns(artificialConditionsMatrix, artificialBaselineIndexes))
+	if err != nil {
+		result.Status = optim.SingularBasis
+		return result, conditionsMatrix, freeVector, optim.ErrSingularBasis
+	}
+	// findings l[i] where i - nonbaseline own index. l[k] is row k of inversed
+	// artificial baseline matrix times column i. If l[k] != 0 own index
+	// replaces artificial one k in basis, otherwise condition of k is a linear
+	// combination of conditions without artificial baseline indexes and is
+	// eliminated. Replacements keep rows of eliminated ones, so they're all
+	// found in one pass
+	var eliminatedConditions []int
+	for k, index := range artificialBaselineIndexes {
+		if index < varNumber {
+			continue
+		}
+		replaced := false
+		inversedRow := linalg.InverseRow(artificialBaselineFactor, k)
+		for i, ownIndex := range nonBaselineOwnIndexes {
+			if math.Abs(mat.Dot(inversedRow, artificialConditionsMatrix.ColView(ownIndex))) > limits.Tolerances().Pivot {
+				if err := artificialBaselineFactor.Replace(k, artificialConditionsMatrix.ColView(ownIndex)); err != nil {
+					result.Status = optim.SingularBasis
+					return result, conditionsMatrix, freeVector, err
+				}
+				artificialBaselineIndexes[k] = ownIndex
+				nonBaselineOwnIndexes = append(nonBaselineOwnIndexes[:i], nonBaselineOwnIndexes[i+1:]...)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			optim.Tracef("Condition %v is linearly dependent, eliminating it\n", index-varNumber+1)
+			eliminatedConditions = append(eliminatedConditions, index-varNumber)
+		}
+	}
+	result.Status = optim.Optimal
+	if eliminatedConditions == nil {
+		optim.Tracef("There's no index to eliminate. Slicing baselineVector\n")
+		return result, conditionsMatrix, freeVector, nil
+	}
+
+	// elimination, artificial indexes of eliminated conditions leave basis
+	// together with them
+	leftNumber := conditionsNumber - len(eliminatedConditions)
+	newFreeVector := mat.NewVecDense(leftNumber, nil)
+	newConditionsMatrix := mat.NewDense(leftNumber, varNumber, nil)
+	for i, j := 0, 0; i < conditionsNumber; i++ {
+		if !linalg.FindInt(eliminatedConditions, i) {
+			newConditionsMatrix.SetRow(j, conditionsMatrix.RawRowView(i))
+			newFreeVector.SetVec(j, freeVector.AtVec(i))
+			j++
+		}
+	}
+	result.Basis = nil
+	for _, index := range artificialBaselineIndexes {
+		if index < varNumber {
+			result.Basis = append(result.Basis, index)
+		}
+	}
+	return result, newConditionsMatrix, newFreeVector, nil
+}
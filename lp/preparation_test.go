@@ -0,0 +1,47 @@
+package lp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSimplexPreparationPhaseRedundantConditions(t *testing.T) {
+	// condition 3 is the sum of conditions 1 and 2, condition 4 is twice
+	// condition 1, condition 5 repeats condition 1
+	scalesVector := mat.NewVecDense(3, []float64{1, 2, 1})
+	conditionsMatrix := mat.NewDense(5, 3, []float64{
+		1, 1, 1,
+		1, -1, 0,
+		2, 0, 1,
+		2, 2, 2,
+		1, 1, 1,
+	})
+	freeVector := mat.NewVecDense(5, []float64{4, 0, 4, 8, 4})
+	for _, factorization := range []optim.Factorization{optim.LU, optim.ProductForm} {
+		limits := optim.NewLimits(context.Background(), optim.Options{Factorization: factorization})
+		result, leftMatrix, leftVector, err := simplexPreparationPhase(limits, scalesVector, mat.DenseCopyOf(conditionsMatrix), mat.VecDenseCopyOf(freeVector))
+		if err != nil {
+			t.Fatalf("%v: %v", factorization, err)
+		}
+		if rows, _ := leftMatrix.Dims(); result.Status != optim.Optimal || rows != 2 || leftVector.Len() != 2 || len(result.Basis) != 2 {
+			t.Fatalf("%v: %v with basis %v and %v conditions left, want optimal with 2 of them", factorization, result.Status, result.Basis, rows)
+		}
+		for _, index := range result.Basis {
+			if index >= 3 {
+				t.Errorf("%v: artificial index %v in basis %v", factorization, index+1, result.Basis)
+			}
+		}
+		var product mat.VecDense
+		product.MulVec(conditionsMatrix, result.X)
+		if !mat.EqualApprox(&product, freeVector, 1e-9) || mat.Min(result.X) < 0 {
+			t.Errorf("%v: x = %v isn't a plan of conditions", factorization, mat.Formatted(result.X.T()))
+		}
+		if _, err := newBasisFactor(limits, linalg.Columns(leftMatrix, result.Basis)); err != nil {
+			t.Errorf("%v: basis %v of conditions left: %v", factorization, result.Basis, err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package lp
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// PricingRuleNames - names of pricing rules known to NewPricingRule
+var PricingRuleNames = []string{"bland", "dantzig", "partial", "devex", "steepest-edge"}
+
+// NewPricingRule - pricing rule by its name, see PricingRuleNames
+func NewPricingRule(name string) (optim.PricingRule, error) {
+	switch name {
+	case "bland":
+		return Bland{}, nil
+	case "dantzig":
+		return Dantzig{}, nil
+	case "partial":
+		return &Partial{}, nil
+	case "devex":
+		return &Devex{}, nil
+	case "steepest-edge":
+		return SteepestEdge{}, nil
+	}
+	return nil, fmt.Errorf("unknown pricing rule %q, expected one of %v", name, PricingRuleNames)
+}
+
+// pricingRule - pricing rule of limits, Bland by default
+func pricingRule(limits optim.Limits) optim.PricingRule {
+	if rule := limits.Pricing(); rule != nil {
+		return rule
+	}
+	return Bland{}
+}
+
+// Bland - the lowest index of column with negative score. Never cycles, but
+// often makes many iterations. Here and in other rules scores above
+// -state.Tolerance are left by rounding errors and aren't negative
+type Bland struct{}
+
+// Start - nothing to start
+func (Bland) Start(varNumber int) {}
+
+// Entering - the first nonbaseline column with negative score
+func (Bland) Entering(state optim.PricingState) int {
+	for _, j := range state.Nonbasic {
+		if state.Scores.AtVec(j) < -state.Tolerance {
+			return j
+		}
+	}
+	return -1
+}
+
+// Update - nothing to update
+func (Bland) Update(state optim.PricingState, entering, leaving int, column *mat.VecDense) {}
+
+// Dantzig - column with the most negative score
+type Dantzig struct{}
+
+// Start - nothing to start
+func (Dantzig) Start(varNumber int) {}
+
+// Entering - nonbaseline column with the most negative score
+func (Dantzig) Entering(state optim.PricingState) int {
+	return mostNegative(state, state.Nonbasic)
+}
+
+// Update - nothing to update
+func (Dantzig) Update(state optim.PricingState, entering, leaving int, column *mat.VecDense) {}
+
+// Partial - Dantzig rule over a window of Size nonbaseline columns. Windows
+// are scanned in turn starting from the one after the window of the previous
+// entering column, the first window with negative scores is taken. Zero Size
+// means a quarter of columns
+type Partial struct {
+	Size int
+	next int
+}
+
+// Start - starts scanning from the first window
+func (p *Partial) Start(varNumber int) {
+	p.next = 0
+}
+
+// Entering - the most negative score of the first window having one
+func (p *Partial) Entering(state optim.PricingState) int {
+	size := p.Size
+	if size <= 0 {
+		size = (len(state.Nonbasic) + 3) / 4
+	}
+	for scanned := 0; scanned < len(state.Nonbasic); scanned += size {
+		window := make([]int, 0, size)
+		for k := 0; k < size && scanned+k < len(state.Nonbasic); k++ {
+			window = append(window, state.Nonbasic[(p.next+k)%len(state.Nonbasic)])
+		}
+		p.next = (p.next + len(window)) % len(state.Nonbasic)
+		if j := mostNegative(state, window); j >= 0 {
+			return j
+		}
+	}
+	return -1
+}
+
+// Update - nothing to update
+func (p *Partial) Update(state optim.PricingState, entering, leaving int, column *mat.VecDense) {}
+
+// Devex - column with the greatest score[j]^2/weights[j], weights
+// approximate norms of edge directions relative to the columns of the first
+// basis
+type Devex struct {
+	weights []float64
+}
+
+// Start - reference framework of the first basis, every weight is 1
+func (d *Devex) Start(varNumber int) {
+	d.weights = make([]float64, varNumber)
+	for j := range d.weights {
+		d.weights[j] = 1
+	}
+}
+
+// Entering - nonbaseline column with the greatest weighted score
+func (d *Devex) Entering(state optim.PricingState) int {
+	return greatestWeighted(state, d.weights)
+}
+
+// Update - weights of the basis after pivot
+func (d *Devex) Update(state optim.PricingState, entering, leaving int, column *mat.VecDense) {
+	// pivot row of inversed baseline matrix times conditions
+	pivotRow := mat.NewVecDense(len(d.weights), nil)
+	pivotRow.MulVec(state.Conditions.T(), linalg.InverseRow(state.Factor, leaving))
+	pivot := column.AtVec(leaving)
+	enteringWeight := d.weights[entering]
+	for _, j := range state.Nonbasic {
+		if j == entering {
+			continue
+		}
+		ratio := pivotRow.AtVec(j) / pivot
+		d.weights[j] = math.Max(d.weights[j], ratio*ratio*enteringWeight)
+	}
+	d.weights[state.Basis[leaving]] = math.Max(enteringWeight/(pivot*pivot), 1)
+}
+
+// SteepestEdge - column with the greatest score[j]^2/(1+|A_B^-1 A[j]|^2), the
+// steepest edge of objective. Norms are found anew on every iteration
+type SteepestEdge struct{}
+
+// Start - nothing to start
+func (SteepestEdge) Start(varNumber int) {}
+
+// Entering - nonbaseline column with the greatest score over its edge norm
+func (SteepestEdge) Entering(state optim.PricingState) int {
+	_, varNumber := state.Conditions.Dims()
+	weights := make([]float64, varNumber)
+	for _, j := range state.Nonbasic {
+		if state.Scores.AtVec(j) < -state.Tolerance {
+			edge := state.Factor.Solve(state.Conditions.ColView(j))
+			weights[j] = 1 + mat.Dot(edge, edge)
+		}
+	}
+	return greatestWeighted(state, weights)
+}
+
+// Update - nothing to update
+func (SteepestEdge) Update(state optim.PricingState, entering, leaving int, column *mat.VecDense) {}
+
+// mostNegative - column of columns with the most negative score, -1 if there's
+// no score below -state.Tolerance
+func mostNegative(state optim.PricingState, columns []int) int {
+	best, bestScore := -1, -state.Tolerance
+	for _, j := range columns {
+		if score := state.Scores.AtVec(j); score < bestScore {
+			best, bestScore = j, score
+		}
+	}
+	return best
+}
+
+// greatestWeighted - nonbaseline column with negative score and the greatest
+// score[j]^2/weights[j], -1 if there's no negative score
+func greatestWeighted(state optim.PricingState, weights []float64) int {
+	best, bestValue := -1, 0.0
+	for _, j := range state.Nonbasic {
+		score := state.Scores.AtVec(j)
+		if score >= -state.Tolerance {
+			continue
+		}
+		if value := score * score / weights[j]; best < 0 || value > bestValue {
+			best, bestValue = j, value
+		}
+	}
+	return best
+}
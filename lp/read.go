@@ -0,0 +1,509 @@
+package lp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Problem - optimization problem in canonical form c'x -> max, Ax = b, x >= 0.
+// BaselineVector and BaselineIndexes are set if the problem file has them,
+// LowerBounds and UpperBounds replace x >= 0 in problems of the bounded
+// simplex method. Exact is set by parsers of problems the exact methods solve
+type Problem struct {
+	ScalesVector     *mat.VecDense
+	ConditionsMatrix *mat.Dense
+	FreeVector       *mat.VecDense
+	BaselineVector   *mat.VecDense
+	BaselineIndexes  []int
+	LowerBounds      *mat.VecDense
+	UpperBounds      *mat.VecDense
+	Exact            *ExactProblem
+}
+
+// ExactProblem - values of Problem as they're written in the problem file:
+// 1/3 and 0.1 are exact fractions here, not float64 values rounded from them
+type ExactProblem struct {
+	ScalesVector     []*big.Rat
+	ConditionsMatrix *linalg.Dense[*big.Rat]
+	FreeVector       []*big.Rat
+	BaselineVector   []*big.Rat
+}
+
+// canonicalDims - varNumber and conditionsNumber of problem in canonical form:
+// c, rows of A, b and then tail more rows
+func canonicalDims(block parse.Block, tail int) (int, int, error) {
+	varNumber := len(block.Rows[0])
+	// b is the first row shorter or longer than c before the tail rows, b of
+	// the same length means as many conditions as variables
+	conditionsNumber := varNumber
+	for i := 1; i < len(block.Rows)-tail; i++ {
+		if len(block.Rows[i]) != varNumber {
+			conditionsNumber = i - 1
+			break
+		}
+	}
+	if conditionsNumber < 1 || len(block.Rows) != conditionsNumber+2+tail {
+		return 0, 0, block.Errorf(0, "can't infer sizes: expected c, rows of A, b and %v more rows, got %v rows", tail, len(block.Rows))
+	}
+	return varNumber, conditionsNumber, nil
+}
+
+// parseExactProblem - fractions of rows c, A, b and, if baselineVector is set,
+// baseline vector of block
+func parseExactProblem(block parse.Block, varNumber, conditionsNumber int, baselineVector bool) (*ExactProblem, error) {
+	var (
+		exact ExactProblem
+		err   error
+	)
+	if exact.ScalesVector, err = block.Rats(0, varNumber); err != nil {
+		return nil, err
+	}
+	conditions, err := block.RatMatrix(1, conditionsNumber, varNumber)
+	if err != nil {
+		return nil, err
+	}
+	exact.ConditionsMatrix = linalg.RatDenseOf(conditions)
+	if exact.FreeVector, err = block.Rats(conditionsNumber+1, conditionsNumber); err != nil {
+		return nil, err
+	}
+	if baselineVector {
+		if exact.BaselineVector, err = block.Rats(conditionsNumber+2, varNumber); err != nil {
+			return nil, err
+		}
+	}
+	return &exact, nil
+}
+
+// ParseOptimizationProblem - problem of block with rows c, A, b and, unless
+// preparationPhase is set, baseline vector. Baseline indexes are determined by
+// nonzero values of baseline vector
+func ParseOptimizationProblem(block parse.Block, preparationPhase bool) (Problem, error) {
+	var (
+		problem Problem
+		err     error
+	)
+	tail := 1
+	if preparationPhase {
+		tail = 0
+	}
+	varNumber, conditionsNumber, err := canonicalDims(block, tail)
+	if err != nil {
+		return problem, err
+	}
+
+	//scalesVector
+	if problem.ScalesVector, err = block.Vector(0, varNumber); err != nil {
+		return problem, err
+	}
+
+	//conditionsMatrix
+	if problem.ConditionsMatrix, err = block.Matrix(1, conditionsNumber, varNumber); err != nil {
+		return problem, err
+	}
+
+	//freeMembersVector
+	if problem.FreeVector, err = block.Vector(conditionsNumber+1, conditionsNumber); err != nil {
+		return problem, err
+	}
+	if problem.Exact, err = parseExactProblem(block, varNumber, conditionsNumber, !preparationPhase); err != nil {
+		return problem, err
+	}
+	if preparationPhase {
+		return problem, nil
+	}
+
+	//baselineVector
+	if problem.BaselineVector, err = block.Vector(conditionsNumber+2, varNumber); err != nil {
+		return problem, err
+	}
+
+	//determining baseline indexes
+	for i := 0; i < varNumber; i++ {
+		if problem.BaselineVector.AtVec(i) != 0 {
+			problem.BaselineIndexes = append(problem.BaselineIndexes, i)
+		}
+	}
+	return problem, nil
+}
+
+// ParseDoubleOptimizationProblem - problem of block with rows c, A, b and dual
+// feasible baseline indexes (numeration starts from 1 in file). Baseline
+// indexes row may be omitted, BaselineIndexes are nil then. Block is read
+// without the row if it isn't a problem with it
+func ParseDoubleOptimizationProblem(block parse.Block) (Problem, error) {
+	problem, err := parseDoubleOptimizationProblem(block, true)
+	if err == nil {
+		return problem, nil
+	}
+	withoutIndexes, withoutErr := parseDoubleOptimizationProblem(block, false)
+	// errors of rows are reported for the shape sizes are inferred for
+	if _, _, dimsErr := canonicalDims(block, 1); withoutErr == nil || dimsErr != nil {
+		return withoutIndexes, withoutErr
+	}
+	return problem, err
+}
+
+// parseDoubleOptimizationProblem - ParseDoubleOptimizationProblem of block
+// with or without baseline indexes row
+func parseDoubleOptimizationProblem(block parse.Block, baselineIndexes bool) (Problem, error) {
+	var problem Problem
+	tail := 0
+	if baselineIndexes {
+		tail = 1
+	}
+	varNumber, conditionsNumber, err := canonicalDims(block, tail)
+	if err != nil {
+		return problem, err
+	}
+	if problem.ScalesVector, err = block.Vector(0, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.ConditionsMatrix, err = block.Matrix(1, conditionsNumber, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.FreeVector, err = block.Vector(conditionsNumber+1, conditionsNumber); err != nil {
+		return problem, err
+	}
+	if problem.Exact, err = parseExactProblem(block, varNumber, conditionsNumber, false); err != nil {
+		return problem, err
+	}
+	if !baselineIndexes {
+		return problem, nil
+	}
+	if problem.BaselineIndexes, err = block.Indexes(conditionsNumber+2, conditionsNumber); err != nil {
+		return problem, err
+	}
+	return problem, nil
+}
+
+// ParseGeneralProblem - problem of block with rows: c followed by max or min,
+// rows of A each followed by <=, >= or =, b and then bound rows of variables
+// without x >= 0 bound. Bound rows are "j >= value", "j <= value", "j = value"
+// or "j free" where j is variable index (numeration starts from 1) and value
+// may be -inf or inf. Upper bound below zero makes variable without lower
+// bound row unbounded from below. Bound rows "0 >=" ... "4 >=" of the problem
+// sketched in 5/input.txt number variables from 0 and have no values, so the
+// sketch isn't read in this format
+func ParseGeneralProblem(block parse.Block) (GeneralProblem, error) {
+	var problem GeneralProblem
+
+	// c and sense
+	varNumber := len(block.Rows[0]) - 1
+	sense := block.Rows[0][varNumber]
+	switch sense.Text {
+	case "max":
+		problem.Sense = Maximize
+	case "min":
+		problem.Sense = Minimize
+	default:
+		return problem, sense.Errorf("expected max or min, got %q", sense.Text)
+	}
+	if varNumber < 1 {
+		return problem, sense.Errorf("expected c before %v", sense.Text)
+	}
+	scales, err := numbers(block.Rows[0][:varNumber])
+	if err != nil {
+		return problem, err
+	}
+
+	// A with relations
+	var conditions []float64
+	for row := 1; row < len(block.Rows) && len(block.Rows[row]) == varNumber+1; row++ {
+		tokens := block.Rows[row]
+		relation, ok := parseRelation(tokens[varNumber].Text)
+		if !ok {
+			break
+		}
+		condition, err := numbers(tokens[:varNumber])
+		if err != nil {
+			return problem, err
+		}
+		conditions = append(conditions, condition...)
+		problem.Relations = append(problem.Relations, relation)
+	}
+	conditionsNumber := len(problem.Relations)
+	if conditionsNumber == 0 {
+		return problem, block.Errorf(1, "expected %v numbers followed by <=, >= or =", varNumber)
+	}
+
+	// b
+	freeVector, err := block.Vector(conditionsNumber+1, conditionsNumber)
+	if err != nil {
+		return problem, err
+	}
+	problem = NewGeneralProblem(problem.Sense, mat.NewVecDense(varNumber, scales), mat.NewDense(conditionsNumber, varNumber, conditions), problem.Relations, freeVector)
+
+	// bounds
+	lowerSet := make([]bool, varNumber)
+	for row := conditionsNumber + 2; row < len(block.Rows); row++ {
+		tokens := block.Rows[row]
+		if len(tokens) < 2 || len(tokens) > 3 {
+			return problem, block.Errorf(row, "expected bound row \"j >= value\", \"j <= value\", \"j = value\" or \"j free\"")
+		}
+		indexes, err := numbers(tokens[:1])
+		if err != nil {
+			return problem, err
+		}
+		index := int(indexes[0]) - 1
+		if float64(index+1) != indexes[0] || index < 0 || index >= varNumber {
+			return problem, tokens[0].Errorf("%v is not an index of %v variables", indexes[0], varNumber)
+		}
+		if tokens[1].Text == "free" {
+			if len(tokens) != 2 {
+				return problem, tokens[2].Errorf("unexpected value after free")
+			}
+			problem.LowerBounds.SetVec(index, math.Inf(-1))
+			problem.UpperBounds.SetVec(index, math.Inf(1))
+			lowerSet[index] = true
+			continue
+		}
+		relation, ok := parseRelation(tokens[1].Text)
+		if !ok {
+			return problem, tokens[1].Errorf("expected <=, >=, = or free, got %q", tokens[1].Text)
+		}
+		if len(tokens) != 3 {
+			return problem, block.Errorf(row, "expected value after %v in bound row of variable %v", tokens[1].Text, index+1)
+		}
+		value, err := tokens[2].Number()
+		if err != nil {
+			return problem, err
+		}
+		switch relation {
+		case GreaterEqual:
+			problem.LowerBounds.SetVec(index, value)
+			lowerSet[index] = true
+		case LessEqual:
+			problem.UpperBounds.SetVec(index, value)
+			if value < 0 && !lowerSet[index] {
+				problem.LowerBounds.SetVec(index, math.Inf(-1))
+			}
+		case Equal:
+			problem.LowerBounds.SetVec(index, value)
+			problem.UpperBounds.SetVec(index, value)
+			lowerSet[index] = true
+		}
+	}
+	return problem, nil
+}
+
+// ReadGeneralProblems - every problem of input, see ParseGeneralProblem
+func ReadGeneralProblems(input string) ([]GeneralProblem, error) {
+	blocks, err := parse.ReadBlocks(input)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]GeneralProblem, len(blocks))
+	for i, block := range blocks {
+		if problems[i], err = ParseGeneralProblem(block); err != nil {
+			return nil, fmt.Errorf("%v: problem %v: %w", input, i+1, err)
+		}
+	}
+	return problems, nil
+}
+
+// parseRelation - relation of <=, >= or = text
+func parseRelation(text string) (Relation, bool) {
+	switch text {
+	case "<=":
+		return LessEqual, true
+	case ">=":
+		return GreaterEqual, true
+	case "=":
+		return Equal, true
+	}
+	return 0, false
+}
+
+// numbers - values of tokens
+func numbers(tokens []parse.Token) ([]float64, error) {
+	values := make([]float64, len(tokens))
+	for i, token := range tokens {
+		value, err := token.Number()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// ReadOptimizationProblems - every problem of input, see ParseOptimizationProblem
+func ReadOptimizationProblems(input string, preparationPhase bool) ([]Problem, error) {
+	return readProblems(input, func(block parse.Block) (Problem, error) {
+		return ParseOptimizationProblem(block, preparationPhase)
+	})
+}
+
+// ReadDoubleOptimizationProblems - every problem of input, see
+// ParseDoubleOptimizationProblem
+func ReadDoubleOptimizationProblems(input string) ([]Problem, error) {
+	return readProblems(input, ParseDoubleOptimizationProblem)
+}
+
+func readProblems(input string, parseProblem func(parse.Block) (Problem, error)) ([]Problem, error) {
+	blocks, err := parse.ReadBlocks(input)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]Problem, len(blocks))
+	for i, block := range blocks {
+		if problems[i], err = parseProblem(block); err != nil {
+			return nil, fmt.Errorf("%v: problem %v: %w", input, i+1, err)
+		}
+	}
+	return problems, nil
+}
+
+// ParseParametricProblem - problem of block with rows c, A, b and direction
+// of parameter t: d of objective (c + t*d)'x or, if freeValues is set, g of
+// free values b + t*g
+func ParseParametricProblem(block parse.Block, freeValues bool) (Problem, *mat.VecDense, error) {
+	var problem Problem
+	varNumber, conditionsNumber, err := canonicalDims(block, 1)
+	if err != nil {
+		return problem, nil, err
+	}
+	if problem.ScalesVector, err = block.Vector(0, varNumber); err != nil {
+		return problem, nil, err
+	}
+	if problem.ConditionsMatrix, err = block.Matrix(1, conditionsNumber, varNumber); err != nil {
+		return problem, nil, err
+	}
+	if problem.FreeVector, err = block.Vector(conditionsNumber+1, conditionsNumber); err != nil {
+		return problem, nil, err
+	}
+	size := varNumber
+	if freeValues {
+		size = conditionsNumber
+	}
+	directionVector, err := block.Vector(conditionsNumber+2, size)
+	if err != nil {
+		return problem, nil, err
+	}
+	return problem, directionVector, nil
+}
+
+// ParseIntegerProblem - problem of block with rows c, A, b and indexes of
+// integer variables (numeration starts from 1 in file)
+func ParseIntegerProblem(block parse.Block) (Problem, []int, error) {
+	var problem Problem
+	varNumber, conditionsNumber, err := canonicalDims(block, 1)
+	if err != nil {
+		return problem, nil, err
+	}
+	if problem.ScalesVector, err = block.Vector(0, varNumber); err != nil {
+		return problem, nil, err
+	}
+	if problem.ConditionsMatrix, err = block.Matrix(1, conditionsNumber, varNumber); err != nil {
+		return problem, nil, err
+	}
+	if problem.FreeVector, err = block.Vector(conditionsNumber+1, conditionsNumber); err != nil {
+		return problem, nil, err
+	}
+	row := conditionsNumber + 2
+	integerIndexes, err := block.Indexes(row, len(block.Rows[row]))
+	if err != nil {
+		return problem, nil, err
+	}
+	for i, index := range integerIndexes {
+		if index >= varNumber {
+			return problem, nil, block.Rows[row][i].Errorf("there's no variable %v", index+1)
+		}
+	}
+	return problem, integerIndexes, nil
+}
+
+// ParseBoundedProblem - problem of block with rows c, lower and upper bounds
+// of x, A, b. Bounds may be -inf and inf
+func ParseBoundedProblem(block parse.Block) (Problem, error) {
+	var (
+		problem Problem
+		err     error
+	)
+	if len(block.Rows) < 3 {
+		return problem, block.Errorf(len(block.Rows), "expected c, lower and upper bounds, rows of A and b")
+	}
+	// sizes are inferred from rows starting with upper bounds, they're as long
+	// as c
+	tail := block
+	tail.Rows = block.Rows[2:]
+	varNumber, conditionsNumber, err := canonicalDims(tail, 0)
+	if err != nil {
+		return problem, err
+	}
+	if problem.ScalesVector, err = block.Vector(0, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.LowerBounds, err = block.Vector(1, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.UpperBounds, err = block.Vector(2, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.ConditionsMatrix, err = block.Matrix(3, conditionsNumber, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.FreeVector, err = block.Vector(conditionsNumber+3, conditionsNumber); err != nil {
+		return problem, err
+	}
+	return problem, nil
+}
+
+// ParseBoundedDoubleProblem - problem of block with rows c, A, b, lower and
+// upper bounds of x and dual feasible baseline indexes (numeration starts
+// from 1 in file). Bounds may be -inf and inf. Baseline indexes row may be
+// omitted, BaselineIndexes are nil then. Block is read without the row if it
+// isn't a problem with it
+func ParseBoundedDoubleProblem(block parse.Block) (Problem, error) {
+	problem, err := parseBoundedDoubleProblem(block, true)
+	if err == nil {
+		return problem, nil
+	}
+	withoutIndexes, withoutErr := parseBoundedDoubleProblem(block, false)
+	// errors of rows are reported for the shape sizes are inferred for
+	if _, _, dimsErr := canonicalDims(block, 3); withoutErr == nil || dimsErr != nil {
+		return withoutIndexes, withoutErr
+	}
+	return problem, err
+}
+
+// parseBoundedDoubleProblem - ParseBoundedDoubleProblem of block with or
+// without baseline indexes row
+func parseBoundedDoubleProblem(block parse.Block, baselineIndexes bool) (Problem, error) {
+	var problem Problem
+	tail := 2
+	if baselineIndexes {
+		tail = 3
+	}
+	varNumber, conditionsNumber, err := canonicalDims(block, tail)
+	if err != nil {
+		return problem, err
+	}
+	if problem.ScalesVector, err = block.Vector(0, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.ConditionsMatrix, err = block.Matrix(1, conditionsNumber, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.FreeVector, err = block.Vector(conditionsNumber+1, conditionsNumber); err != nil {
+		return problem, err
+	}
+	if problem.LowerBounds, err = block.Vector(conditionsNumber+2, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.UpperBounds, err = block.Vector(conditionsNumber+3, varNumber); err != nil {
+		return problem, err
+	}
+	if !baselineIndexes {
+		return problem, nil
+	}
+	if problem.BaselineIndexes, err = block.Indexes(conditionsNumber+4, conditionsNumber); err != nil {
+		return problem, err
+	}
+	return problem, nil
+}
@@ -0,0 +1,84 @@
+package lp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseGeneralProblem(t *testing.T) {
+	inf := math.Inf(1)
+	problem, err := ParseGeneralProblem(parseBlock("1 2 3 max\n1 1 1 <=\n1 -1 0 =\n4 0\n1 >= -2\n2 <= 5\n3 free\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := generalProblem(Maximize, []float64{1, 2, 3},
+		[][]float64{{1, 1, 1}, {1, -1, 0}},
+		[]Relation{LessEqual, Equal}, []float64{4, 0},
+		[]float64{-2, 0, -inf}, []float64{inf, 5, inf}, nil, nil)
+	checkProblem(t, "bound rows", problem, want)
+}
+
+func TestParseGeneralProblemErrors(t *testing.T) {
+	problem := "1 2 max\n1 1 <=\n4\n"
+	tests := []struct {
+		text string
+		want string
+	}{
+		{problem + "1 >=\n", "input.txt:4:1: expected value after >= in bound row of variable 1"},
+		{problem + "2 <=\n", "input.txt:4:1: expected value after <= in bound row of variable 2"},
+		{problem + "1 >= 0\n2 =\n", "input.txt:5:1: expected value after = in bound row of variable 2"},
+		{problem + "0 >= 1\n", "input.txt:4:1: 0 is not an index of 2 variables"},
+		{problem + "1 free 2\n", "input.txt:4:8: unexpected value after free"},
+	}
+	for _, test := range tests {
+		_, err := ParseGeneralProblem(parseBlock(test.text))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("ParseGeneralProblem(%q) = %v, want %v", test.text, err, test.want)
+		}
+	}
+}
+
+func TestParseDoubleOptimizationProblemShapes(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		conditions int
+		basis      []int
+	}{
+		{"as many conditions as variables", "-1 -1\n1 2\n2 1\n4 4\n", 2, nil},
+		{"as many conditions as variables with basis", "-1 -1\n1 2\n2 1\n4 4\n1 2\n", 2, []int{0, 1}},
+		{"fewer conditions", "-1 -1 0\n1 2 1\n4\n", 1, nil},
+		{"fewer conditions with basis", "-1 -1 0\n1 2 1\n4\n3\n", 1, []int{2}},
+		{"one variable less than conditions", "-1 -1\n1 2\n2 1\n1 1\n4 4 3\n", 3, nil},
+	}
+	for _, test := range tests {
+		problem, err := ParseDoubleOptimizationProblem(parseBlock(test.text))
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if rows, _ := problem.ConditionsMatrix.Dims(); rows != test.conditions || !equalInts(problem.BaselineIndexes, test.basis) {
+			t.Errorf("%v: %v conditions with basis %v, want %v with %v", test.name, rows, problem.BaselineIndexes, test.conditions, test.basis)
+		}
+	}
+
+	// as many conditions as variables, lower and upper bounds
+	problem, err := ParseBoundedDoubleProblem(parseBlock("-1 -1\n1 2\n2 1\n4 4\n0 0\n3 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows, _ := problem.ConditionsMatrix.Dims(); rows != 2 || problem.BaselineIndexes != nil || problem.UpperBounds.AtVec(0) != 3 {
+		t.Errorf("bounded problem: %v conditions with basis %v and upper bounds %v", rows, problem.BaselineIndexes, problem.UpperBounds.RawVector().Data)
+	}
+}
+
+func TestParseIntegerProblemShapes(t *testing.T) {
+	// as many conditions as variables with fewer integer indexes
+	problem, integerIndexes, err := ParseIntegerProblem(parseBlock("1 1\n1 2\n2 1\n4 4\n1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows, _ := problem.ConditionsMatrix.Dims(); rows != 2 || !equalInts(integerIndexes, []int{0}) {
+		t.Errorf("%v conditions with integer indexes %v, want 2 with [0]", rows, integerIndexes)
+	}
+}
@@ -0,0 +1,222 @@
+package lp
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Range - interval Lower <= value <= Upper, bounds may be infinite
+type Range struct {
+	Lower, Upper float64
+}
+
+// Sensitivity - ranges over which optimal basis stays optimal: Costs[j] is
+// the range of c[j] keeping it optimal, FreeValues[i] is the range of b[i]
+// keeping it feasible. Over FreeValues[i] the optimal objective changes by
+// Duals[i] per unit of b[i]
+type Sensitivity struct {
+	Costs      []Range
+	FreeValues []Range
+	Duals      *mat.VecDense
+}
+
+// SensitivityAnalysis - ranges of c and b of canonical problem c'x -> max,
+// Ax = b, x >= 0 for optimal result of SimplexMainPhase. Inversed baseline
+// matrix of result is used if it's set. Every range is of one value, while the
+// rest of them are left as they are
+func SensitivityAnalysis(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, result optim.Result) (Sensitivity, error) {
+	return SensitivityAnalysisContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector, result)
+}
+
+// SensitivityAnalysisContext - SensitivityAnalysis with values of tableau up
+// to options.Tolerances.Zero taken as zeros. Returns optim.ErrCanceled if ctx
+// is done
+func SensitivityAnalysisContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, result optim.Result) (Sensitivity, error) {
+	limits := optim.NewLimits(ctx, options)
+	if _, err := limits.Check(0); err != nil {
+		return Sensitivity{}, err
+	}
+	a, err := analyse(scalesVector, conditionsMatrix, freeVector, result, limits.Tolerances())
+	if err != nil {
+		return Sensitivity{}, err
+	}
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	sensitivity := Sensitivity{
+		Costs:      make([]Range, varNumber),
+		FreeValues: make([]Range, conditionsNumber),
+		Duals:      a.potentials,
+	}
+	for j := range sensitivity.Costs {
+		sensitivity.Costs[j] = a.costRange(scalesVector, j, -1)
+	}
+	for i := range sensitivity.FreeValues {
+		sensitivity.FreeValues[i] = a.freeRange(freeVector, i)
+	}
+	return sensitivity, nil
+}
+
+// Sensitivity - ranges of c and b of the original problem for optimal Result
+// of Solve, see SensitivityAnalysis. Ranges of b are found for the canonical
+// problem, so they don't take upper bounds of variables into account as
+// conditions of their own
+func (c CanonicalProblem) Sensitivity(result optim.Result) (Sensitivity, error) {
+	return c.SensitivityContext(context.Background(), optim.Options{}, result)
+}
+
+// SensitivityContext - Sensitivity with tolerances of options, see
+// SensitivityAnalysisContext
+func (c CanonicalProblem) SensitivityContext(ctx context.Context, options optim.Options, result optim.Result) (Sensitivity, error) {
+	limits := optim.NewLimits(ctx, options)
+	if _, err := limits.Check(0); err != nil {
+		return Sensitivity{}, err
+	}
+	a, err := analyse(c.ScalesVector, c.ConditionsMatrix, c.FreeVector, optim.Result{Status: result.Status, Basis: result.Basis}, limits.Tolerances())
+	if err != nil {
+		return Sensitivity{}, err
+	}
+	sense := 1.
+	if c.problem.Sense == Minimize {
+		sense = -1
+	}
+
+	sensitivity := Sensitivity{Costs: make([]Range, len(c.variables))}
+	for i, variable := range c.variables {
+		// c[i] is sense*sign times cost of its canonical column, -sense times
+		// cost of negative column of free variable. Columns of free variable
+		// change together, so baseline one is found without the other
+		if variable.negativeColumn == -1 {
+			sensitivity.Costs[i] = a.costRange(c.ScalesVector, variable.column, -1).scaled(sense * variable.sign)
+			continue
+		}
+		costs := a.costRange(c.ScalesVector, variable.column, variable.negativeColumn).scaled(sense)
+		negativeCosts := a.costRange(c.ScalesVector, variable.negativeColumn, variable.column).scaled(-sense)
+		_, isBaseline := a.position[variable.column]
+		_, isNegativeBaseline := a.position[variable.negativeColumn]
+		switch {
+		case isNegativeBaseline:
+			costs = negativeCosts
+		case !isBaseline:
+			costs = Range{Lower: math.Max(costs.Lower, negativeCosts.Lower), Upper: math.Min(costs.Upper, negativeCosts.Upper)}
+		}
+		sensitivity.Costs[i] = costs
+	}
+
+	// b[i] of the original problem is shifted by offsets of variables
+	conditionsNumber, _ := c.problem.ConditionsMatrix.Dims()
+	sensitivity.FreeValues = make([]Range, conditionsNumber)
+	for i := range sensitivity.FreeValues {
+		shift := c.problem.FreeVector.AtVec(i) - c.FreeVector.AtVec(i)
+		freeValues := a.freeRange(c.FreeVector, i)
+		sensitivity.FreeValues[i] = Range{Lower: freeValues.Lower + shift, Upper: freeValues.Upper + shift}
+	}
+	if sensitivity.Duals, err = c.duals(result, limits.Tolerances()); err != nil {
+		return Sensitivity{}, err
+	}
+	return sensitivity, nil
+}
+
+// analysis - optimal basis with its inversed matrix, baseline values,
+// potentials, deltas and rows of A_B^-1 A. Values up to zero in absolute
+// value are zeros
+type analysis struct {
+	position               map[int]int // position of baseline column in basis
+	nonbasic               []int
+	inversedBaselineMatrix *mat.Dense
+	baselineValues         *mat.VecDense
+	potentials             *mat.VecDense
+	scoreVector            *mat.VecDense
+	tableau                *mat.Dense
+	zero                   float64
+}
+
+func analyse(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, result optim.Result, tolerances optim.Tolerances) (analysis, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	if result.Status != optim.Optimal {
+		return analysis{}, fmt.Errorf("result is %v, sensitivity is found for optimal one", result.Status)
+	}
+	if len(result.Basis) != conditionsNumber {
+		return analysis{}, fmt.Errorf("basis has %v indexes for %v conditions, conditions are linearly dependent", len(result.Basis), conditionsNumber)
+	}
+	a := analysis{
+		position:               make(map[int]int, conditionsNumber),
+		nonbasic:               nonbasic(varNumber, result.Basis),
+		inversedBaselineMatrix: result.InverseBasis,
+		zero:                   tolerances.Zero,
+	}
+	for k, index := range result.Basis {
+		a.position[index] = k
+	}
+	if a.inversedBaselineMatrix == nil {
+		a.inversedBaselineMatrix = mat.NewDense(conditionsNumber, conditionsNumber, nil)
+		if err := a.inversedBaselineMatrix.Inverse(linalg.Columns(conditionsMatrix, result.Basis)); err != nil {
+			return analysis{}, optim.ErrSingularBasis
+		}
+	}
+
+	a.baselineValues = mat.NewVecDense(conditionsNumber, nil)
+	a.baselineValues.MulVec(a.inversedBaselineMatrix, freeVector)
+	components := mat.NewVecDense(conditionsNumber, nil)
+	for i, index := range result.Basis {
+		components.SetVec(i, scalesVector.AtVec(index))
+	}
+	a.potentials = linalg.VecMulMat(components, a.inversedBaselineMatrix)
+	a.scoreVector = linalg.VecMulMat(a.potentials, conditionsMatrix)
+	a.scoreVector.AddScaledVec(a.scoreVector, -1, scalesVector)
+	a.tableau = mat.NewDense(conditionsNumber, varNumber, nil)
+	a.tableau.Mul(a.inversedBaselineMatrix, conditionsMatrix)
+	return a, nil
+}
+
+// costRange - range of c[j] keeping the basis optimal. c[j] of nonbaseline
+// column lowers delta[j] when it grows, of baseline column in row k changes
+// delta[l] by A_B^-1 A[k][l] per unit. Column paired is left out, it's the
+// other column of free variable, whose delta stays 0
+func (a analysis) costRange(scalesVector *mat.VecDense, j, paired int) Range {
+	k, isBaseline := a.position[j]
+	if !isBaseline {
+		return Range{Lower: math.Inf(-1), Upper: scalesVector.AtVec(j) + math.Max(a.scoreVector.AtVec(j), 0)}
+	}
+	lower, upper := math.Inf(-1), math.Inf(1)
+	for _, l := range a.nonbasic {
+		if l == paired {
+			continue
+		}
+		alpha, delta := a.tableau.At(k, l), math.Max(a.scoreVector.AtVec(l), 0)
+		switch {
+		case alpha > a.zero:
+			lower = math.Max(lower, -delta/alpha)
+		case alpha < -a.zero:
+			upper = math.Min(upper, -delta/alpha)
+		}
+	}
+	return Range{Lower: scalesVector.AtVec(j) + lower, Upper: scalesVector.AtVec(j) + upper}
+}
+
+// freeRange - range of b[i] keeping the basis feasible, b[i] changes baseline
+// values by column i of A_B^-1 per unit
+func (a analysis) freeRange(freeVector *mat.VecDense, i int) Range {
+	lower, upper := math.Inf(-1), math.Inf(1)
+	for k := 0; k < a.baselineValues.Len(); k++ {
+		beta, value := a.inversedBaselineMatrix.At(k, i), math.Max(a.baselineValues.AtVec(k), 0)
+		switch {
+		case beta > a.zero:
+			lower = math.Max(lower, -value/beta)
+		case beta < -a.zero:
+			upper = math.Min(upper, -value/beta)
+		}
+	}
+	return Range{Lower: freeVector.AtVec(i) + lower, Upper: freeVector.AtVec(i) + upper}
+}
+
+// scaled - range of factor*value
+func (r Range) scaled(factor float64) Range {
+	if factor < 0 {
+		return Range{Lower: factor * r.Upper, Upper: factor * r.Lower}
+	}
+	return Range{Lower: factor * r.Lower, Upper: factor * r.Upper}
+}
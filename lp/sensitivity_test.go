@@ -0,0 +1,49 @@
+package lp
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSensitivityOfLab4(t *testing.T) {
+	problem, err := ReadMPSFile("../4/input.mps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical := problem.Canonical()
+	result, err := canonical.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sensitivity, err := canonical.Sensitivity(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 4x1 + 3x2 + 7x3 -> min at x = (0.25, 0.5, 0) with duals (1, 1), x3
+	// stays nonbaseline however its cost grows
+	costs := []Range{{3, 13. / 3}, {2.5, 4}, {6, math.Inf(1)}}
+	freeValues := []Range{{0.75, 1.5}, {1, 2}}
+	for j, want := range costs {
+		if got := sensitivity.Costs[j]; !equalRanges(got, want) {
+			t.Errorf("cost range of x%v = %v, want %v", j+1, got, want)
+		}
+	}
+	for i, want := range freeValues {
+		if got := sensitivity.FreeValues[i]; !equalRanges(got, want) {
+			t.Errorf("free value range of c%v = %v, want %v", i+1, got, want)
+		}
+	}
+	if want := mat.NewVecDense(2, []float64{1, 1}); !mat.EqualApprox(sensitivity.Duals, want, 1e-9) {
+		t.Errorf("duals %v, want %v", mat.Formatted(sensitivity.Duals.T()), mat.Formatted(want.T()))
+	}
+}
+
+func equalRanges(a, b Range) bool {
+	equal := func(x, y float64) bool {
+		return x == y || math.Abs(x-y) <= 1e-9
+	}
+	return equal(a.Lower, b.Lower) && equal(a.Upper, b.Upper)
+}
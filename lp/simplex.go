@@ -0,0 +1,345 @@
+package lp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SimplexMainPhase - solves optimization problem in canonical form starting
+// from baseline plan baselineVector with baselineIndexes. Returns
+// optim.ErrUnbounded, optim.ErrSingularBasis, optim.ErrIterationLimit or
+// optim.ErrDualityGap with a non optimal Result. Optimal Result holds dual
+// solution of the plan
+func SimplexMainPhase(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, baselineVector *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	return SimplexMainPhaseContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, baselineVector, baselineIndexes)
+}
+
+// SimplexMainPhaseContext - SimplexMainPhase stopped with optim.ErrCanceled
+// when ctx is done or options.TimeLimit passes and with
+// optim.ErrIterationLimit after options.MaxIterations pivots. Result of stopped
+// run holds the last baseline plan, the best one found. Entering columns are
+// chosen by options.Pricing, Bland by default
+func SimplexMainPhaseContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, baselineVector *mat.VecDense, baselineIndexes []int) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	limits := optim.NewLimits(ctx, options)
+	result, err := simplexMainPhase(limits, scalesVector, conditionsMatrix, mat.VecDenseCopyOf(baselineVector), linalg.FloatVector(baselineIndexes))
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	freeVector := mat.NewVecDense(conditionsNumber, nil)
+	freeVector.MulVec(conditionsMatrix, baselineVector)
+	return withInfeasibility(result, err, limits.Tolerances(), scalesVector, conditionsMatrix, freeVector, lowerBounds, upperBounds)
+}
+
+// simplexMainPhase - main phase with perturbed baseline values if limits ask
+// for optim.Perturbation. Plan of perturbed run is found anew for the
+// conditions Ax = b of the starting plan, if it has negative values the
+// problem is solved again from the starting plan with optim.Lexicographic
+// rule. Optimal Result gets slacks and dual
+// objective for the same b, optim.ErrDualityGap is returned if dual objective
+// isn't equal to the primal one
+func simplexMainPhase(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, baselineVector, baselineIndexes *mat.VecDense) (optim.Result, error) {
+	conditionsNumber, _ := conditionsMatrix.Dims()
+	freeVector := mat.NewVecDense(conditionsNumber, nil)
+	freeVector.MulVec(conditionsMatrix, baselineVector)
+
+	var result optim.Result
+	var err error
+	if limits.AntiCycling() != optim.Perturbation {
+		result, err = simplexIterations(limits, scalesVector, conditionsMatrix, baselineVector, baselineIndexes)
+	} else {
+		result, err = simplexIterations(limits, scalesVector, conditionsMatrix, perturbed(baselineVector, baselineIndexes), baselineIndexes)
+		var feasible bool
+		result, feasible = unperturbed(result, scalesVector, conditionsMatrix, freeVector, limits.Tolerances())
+		if !feasible {
+			optim.Tracef("plan of perturbed basis %v has negative values, solving with lexicographic rule\n", result.Basis)
+			iterations := result.Iterations
+			result, err = simplexIterations(limits.WithAntiCycling(optim.Lexicographic), scalesVector, conditionsMatrix, baselineVector, baselineIndexes)
+			result.Iterations += iterations
+		}
+	}
+	if err != nil || result.Duals == nil {
+		return result, err
+	}
+
+	result.Slacks = mat.NewVecDense(conditionsNumber, nil)
+	result.Slacks.MulVec(conditionsMatrix, result.X)
+	result.Slacks.SubVec(freeVector, result.Slacks)
+	result.DualObjective = mat.Dot(result.Duals, freeVector)
+	if gap := math.Abs(result.Objective - result.DualObjective); gap > limits.Tolerances().DualityGap*(1+math.Abs(result.Objective)) {
+		optim.Tracef("primal objective %v and dual objective %v differ by %v\n", result.Objective, result.DualObjective, gap)
+		result.Status = optim.NotSolved
+		return result, fmt.Errorf("%w by %v", optim.ErrDualityGap, gap)
+	}
+	return result, nil
+}
+
+func simplexIterations(limits optim.Limits, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, baselineVector, baselineIndexes *mat.VecDense) (optim.Result, error) {
+	conditionsNumber, varNumber := conditionsMatrix.Dims()
+	tolerances := limits.Tolerances()
+	var baselineFactor linalg.BasisFactor
+	pricing := pricingRule(limits)
+	pricing.Start(varNumber)
+	replacedIndex := 0
+	degeneratePivots, zeroBasic := 0, make([]bool, varNumber)
+	// every plan should solve A x = b of the first one, the greatest
+	// violation of it and of x >= 0 is reported
+	freeVector := mat.NewVecDense(conditionsNumber, nil)
+	freeVector.MulVec(conditionsMatrix, baselineVector)
+	lowerBounds, upperBounds := nonnegativeBounds(varNumber)
+	observedInfeasibility := 0.0
+	for iteration := 0; ; iteration++ {
+		observedInfeasibility = math.Max(observedInfeasibility, primalInfeasibility(conditionsMatrix, freeVector, lowerBounds, upperBounds, baselineVector))
+		for i := 0; i < conditionsNumber; i++ {
+			if index := int(baselineIndexes.AtVec(i)); math.Abs(baselineVector.AtVec(index)) <= tolerances.Zero {
+				zeroBasic[index] = true
+			}
+		}
+		result := optim.Result{
+			Objective:           mat.Dot(scalesVector, baselineVector),
+			X:                   baselineVector,
+			Basis:               linalg.IntVector(baselineIndexes),
+			Iterations:          iteration,
+			Degeneracy:          degeneracy(degeneratePivots, zeroBasic),
+			PrimalInfeasibility: observedInfeasibility,
+		}
+
+		if iteration == 0 {
+			// First iteration. Factorizing baselineMatrix of baselineIndexes
+			// of conditionsMatrix
+			factor, err := newBasisFactor(limits, linalg.Columns(conditionsMatrix, result.Basis))
+			if err != nil {
+				result.Status = optim.SingularBasis
+				return result, optim.ErrSingularBasis
+			}
+			baselineFactor = factor
+		} else {
+			// Other operations. Updating factorization with the replaced column
+			if err := baselineFactor.Replace(replacedIndex, conditionsMatrix.ColView(int(baselineIndexes.AtVec(replacedIndex)))); err != nil {
+				result.Status = optim.SingularBasis
+				return result, err
+			}
+		}
+
+		// finding components of scalesVector
+		components := mat.NewVecDense(conditionsNumber, nil)
+		for i := 0; i < conditionsNumber; i++ {
+			components.SetVec(i, scalesVector.AtVec(int(baselineIndexes.AtVec(i))))
+		}
+
+		// potentials vector solves potentials * baselineMatrix = components
+		potentials := baselineFactor.SolveTranspose(components)
+		scoreVector := linalg.VecMulMat(potentials, conditionsMatrix)
+		scoreVector.AddScaledVec(scoreVector, -1, scalesVector)
+
+		// First exit condition, if pricing rule finds no column with negative
+		// score, current case is optimal
+		state := optim.PricingState{
+			Scores:     scoreVector,
+			Tolerance:  tolerances.DualFeasibility,
+			Basis:      result.Basis,
+			Nonbasic:   nonbasic(varNumber, result.Basis),
+			Factor:     baselineFactor,
+			Conditions: conditionsMatrix,
+		}
+		lowestIndex := pricing.Entering(state)
+		if lowestIndex < 0 {
+			// THIS IS OPTIMAL CASE
+			optim.Tracef("every deltas element of \n")
+			optim.TraceMatrix(scoreVector)
+			optim.Tracef("> 0, baseline vector is optimal case \n")
+			result.Status = optim.Optimal
+			result.Duals, result.ReducedCosts, result.InverseBasis = potentials, scoreVector, linalg.InverseOf(baselineFactor)
+			return result, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+		optim.Tracef("scoreVector[%v] of delta\n", lowestIndex+1)
+		optim.TraceMatrix(scoreVector)
+		optim.Tracef("%v < 0\n", scoreVector.AtVec(lowestIndex))
+
+		// Nonbaseline index chosen by pricing rule for vector z
+		var zVector = baselineFactor.Solve(conditionsMatrix.ColView(lowestIndex))
+
+		// Theta
+		minTheta, minThetaIndex, thetaValue := math.Inf(+1), 0, 0.0
+		for j := 0; j < conditionsNumber; j++ {
+			z := zVector.AtVec(j)
+			// entries of z close to 0 are left by rounding errors, pivot on
+			// them would make baseline matrix almost singular
+			if z > tolerances.Pivot {
+				thetaValue = baselineVector.AtVec(int(baselineIndexes.AtVec(j))) / z
+			} else {
+				thetaValue = math.Inf(+1)
+			}
+			if thetaValue < minTheta {
+				minTheta = thetaValue
+				minThetaIndex = j
+			}
+		}
+		if math.IsInf(minTheta, 1) {
+			result.Status = optim.Unbounded
+			return result, optim.ErrUnbounded
+		}
+		if limits.AntiCycling() == optim.Lexicographic {
+			minThetaIndex = lexicographicRow(baselineFactor, zVector, baselineVector, baselineIndexes, minTheta, tolerances)
+		}
+		if minTheta <= tolerances.Zero {
+			degeneratePivots++
+			optim.Tracef("theta is 0, pivot is degenerate\n")
+		}
+
+		pricing.Update(state, lowestIndex, minThetaIndex, zVector)
+
+		// changing baseline indexes
+		newBaselineIndexes := mat.VecDenseCopyOf(baselineIndexes)
+		newBaselineIndexes.SetVec(minThetaIndex, float64(lowestIndex))
+
+		// creating new baselineVector (new baseline case)
+		newBaselineVector := mat.NewVecDense(varNumber, nil)
+		// minThetaIndex value equals to theta, others following the formula
+		for i := 0; i < conditionsNumber; i++ {
+			newValue := 0.0
+			if i != minThetaIndex {
+				newValue = baselineVector.AtVec(int(baselineIndexes.AtVec(i))) - minTheta*zVector.AtVec(i)
+			} else {
+				newValue = minTheta
+			}
+			newBaselineVector.SetVec(int(newBaselineIndexes.AtVec(i)), newValue)
+		}
+
+		optim.Tracef("New baseline vector on %v iteration is\n", iteration+1)
+		optim.TraceMatrix(newBaselineVector)
+
+		// Next iteration with new baseline vector and new baseline indexes
+		baselineVector, baselineIndexes, replacedIndex = newBaselineVector, newBaselineIndexes, minThetaIndex
+	}
+}
+
+// newBasisFactor - factorization of baselineMatrix chosen by limits with
+// their pivot and primal feasibility tolerances
+func newBasisFactor(limits optim.Limits, baselineMatrix *mat.Dense) (linalg.BasisFactor, error) {
+	tolerances := linalg.FactorTolerances{Pivot: limits.Tolerances().Pivot, Residual: limits.Tolerances().PrimalFeasibility}
+	if limits.Factorization() == optim.ProductForm {
+		factor, err := linalg.NewEtaFile(baselineMatrix, limits.RefactorEvery(), tolerances)
+		if err != nil {
+			return nil, err
+		}
+		return factor, nil
+	}
+	factor, err := linalg.NewLUFactor(baselineMatrix, limits.RefactorEvery(), tolerances)
+	if err != nil {
+		return nil, err
+	}
+	return factor, nil
+}
+
+// nonbasic - indexes of columns out of basis in increasing order
+func nonbasic(varNumber int, basis []int) []int {
+	isBaseline := make([]bool, varNumber)
+	for _, j := range basis {
+		isBaseline[j] = true
+	}
+	indexes := make([]int, 0, varNumber-len(basis))
+	for j := 0; j < varNumber; j++ {
+		if !isBaseline[j] {
+			indexes = append(indexes, j)
+		}
+	}
+	return indexes
+}
+
+// perturbationScale - relative size of perturbations of baseline values
+const perturbationScale = 1e-7
+
+// lexicographicRow - row of the least ratio minTheta, ties are broken by
+// lexicographically least row of inversed baseline matrix divided by zVector,
+// values within tolerances.Zero tie. Rows of inversed matrix are linearly
+// independent, so only one row is left. Rows of tied ratios are found by
+// SolveTranspose of baselineFactor
+func lexicographicRow(baselineFactor linalg.BasisFactor, zVector, baselineVector, baselineIndexes *mat.VecDense, minTheta float64, tolerances optim.Tolerances) int {
+	var rows []int
+	for j := 0; j < zVector.Len(); j++ {
+		if z := zVector.AtVec(j); z > tolerances.Pivot && baselineVector.AtVec(int(baselineIndexes.AtVec(j)))/z-minTheta <= tolerances.Zero*(1+math.Abs(minTheta)) {
+			rows = append(rows, j)
+		}
+	}
+	inversedRows := map[int]*mat.VecDense{}
+	if len(rows) > 1 {
+		for _, j := range rows {
+			inversedRows[j] = linalg.InverseRow(baselineFactor, j)
+		}
+	}
+	for k := 0; k < zVector.Len() && len(rows) > 1; k++ {
+		least := math.Inf(+1)
+		for _, j := range rows {
+			least = math.Min(least, inversedRows[j].AtVec(k)/zVector.AtVec(j))
+		}
+		var tied []int
+		for _, j := range rows {
+			if inversedRows[j].AtVec(k)/zVector.AtVec(j)-least <= tolerances.Zero*(1+math.Abs(least)) {
+				tied = append(tied, j)
+			}
+		}
+		rows = tied
+	}
+	return rows[0]
+}
+
+// perturbed - copy of baselineVector with baseline values increased by
+// distinct small values
+func perturbed(baselineVector, baselineIndexes *mat.VecDense) *mat.VecDense {
+	r := rand.New(rand.NewSource(1))
+	perturbedVector := mat.VecDenseCopyOf(baselineVector)
+	for i := 0; i < baselineIndexes.Len(); i++ {
+		index := int(baselineIndexes.AtVec(i))
+		value := baselineVector.AtVec(index)
+		perturbedVector.SetVec(index, value+perturbationScale*(1+math.Abs(value))*(1+r.Float64()))
+	}
+	return perturbedVector
+}
+
+// unperturbed - result with plan of its basis for A_B x_B = b, values close
+// to 0 than tolerances.Zero are made 0. Result is returned as is if its basis
+// is singular. False is returned if a value of the plan is below
+// -tolerances.PrimalFeasibility, the basis isn't feasible for b then
+func unperturbed(result optim.Result, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense, tolerances optim.Tolerances) (optim.Result, bool) {
+	if result.X == nil || len(result.Basis) != freeVector.Len() {
+		return result, true
+	}
+	baselineValues := mat.NewVecDense(freeVector.Len(), nil)
+	if err := baselineValues.SolveVec(linalg.Columns(conditionsMatrix, result.Basis), freeVector); err != nil {
+		return result, true
+	}
+	for i := 0; i < baselineValues.Len(); i++ {
+		if baselineValues.AtVec(i) < -tolerances.PrimalFeasibility {
+			return result, false
+		}
+	}
+	_, varNumber := conditionsMatrix.Dims()
+	result.X = mat.NewVecDense(varNumber, nil)
+	for i, index := range result.Basis {
+		if value := baselineValues.AtVec(i); math.Abs(value) > tolerances.Zero {
+			result.X.SetVec(index, value)
+		}
+	}
+	result.Objective = mat.Dot(scalesVector, result.X)
+	return result, true
+}
+
+// degeneracy - report of pivots number and baseline variables that were zero
+func degeneracy(pivots int, zeroBasic []bool) optim.Degeneracy {
+	report := optim.Degeneracy{Pivots: pivots}
+	for index, zero := range zeroBasic {
+		if zero {
+			report.ZeroBasic = append(report.ZeroBasic, index)
+		}
+	}
+	return report
+}
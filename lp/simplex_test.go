@@ -0,0 +1,95 @@
+package lp
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// randomCanonicalProblem - c'x -> max, A x + s = b, x, s >= 0 with positive
+// A and b, so slacks s make a feasible basis and every plan is bounded
+func randomCanonicalProblem(r *rand.Rand, conditionsNumber, varNumber int) (*mat.VecDense, *mat.Dense, *mat.VecDense, *mat.VecDense, []int) {
+	scalesVector := mat.NewVecDense(varNumber+conditionsNumber, nil)
+	conditionsMatrix := mat.NewDense(conditionsNumber, varNumber+conditionsNumber, nil)
+	freeVector := mat.NewVecDense(conditionsNumber, nil)
+	baselineVector := mat.NewVecDense(varNumber+conditionsNumber, nil)
+	baselineIndexes := make([]int, conditionsNumber)
+	for j := 0; j < varNumber; j++ {
+		scalesVector.SetVec(j, float64(1+r.Intn(9)))
+	}
+	for i := 0; i < conditionsNumber; i++ {
+		for j := 0; j < varNumber; j++ {
+			conditionsMatrix.Set(i, j, float64(1+r.Intn(9)))
+		}
+		conditionsMatrix.Set(i, varNumber+i, 1)
+		freeVector.SetVec(i, float64(10+r.Intn(40)))
+		baselineVector.SetVec(varNumber+i, freeVector.AtVec(i))
+		baselineIndexes[i] = varNumber + i
+	}
+	return scalesVector, conditionsMatrix, freeVector, baselineVector, baselineIndexes
+}
+
+func TestFactorizationsGiveSameResults(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for problem := 0; problem < 10; problem++ {
+		c, A, b, x0, basis := randomCanonicalProblem(r, 6, 8)
+		var results [2][2]optim.Result
+		for k, factorization := range []optim.Factorization{optim.LU, optim.ProductForm} {
+			options := optim.Options{Factorization: factorization, RefactorEvery: 2}
+			var err error
+			if results[k][0], err = SimplexMainPhaseContext(context.Background(), options, c, A, x0, basis); err != nil {
+				t.Fatalf("problem %v, simplex with %v: %v", problem, factorization, err)
+			}
+			if results[k][1], err = DoubleSimplexMethodContext(context.Background(), options, c, A, b, nil); err != nil {
+				t.Fatalf("problem %v, dual with %v: %v", problem, factorization, err)
+			}
+		}
+		// pivots of the primal method are the same, dual phase 1 and the dual
+		// method may break ties of degenerate steps by rounding errors
+		lu, eta := results[0][0], results[1][0]
+		if lu.Status != eta.Status || lu.Iterations != eta.Iterations || math.Abs(lu.Objective-eta.Objective) > 1e-9 || !mat.EqualApprox(lu.X, eta.X, 1e-9) || !equalInts(lu.Basis, eta.Basis) {
+			t.Errorf("problem %v, simplex: lu gives %v in %v iterations, objective %v, basis %v, eta gives %v in %v iterations, objective %v, basis %v", problem, lu.Status, lu.Iterations, lu.Objective, lu.Basis, eta.Status, eta.Iterations, eta.Objective, eta.Basis)
+		}
+		lu, eta = results[0][1], results[1][1]
+		if lu.Status != eta.Status || math.Abs(lu.Objective-eta.Objective) > 1e-9*(1+math.Abs(lu.Objective)) || math.Abs(lu.Objective-results[0][0].Objective) > 1e-9*(1+math.Abs(lu.Objective)) {
+			t.Errorf("problem %v, dual: lu gives %v, objective %v, eta gives %v, objective %v", problem, lu.Status, lu.Objective, eta.Status, eta.Objective)
+		}
+	}
+}
+
+// equalInts - whether a and b have the same values
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestUnperturbedNegativeValues(t *testing.T) {
+	// x1 + x2 = 1, x1 - x2 + x3 = 3: basis {1, 2} gives x2 = -1, basis {1, 3}
+	// gives x1 = 1, x3 = 2
+	scalesVector := mat.NewVecDense(3, []float64{1, 1, 0})
+	conditionsMatrix := mat.NewDense(2, 3, []float64{1, 1, 0, 1, -1, 1})
+	freeVector := mat.NewVecDense(2, []float64{1, 3})
+	tolerances := optim.DefaultTolerances
+	perturbedResult := optim.Result{X: mat.NewVecDense(3, nil)}
+
+	perturbedResult.Basis = []int{0, 1}
+	if _, feasible := unperturbed(perturbedResult, scalesVector, conditionsMatrix, freeVector, tolerances); feasible {
+		t.Errorf("basis %v with x2 = -1 is feasible", perturbedResult.Basis)
+	}
+	perturbedResult.Basis = []int{0, 2}
+	result, feasible := unperturbed(perturbedResult, scalesVector, conditionsMatrix, freeVector, tolerances)
+	if want := mat.NewVecDense(3, []float64{1, 0, 2}); !feasible || !mat.EqualApprox(result.X, want, 1e-12) || result.Objective != 1 {
+		t.Errorf("basis %v: x = %v with objective %v, feasible %v", perturbedResult.Basis, mat.Formatted(result.X.T()), result.Objective, feasible)
+	}
+}
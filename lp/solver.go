@@ -0,0 +1,289 @@
+package lp
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Solver - problem in canonical form c'x -> max, Ax = b, x >= 0 that keeps
+// the basis of its last solution and its inversed matrix. Every edit
+// re-optimizes starting from that basis: the primal simplex method is used
+// while the basis stays feasible (SetCost, AddColumn), the dual simplex method
+// while it stays dual feasible (SetRHS, AddRow), both phases are run anew if
+// it's neither. Conditions must be linearly independent
+type Solver struct {
+	ScalesVector     *mat.VecDense
+	ConditionsMatrix *mat.Dense
+	FreeVector       *mat.VecDense
+
+	ctx     context.Context
+	options optim.Options
+	result  optim.Result
+	basis   []int
+	inverse *mat.Dense
+	slacks  []int // slack column of row added by AddRow, -1 for other rows
+}
+
+// NewSolver - solver of problem solved with both phases of the simplex method
+func NewSolver(scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (*Solver, error) {
+	return NewSolverContext(context.Background(), optim.Options{}, scalesVector, conditionsMatrix, freeVector)
+}
+
+// NewSolverContext - NewSolver with limits of the first solution and every
+// re-optimization, see SimplexMainPhaseContext. Solver keeps copies of c, A
+// and b, it's returned with non optimal Result too
+func NewSolverContext(ctx context.Context, options optim.Options, scalesVector *mat.VecDense, conditionsMatrix *mat.Dense, freeVector *mat.VecDense) (*Solver, error) {
+	conditionsNumber, _ := conditionsMatrix.Dims()
+	s := &Solver{
+		ScalesVector:     mat.VecDenseCopyOf(scalesVector),
+		ConditionsMatrix: mat.DenseCopyOf(conditionsMatrix),
+		FreeVector:       mat.VecDenseCopyOf(freeVector),
+		ctx:              ctx,
+		options:          options,
+		slacks:           make([]int, conditionsNumber),
+	}
+	for i := range s.slacks {
+		s.slacks[i] = -1
+	}
+	result, err := solveCanonical(optim.NewLimits(ctx, options), s.ScalesVector, s.ConditionsMatrix, s.FreeVector)
+	if result.Basis != nil && len(result.Basis) != conditionsNumber {
+		return nil, fmt.Errorf("basis has %v indexes for %v conditions, conditions are linearly dependent", len(result.Basis), conditionsNumber)
+	}
+	s.keep(result)
+	return s, err
+}
+
+// Result - Result of the last solution
+func (s *Solver) Result() optim.Result {
+	return s.result
+}
+
+// Basis - basis the next re-optimization starts from
+func (s *Solver) Basis() []int {
+	return append([]int{}, s.basis...)
+}
+
+// SetCost - sets c[j] to value and re-optimizes
+func (s *Solver) SetCost(j int, value float64) (optim.Result, error) {
+	if j < 0 || j >= s.ScalesVector.Len() {
+		return s.result, fmt.Errorf("there's no variable %v", j+1)
+	}
+	s.ScalesVector.SetVec(j, value)
+	return s.reoptimize()
+}
+
+// SetRHS - sets b[i] to value and re-optimizes
+func (s *Solver) SetRHS(i int, value float64) (optim.Result, error) {
+	if i < 0 || i >= s.FreeVector.Len() {
+		return s.result, fmt.Errorf("there's no condition %v", i+1)
+	}
+	s.FreeVector.SetVec(i, value)
+	return s.reoptimize()
+}
+
+// AddColumn - adds variable with cost and column of A out of basis and
+// re-optimizes. The variable gets the last index
+func (s *Solver) AddColumn(cost float64, column *mat.VecDense) (optim.Result, error) {
+	conditionsNumber, varNumber := s.ConditionsMatrix.Dims()
+	if column.Len() != conditionsNumber {
+		return s.result, fmt.Errorf("column has %v values, expected %v", column.Len(), conditionsNumber)
+	}
+	conditionsMatrix := mat.NewDense(conditionsNumber, varNumber+1, nil)
+	conditionsMatrix.Slice(0, conditionsNumber, 0, varNumber).(*mat.Dense).Copy(s.ConditionsMatrix)
+	conditionsMatrix.SetCol(varNumber, column.RawVector().Data)
+	s.ConditionsMatrix = conditionsMatrix
+	s.ScalesVector = mat.NewVecDense(varNumber+1, append(linalg.RawVector(s.ScalesVector), cost))
+	return s.reoptimize()
+}
+
+// AddRow - adds condition row'x <= value (>= value) with a new slack
+// variable, which gets the last index and joins the basis, and re-optimizes.
+// Equal conditions aren't added, they're two inequalities
+func (s *Solver) AddRow(row *mat.VecDense, relation Relation, value float64) (optim.Result, error) {
+	conditionsNumber, varNumber := s.ConditionsMatrix.Dims()
+	if row.Len() != varNumber {
+		return s.result, fmt.Errorf("row has %v values, expected %v", row.Len(), varNumber)
+	}
+	sign := 1.0
+	switch relation {
+	case GreaterEqual:
+		sign = -1
+	case Equal:
+		return s.result, fmt.Errorf("row is added with <= or >= relation, got %v", relation)
+	}
+	conditionsMatrix := mat.NewDense(conditionsNumber+1, varNumber+1, nil)
+	conditionsMatrix.Slice(0, conditionsNumber, 0, varNumber).(*mat.Dense).Copy(s.ConditionsMatrix)
+	conditionsMatrix.SetRow(conditionsNumber, append(linalg.RawVector(row), sign))
+	s.ConditionsMatrix = conditionsMatrix
+	s.ScalesVector = mat.NewVecDense(varNumber+1, append(linalg.RawVector(s.ScalesVector), 0))
+	s.FreeVector = mat.NewVecDense(conditionsNumber+1, append(linalg.RawVector(s.FreeVector), value))
+	s.slacks = append(s.slacks, varNumber)
+	if s.basis != nil {
+		s.basis = append(s.basis, varNumber)
+		s.inverse = nil
+	}
+	return s.reoptimize()
+}
+
+// RemoveRow - removes condition i together with its slack variable if it was
+// added by AddRow and re-optimizes. Indexes of the following conditions and
+// variables go down by one
+func (s *Solver) RemoveRow(i int) (optim.Result, error) {
+	conditionsNumber, varNumber := s.ConditionsMatrix.Dims()
+	if i < 0 || i >= conditionsNumber {
+		return s.result, fmt.Errorf("there's no condition %v", i+1)
+	}
+	if conditionsNumber == 1 {
+		return s.result, fmt.Errorf("the only condition can't be removed")
+	}
+
+	// basis leaves the slack variable of the row or the baseline column that
+	// the row keeps A_B nonsingular for
+	slack := s.slacks[i]
+	if s.basis != nil {
+		leaving := -1
+		for k, index := range s.basis {
+			if index == slack {
+				leaving = k
+			}
+		}
+		if leaving < 0 {
+			inverse := s.inverseBasis()
+			for k := range s.basis {
+				if inverse != nil && (leaving < 0 || math.Abs(inverse.At(k, i)) > math.Abs(inverse.At(leaving, i))) {
+					leaving = k
+				}
+			}
+		}
+		if leaving >= 0 {
+			s.basis = append(append([]int{}, s.basis[:leaving]...), s.basis[leaving+1:]...)
+		} else {
+			s.basis = nil
+		}
+		s.inverse = nil
+	}
+
+	columns := make([]int, 0, varNumber)
+	for j := 0; j < varNumber; j++ {
+		if j != slack {
+			columns = append(columns, j)
+		}
+	}
+	rows := make([]int, 0, conditionsNumber-1)
+	for k := 0; k < conditionsNumber; k++ {
+		if k != i {
+			rows = append(rows, k)
+		}
+	}
+	conditionsMatrix := mat.NewDense(len(rows), len(columns), nil)
+	scalesVector := mat.NewVecDense(len(columns), nil)
+	freeVector := mat.NewVecDense(len(rows), nil)
+	for r, k := range rows {
+		for c, j := range columns {
+			conditionsMatrix.Set(r, c, s.ConditionsMatrix.At(k, j))
+		}
+		freeVector.SetVec(r, s.FreeVector.AtVec(k))
+	}
+	for c, j := range columns {
+		scalesVector.SetVec(c, s.ScalesVector.AtVec(j))
+	}
+	s.ConditionsMatrix, s.ScalesVector, s.FreeVector = conditionsMatrix, scalesVector, freeVector
+	s.slacks = append(s.slacks[:i], s.slacks[i+1:]...)
+	if slack >= 0 {
+		for k := range s.slacks {
+			if s.slacks[k] > slack {
+				s.slacks[k]--
+			}
+		}
+		for k := range s.basis {
+			if s.basis[k] > slack {
+				s.basis[k]--
+			}
+		}
+	}
+	return s.reoptimize()
+}
+
+// reoptimize - solves problem starting from kept basis with the primal
+// simplex method if it's feasible, with the dual one if it's dual feasible
+// and with both phases anew otherwise
+func (s *Solver) reoptimize() (optim.Result, error) {
+	limits := optim.NewLimits(s.ctx, s.options)
+	var a analysis
+	var err error = optim.ErrSingularBasis
+	if s.basis != nil {
+		a, err = analyse(s.ScalesVector, s.ConditionsMatrix, s.FreeVector, optim.Result{Status: optim.Optimal, Basis: s.basis, InverseBasis: s.inverseBasis()}, limits.Tolerances())
+	}
+	var result optim.Result
+	switch {
+	case err != nil:
+		optim.Tracef("basis %v is singular, solving anew\n", s.basis)
+		result, err = solveCanonical(limits, s.ScalesVector, s.ConditionsMatrix, s.FreeVector)
+	case isNonnegative(a.baselineValues, limits.Tolerances().PrimalFeasibility):
+		optim.Tracef("basis %v is feasible, primal simplex method\n", s.basis)
+		plan := a.plan(s.basis)
+		for _, index := range s.basis {
+			plan.SetVec(index, math.Max(plan.AtVec(index), 0))
+		}
+		result, err = simplexMainPhase(limits, s.ScalesVector, s.ConditionsMatrix, plan, linalg.FloatVector(s.basis))
+	case isNonnegative(a.scoreVector, limits.Tolerances().DualFeasibility):
+		optim.Tracef("basis %v is dual feasible, dual simplex method\n", s.basis)
+		result, err = doubleSimplexMethod(limits, s.ScalesVector, s.ConditionsMatrix, s.FreeVector, linalg.FloatVector(s.basis))
+		if err == nil {
+			// zero pivots of the main phase find dual solution of the plan
+			iterations := result.Iterations
+			result, err = simplexMainPhase(limits, s.ScalesVector, s.ConditionsMatrix, result.X, linalg.FloatVector(result.Basis))
+			result.Iterations += iterations
+		}
+	default:
+		optim.Tracef("basis %v is neither feasible nor dual feasible, solving anew\n", s.basis)
+		result, err = solveCanonical(limits, s.ScalesVector, s.ConditionsMatrix, s.FreeVector)
+	}
+	lowerBounds, upperBounds := nonnegativeBounds(s.ScalesVector.Len())
+	result, err = withInfeasibility(result, err, limits.Tolerances(), s.ScalesVector, s.ConditionsMatrix, s.FreeVector, lowerBounds, upperBounds)
+	s.keep(result)
+	return result, err
+}
+
+// keep - keeps basis of result for the next re-optimization unless it's
+// short of conditions or has artificial variables of the preparation phase
+func (s *Solver) keep(result optim.Result) {
+	s.result = result
+	conditionsNumber, varNumber := s.ConditionsMatrix.Dims()
+	if len(result.Basis) != conditionsNumber {
+		return
+	}
+	for _, index := range result.Basis {
+		if index >= varNumber {
+			return
+		}
+	}
+	s.basis, s.inverse = append([]int{}, result.Basis...), result.InverseBasis
+}
+
+// inverseBasis - inversed matrix of kept basis, nil if it's singular
+func (s *Solver) inverseBasis() *mat.Dense {
+	if s.inverse == nil && s.basis != nil {
+		conditionsNumber, _ := s.ConditionsMatrix.Dims()
+		inverse := mat.NewDense(conditionsNumber, conditionsNumber, nil)
+		if err := inverse.Inverse(linalg.Columns(s.ConditionsMatrix, s.basis)); err == nil {
+			s.inverse = inverse
+		}
+	}
+	return s.inverse
+}
+
+// isNonnegative - whether every value of vector is above -tolerance
+func isNonnegative(vector *mat.VecDense, tolerance float64) bool {
+	for i := 0; i < vector.Len(); i++ {
+		if vector.AtVec(i) < -tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package lp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSolverEditsMatchColdSolve(t *testing.T) {
+	// 2x1 + 3x2 -> max, x1 + x2 <= 4, x1 + 3x2 <= 6 with slacks x3, x4 is
+	// optimal at x1 = 3, x2 = 1
+	tests := []struct {
+		name      string
+		edit      func(s *Solver) (optim.Result, error)
+		status    optim.Status
+		objective float64
+	}{
+		{"cost of x1", func(s *Solver) (optim.Result, error) {
+			return s.SetCost(0, 0.5)
+		}, optim.Optimal, 6},
+		{"free value of condition 2", func(s *Solver) (optim.Result, error) {
+			return s.SetRHS(1, 9)
+		}, optim.Optimal, 10.5},
+		{"row x1 <= 2", func(s *Solver) (optim.Result, error) {
+			return s.AddRow(mat.NewVecDense(4, []float64{1, 0, 0, 0}), LessEqual, 2)
+		}, optim.Optimal, 8},
+		{"row x1 + x2 >= 5", func(s *Solver) (optim.Result, error) {
+			return s.AddRow(mat.NewVecDense(4, []float64{1, 1, 0, 0}), GreaterEqual, 5)
+		}, optim.Infeasible, 0},
+		{"column of cost 5", func(s *Solver) (optim.Result, error) {
+			return s.AddColumn(5, mat.NewVecDense(2, []float64{1, 1}))
+		}, optim.Optimal, 20},
+		{"condition 2", func(s *Solver) (optim.Result, error) {
+			return s.RemoveRow(1)
+		}, optim.Optimal, 12},
+		{"added row removed", func(s *Solver) (optim.Result, error) {
+			if _, err := s.AddRow(mat.NewVecDense(4, []float64{0, 1, 0, 0}), LessEqual, 0.5); err != nil {
+				return optim.Result{}, err
+			}
+			return s.RemoveRow(2)
+		}, optim.Optimal, 9},
+	}
+	for _, test := range tests {
+		s, err := NewSolver(mat.NewVecDense(4, []float64{2, 3, 0, 0}),
+			mat.NewDense(2, 4, []float64{1, 1, 1, 0, 1, 3, 0, 1}),
+			mat.NewVecDense(2, []float64{4, 6}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := test.edit(s)
+		cold, coldErr := NewSolver(s.ScalesVector, s.ConditionsMatrix, s.FreeVector)
+		if coldErr != nil && cold == nil {
+			t.Fatalf("%v: cold solve: %v", test.name, coldErr)
+		}
+		want := cold.Result()
+		switch {
+		case (err == nil) != (coldErr == nil) || result.Status != test.status || want.Status != test.status:
+			t.Errorf("%v: %v with error %v, cold solve gives %v with %v, want %v", test.name, result.Status, err, want.Status, coldErr, test.status)
+		case test.status != optim.Optimal:
+			// there's no plan to compare
+		case math.Abs(result.Objective-test.objective) > 1e-9 || math.Abs(want.Objective-test.objective) > 1e-9:
+			t.Errorf("%v: objective %v, cold solve gives %v, want %v", test.name, result.Objective, want.Objective, test.objective)
+		case !mat.EqualApprox(result.X, want.X, 1e-9) || !mat.EqualApprox(result.Duals, want.Duals, 1e-9):
+			t.Errorf("%v: x = %v with duals %v, cold solve gives %v with %v", test.name, mat.Formatted(result.X.T()), mat.Formatted(result.Duals.T()), mat.Formatted(want.X.T()), mat.Formatted(want.Duals.T()))
+		}
+	}
+}
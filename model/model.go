@@ -0,0 +1,195 @@
+// Package model builds general form linear optimization problems from named
+// variables and linear expressions instead of matrices written by hand:
+//
+//	m := model.New()
+//	x := m.AddVar("x", 0, model.Inf)
+//	y := m.AddVar("y", 0, 3)
+//	timber := m.AddConstraint(x.Mul(1).Add(1, y).LE(4).Named("timber"))
+//	m.Maximize(x.Mul(3).Add(2, y))
+//	sol, err := m.Solve()
+//	fmt.Println(sol.Value(x), sol.Slack(timber), sol.Dual(timber))
+//
+// Model compiles to lp.GeneralProblem, whose Canonical form holds the dense
+// matrices the simplex methods of package lp work with.
+package model
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Lykashonok/moiu_labs_3_course/lp"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Inf - infinite bound, -Inf is for variables unbounded from below
+var Inf = math.Inf(1)
+
+// Model - variables, conditions and objective of a problem
+type Model struct {
+	sense     lp.Sense
+	objective Expr
+	vars      []variable
+	conds     []Condition
+}
+
+type variable struct {
+	name         string
+	lower, upper float64
+}
+
+// Var - variable of Model
+type Var struct {
+	index int
+}
+
+// Constraint - condition added to Model
+type Constraint struct {
+	index int
+}
+
+// term - coefficient*variable
+type term struct {
+	coefficient float64
+	v           Var
+}
+
+// Expr - linear expression, sum of variables with coefficients
+type Expr struct {
+	terms []term
+}
+
+// Condition - Expr relation Value, Name is optional
+type Condition struct {
+	Expr     Expr
+	Relation lp.Relation
+	Value    float64
+	Name     string
+}
+
+// New - empty model with c'x -> max objective
+func New() *Model {
+	return &Model{}
+}
+
+// AddVar - adds variable with bounds lower <= x <= upper, bounds may be -Inf
+// and Inf
+func (m *Model) AddVar(name string, lower, upper float64) Var {
+	m.vars = append(m.vars, variable{name: name, lower: lower, upper: upper})
+	return Var{index: len(m.vars) - 1}
+}
+
+// AddConstraint - adds condition to model
+func (m *Model) AddConstraint(condition Condition) Constraint {
+	m.conds = append(m.conds, condition)
+	return Constraint{index: len(m.conds) - 1}
+}
+
+// Maximize - sets objective expr -> max
+func (m *Model) Maximize(expr Expr) {
+	m.sense, m.objective = lp.Maximize, expr
+}
+
+// Minimize - sets objective expr -> min
+func (m *Model) Minimize(expr Expr) {
+	m.sense, m.objective = lp.Minimize, expr
+}
+
+// Mul - coefficient*v
+func (v Var) Mul(coefficient float64) Expr {
+	return Expr{terms: []term{{coefficient: coefficient, v: v}}}
+}
+
+// Add - e + coefficient*v
+func (e Expr) Add(coefficient float64, v Var) Expr {
+	return e.Plus(v.Mul(coefficient))
+}
+
+// Plus - e + other
+func (e Expr) Plus(other Expr) Expr {
+	terms := make([]term, 0, len(e.terms)+len(other.terms))
+	return Expr{terms: append(append(terms, e.terms...), other.terms...)}
+}
+
+// Sum - sum of expressions
+func Sum(exprs ...Expr) Expr {
+	var sum Expr
+	for _, e := range exprs {
+		sum = sum.Plus(e)
+	}
+	return sum
+}
+
+// LE - condition e <= value
+func (e Expr) LE(value float64) Condition {
+	return Condition{Expr: e, Relation: lp.LessEqual, Value: value}
+}
+
+// GE - condition e >= value
+func (e Expr) GE(value float64) Condition {
+	return Condition{Expr: e, Relation: lp.GreaterEqual, Value: value}
+}
+
+// EQ - condition e = value
+func (e Expr) EQ(value float64) Condition {
+	return Condition{Expr: e, Relation: lp.Equal, Value: value}
+}
+
+// Named - condition with name
+func (c Condition) Named(name string) Condition {
+	c.Name = name
+	return c
+}
+
+// Problem - model compiled to general form problem, variables and conditions
+// keep their order of adding. Unnamed conditions are named c1, c2, ...
+func (m *Model) Problem() (lp.GeneralProblem, error) {
+	if len(m.vars) == 0 || len(m.conds) == 0 {
+		return lp.GeneralProblem{}, fmt.Errorf("model has no variables or no conditions")
+	}
+	varNumber, conditionsNumber := len(m.vars), len(m.conds)
+
+	scalesVector := mat.NewVecDense(varNumber, nil)
+	for _, t := range m.objective.terms {
+		if err := m.check(t.v); err != nil {
+			return lp.GeneralProblem{}, err
+		}
+		scalesVector.SetVec(t.v.index, scalesVector.AtVec(t.v.index)+t.coefficient)
+	}
+
+	conditionsMatrix := mat.NewDense(conditionsNumber, varNumber, nil)
+	freeVector := mat.NewVecDense(conditionsNumber, nil)
+	relations := make([]lp.Relation, conditionsNumber)
+	names := make([]string, conditionsNumber)
+	for i, condition := range m.conds {
+		for _, t := range condition.Expr.terms {
+			if err := m.check(t.v); err != nil {
+				return lp.GeneralProblem{}, err
+			}
+			conditionsMatrix.Set(i, t.v.index, conditionsMatrix.At(i, t.v.index)+t.coefficient)
+		}
+		freeVector.SetVec(i, condition.Value)
+		relations[i] = condition.Relation
+		names[i] = condition.Name
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("c%v", i+1)
+		}
+	}
+
+	problem := lp.NewGeneralProblem(m.sense, scalesVector, conditionsMatrix, relations, freeVector)
+	problem.VarNames = make([]string, varNumber)
+	for i, v := range m.vars {
+		problem.LowerBounds.SetVec(i, v.lower)
+		problem.UpperBounds.SetVec(i, v.upper)
+		problem.VarNames[i] = v.name
+	}
+	problem.ConditionNames = names
+	return problem, nil
+}
+
+// check - error if v isn't a variable of model
+func (m *Model) check(v Var) error {
+	if v.index < 0 || v.index >= len(m.vars) {
+		return fmt.Errorf("variable %v isn't in model", v.index)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+)
+
+func TestModelSolve(t *testing.T) {
+	// 3x + 2y -> max, x + y <= 4, x + 3y <= 6, x - y >= 1, y <= 3 is
+	// optimal at x = 4, y = 0 with timber as the only tight condition
+	m := New()
+	x, y := m.AddVar("x", 0, Inf), m.AddVar("y", 0, 3)
+	vars := map[string]Var{"x": x, "y": y}
+	timber := m.AddConstraint(x.Mul(1).Add(1, y).LE(4).Named("timber"))
+	labour := m.AddConstraint(Sum(x.Mul(1), y.Mul(3)).LE(6).Named("labour"))
+	balance := m.AddConstraint(x.Mul(1).Add(-1, y).GE(1))
+	m.Maximize(x.Mul(3).Add(2, y))
+
+	solution, err := m.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solution.Result.Status != optim.Optimal || math.Abs(solution.Objective()-12) > 1e-9 {
+		t.Fatalf("%v with objective %v, want optimal 12", solution.Result.Status, solution.Objective())
+	}
+	for name, want := range map[string]float64{"x": 4, "y": 0} {
+		if got := solution.Value(vars[name]); solution.Name(vars[name]) != name || math.Abs(got-want) > 1e-9 {
+			t.Errorf("%v = %v, want %v = %v", solution.Name(vars[name]), got, name, want)
+		}
+	}
+	if got := solution.ReducedCost(y); math.Abs(got+1) > 1e-9 {
+		t.Errorf("reduced cost of y %v, want -1", got)
+	}
+
+	problem, err := m.Problem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conditions := []struct {
+		constraint  Constraint
+		name        string
+		slack, dual float64
+	}{
+		{timber, "timber", 0, 3},
+		{labour, "labour", 2, 0},
+		{balance, "c3", 3, 0},
+	}
+	for _, c := range conditions {
+		if name := problem.ConditionNames[c.constraint.index]; name != c.name {
+			t.Errorf("condition %v is named %v, want %v", c.constraint.index+1, name, c.name)
+		}
+		if slack, dual := solution.Slack(c.constraint), solution.Dual(c.constraint); math.Abs(slack-c.slack) > 1e-9 || math.Abs(dual-c.dual) > 1e-9 {
+			t.Errorf("%v: slack %v with dual %v, want %v with %v", c.name, slack, dual, c.slack, c.dual)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"context"
+
+	"github.com/Lykashonok/moiu_labs_3_course/lp"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Solution - Result of model solving with values of variables and conditions
+type Solution struct {
+	Result  optim.Result
+	problem lp.GeneralProblem
+}
+
+// Solve - solves model with both phases of the simplex method. Solution is
+// returned with non optimal Result too, dual values are known for optimal one
+// only
+func (m *Model) Solve() (*Solution, error) {
+	return m.SolveContext(context.Background(), optim.Options{})
+}
+
+// SolveContext - Solve with limits, see lp.SimplexMainPhaseContext
+func (m *Model) SolveContext(ctx context.Context, options optim.Options) (*Solution, error) {
+	problem, err := m.Problem()
+	if err != nil {
+		return nil, err
+	}
+	solution := &Solution{problem: problem}
+	solution.Result, err = problem.Canonical().SolveContext(ctx, options)
+	return solution, err
+}
+
+// Objective - objective value of plan
+func (s *Solution) Objective() float64 {
+	return s.Result.Objective
+}
+
+// Value - value of v in plan
+func (s *Solution) Value(v Var) float64 {
+	if s.Result.X == nil {
+		return 0
+	}
+	return s.Result.X.AtVec(v.index)
+}
+
+// Slack - how far condition c is from being tight: b - A[i]x for <= and =
+// conditions, A[i]x - b for >= ones
+func (s *Solution) Slack(c Constraint) float64 {
+	if s.Result.X == nil {
+		return 0
+	}
+	if s.Result.Slacks != nil {
+		return s.Result.Slacks.AtVec(c.index)
+	}
+	slack := s.problem.FreeVector.AtVec(c.index) - mat.Dot(s.problem.ConditionsMatrix.RowView(c.index), s.Result.X)
+	if s.problem.Relations[c.index] == lp.GreaterEqual {
+		return -slack
+	}
+	return slack
+}
+
+// Dual - dual value of condition c: change of the optimal objective per unit
+// increase of its value, 0 if Result isn't optimal
+func (s *Solution) Dual(c Constraint) float64 {
+	if s.Result.Duals == nil {
+		return 0
+	}
+	return s.Result.Duals.AtVec(c.index)
+}
+
+// ReducedCost - change of the optimal objective per unit increase of v, 0 if
+// Result isn't optimal
+func (s *Solution) ReducedCost(v Var) float64 {
+	if s.Result.ReducedCosts == nil {
+		return 0
+	}
+	return s.Result.ReducedCosts.AtVec(v.index)
+}
+
+// Name - name of v
+func (s *Solution) Name(v Var) string {
+	return s.problem.VarName(v.index)
+}
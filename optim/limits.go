@@ -0,0 +1,150 @@
+package optim
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Options - limits of a solver run. Zero MaxIterations means MaxIterations
+// pivots, zero TimeLimit means no time limit. Pricing and AntiCycling are used
+// by the primal simplex method, nil Pricing means the solver's default rule.
+// Factorization and RefactorEvery are used by the primal, dual and two-phase
+// simplex methods, zero RefactorEvery means RefactorEvery updates. Tolerances
+// are used by every solver, see DefaultTolerances
+type Options struct {
+	MaxIterations int
+	TimeLimit     time.Duration
+	Pricing       PricingRule
+	AntiCycling   AntiCycling
+	Factorization Factorization
+	RefactorEvery int
+	Tolerances    Tolerances
+}
+
+// AntiCycling - how the primal simplex method chooses leaving row among rows
+// with the least ratio of degenerate problems
+type AntiCycling int
+
+const (
+	// FirstRow - the first row with the least ratio, may cycle
+	FirstRow AntiCycling = iota
+	// Lexicographic - row with lexicographically least row of inversed
+	// baseline matrix divided by its value of entering column
+	Lexicographic
+	// Perturbation - baseline values are perturbed by distinct small values,
+	// so ratios don't tie, and the perturbation is removed from the last plan
+	Perturbation
+)
+
+func (a AntiCycling) String() string {
+	switch a {
+	case FirstRow:
+		return "first"
+	case Lexicographic:
+		return "lexicographic"
+	case Perturbation:
+		return "perturbation"
+	}
+	return "unknown"
+}
+
+// Factorization - how the simplex methods keep baseline matrix between pivots
+type Factorization int
+
+const (
+	// LU - linalg.LUFactor, LU with Markowitz pivoting and Forrest-Tomlin
+	// updates
+	LU Factorization = iota
+	// ProductForm - linalg.EtaFile, LU of the last factorization and eta
+	// columns of updates
+	ProductForm
+)
+
+func (f Factorization) String() string {
+	switch f {
+	case LU:
+		return "lu"
+	case ProductForm:
+		return "eta"
+	}
+	return "unknown"
+}
+
+// Limits - stop conditions of a solver run: its context and Options
+type Limits struct {
+	ctx           context.Context
+	maxIterations int
+	timeLimit     time.Duration
+	deadline      time.Time
+	pricing       PricingRule
+	antiCycling   AntiCycling
+	factorization Factorization
+	refactorEvery int
+	tolerances    Tolerances
+}
+
+// NewLimits - limits of a run starting now
+func NewLimits(ctx context.Context, options Options) Limits {
+	limits := Limits{ctx: ctx, maxIterations: options.MaxIterations, timeLimit: options.TimeLimit, pricing: options.Pricing, antiCycling: options.AntiCycling, factorization: options.Factorization, refactorEvery: options.RefactorEvery, tolerances: options.Tolerances.withDefaults()}
+	if limits.maxIterations == 0 {
+		limits.maxIterations = MaxIterations
+	}
+	if limits.refactorEvery <= 0 {
+		limits.refactorEvery = RefactorEvery
+	}
+	if limits.timeLimit > 0 {
+		limits.deadline = time.Now().Add(limits.timeLimit)
+	}
+	return limits
+}
+
+// Check - whether solver may make pivot number iteration+1. Otherwise returns
+// IterationLimit with ErrIterationLimit or Canceled with error wrapping
+// ErrCanceled
+func (l Limits) Check(iteration int) (Status, error) {
+	if l.ctx != nil {
+		if err := l.ctx.Err(); err != nil {
+			return Canceled, fmt.Errorf("%w: %v", ErrCanceled, err)
+		}
+	}
+	if !l.deadline.IsZero() && time.Now().After(l.deadline) {
+		return Canceled, fmt.Errorf("%w: time limit %v passed", ErrCanceled, l.timeLimit)
+	}
+	if iteration >= l.maxIterations {
+		return IterationLimit, ErrIterationLimit
+	}
+	return NotSolved, nil
+}
+
+// Pricing - pricing rule of Options, nil if it wasn't set
+func (l Limits) Pricing() PricingRule {
+	return l.pricing
+}
+
+// AntiCycling - anti cycling mode of Options
+func (l Limits) AntiCycling() AntiCycling {
+	return l.antiCycling
+}
+
+// WithAntiCycling - the same limits with anti cycling mode antiCycling
+func (l Limits) WithAntiCycling(antiCycling AntiCycling) Limits {
+	l.antiCycling = antiCycling
+	return l
+}
+
+// Factorization - factorization of baseline matrix of Options
+func (l Limits) Factorization() Factorization {
+	return l.factorization
+}
+
+// RefactorEvery - updates of factorization of baseline matrix between its
+// factorizations anew
+func (l Limits) RefactorEvery() int {
+	return l.refactorEvery
+}
+
+// Tolerances - tolerances of Options with defaults in place of zero ones
+func (l Limits) Tolerances() Tolerances {
+	return l.tolerances
+}
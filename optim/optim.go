@@ -0,0 +1,153 @@
+// Package optim holds what all the solvers have in common: Result with its
+// Status, sentinel errors and the iterations log.
+package optim
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"gonum.org/v1/gonum/mat"
+)
+
+// MaxIterations - solvers give up with IterationLimit after this many pivots
+// unless Options set another limit
+const MaxIterations = 1000
+
+// RefactorEvery - the simplex methods factorize baseline matrix anew after
+// this many updates of its factorization unless Options set another number
+const RefactorEvery = 50
+
+// Status - how a solver run ended
+type Status int
+
+const (
+	// NotSolved - solver didn't get to a plan, e.g. because of bad input
+	NotSolved Status = iota
+	// Optimal - optimal plan was found
+	Optimal
+	// Infeasible - there's no plan satisfying the conditions
+	Infeasible
+	// Unbounded - objective can be improved without limit
+	Unbounded
+	// SingularBasis - baseline matrix can't be inversed
+	SingularBasis
+	// IterationLimit - solver stopped after MaxIterations pivots
+	IterationLimit
+	// Canceled - solver stopped because its context was done or its time
+	// limit passed
+	Canceled
+)
+
+func (s Status) String() string {
+	switch s {
+	case NotSolved:
+		return "not solved"
+	case Optimal:
+		return "optimal"
+	case Infeasible:
+		return "infeasible"
+	case Unbounded:
+		return "unbounded"
+	case SingularBasis:
+		return "singular basis"
+	case IterationLimit:
+		return "iteration limit"
+	case Canceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// Errors returned together with a non optimal Result, check them with errors.Is
+var (
+	ErrInfeasible          = errors.New("problem is infeasible")
+	ErrUnbounded           = errors.New("problem is unbounded")
+	ErrSingularBasis       = linalg.ErrSingular
+	ErrIterationLimit      = errors.New("iteration limit reached")
+	ErrCanceled            = errors.New("solver was canceled")
+	ErrDualityGap          = errors.New("primal and dual objectives differ")
+	ErrPrimalInfeasibility = errors.New("plan violates conditions")
+)
+
+// Result - what every solver returns. X holds the last plan (the optimal one
+// if Status is Optimal), Basis its baseline indexes. ExtendedBasis is set by
+// quadratic problems solver only, Degeneracy by the primal simplex method only.
+// The primal simplex method sets dual solution of optimal plan too: Duals
+// (potentials u' = c_B'A_B^-1), ReducedCosts (deltas u'A - c), Slacks of
+// conditions (b - Ax), DualObjective u'b, equal to Objective, and
+// InverseBasis, the inversed baseline matrix A_B^-1. Solvers run with
+// rational arithmetic set Exact values too. PrimalInfeasibility is the
+// greatest violation of conditions and bounds by X, DualInfeasibility the
+// greatest violation of signs of deltas of Basis, see Tolerances. The simplex
+// methods report the greatest of them over all their iterations: primal
+// infeasibility of every plan of the primal methods and dual infeasibility of
+// every basis of the dual ones
+type Result struct {
+	Status        Status
+	Objective     float64
+	X             *mat.VecDense
+	Basis         []int
+	ExtendedBasis []int
+	Iterations    int
+	Degeneracy    Degeneracy
+
+	Duals         *mat.VecDense
+	ReducedCosts  *mat.VecDense
+	Slacks        *mat.VecDense
+	DualObjective float64
+	InverseBasis  *mat.Dense
+
+	PrimalInfeasibility float64
+	DualInfeasibility   float64
+
+	Exact *Exact
+}
+
+// CheckPrimalFeasibility - Optimal result whose PrimalInfeasibility exceeds
+// tolerances.PrimalFeasibility is NotSolved with ErrPrimalInfeasibility,
+// results of other runs are returned with their err as they are
+func CheckPrimalFeasibility(result Result, err error, tolerances Tolerances) (Result, error) {
+	if err != nil || result.Status != Optimal || result.PrimalInfeasibility <= tolerances.PrimalFeasibility {
+		return result, err
+	}
+	Tracef("optimal plan violates conditions by %v\n", result.PrimalInfeasibility)
+	result.Status = NotSolved
+	return result, fmt.Errorf("%w by %v", ErrPrimalInfeasibility, result.PrimalInfeasibility)
+}
+
+// Exact - values of Result as fractions, dual solution is nil unless the
+// solver sets it
+type Exact struct {
+	Objective *big.Rat
+	X         []*big.Rat
+
+	Duals         []*big.Rat
+	ReducedCosts  []*big.Rat
+	Slacks        []*big.Rat
+	DualObjective *big.Rat
+}
+
+// Degeneracy - degenerate pivots of a run: Pivots is the number of pivots with
+// zero step length, ZeroBasic holds indexes of baseline variables that were
+// zero on some iteration in increasing order
+type Degeneracy struct {
+	Pivots    int
+	ZeroBasic []int
+}
+
+// Trace - solvers write their iterations log here, it's discarded by default
+var Trace io.Writer = ioutil.Discard
+
+// Tracef - write formatted line of the iterations log
+func Tracef(format string, a ...interface{}) {
+	fmt.Fprintf(Trace, format, a...)
+}
+
+// TraceMatrix - write matrix to the iterations log
+func TraceMatrix(X mat.Matrix) {
+	linalg.MatFprint(Trace, X)
+}
@@ -0,0 +1,35 @@
+package optim
+
+import (
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"gonum.org/v1/gonum/mat"
+)
+
+// PricingState - what pricing rule sees on an iteration of the primal simplex
+// method. Scores holds deltas u'A - c, negative ones are of columns improving
+// the objective, scores above -Tolerance are left by rounding errors and
+// aren't negative. Nonbasic holds indexes of nonbaseline columns in
+// increasing order, Factor is the factorization of baseline matrix of Basis,
+// its Solve and SolveTranspose give columns and rows of the inversed one
+type PricingState struct {
+	Scores     *mat.VecDense
+	Tolerance  float64
+	Basis      []int
+	Nonbasic   []int
+	Factor     linalg.BasisFactor
+	Conditions *mat.Dense
+}
+
+// PricingRule - chooses the column entering basis in the primal simplex
+// method. Rules may keep state between iterations, so a rule value must not be
+// shared by solver runs at the same time
+type PricingRule interface {
+	// Start - called at the start of a run with varNumber columns
+	Start(varNumber int)
+	// Entering - index of entering nonbaseline column with negative score, -1
+	// if there's none and the plan is optimal
+	Entering(state PricingState) int
+	// Update - called before the pivot replacing Basis[leaving] with entering
+	// column, column is Factor's Solve of entering column of Conditions
+	Update(state PricingState, entering, leaving int, column *mat.VecDense)
+}
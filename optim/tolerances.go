@@ -0,0 +1,55 @@
+package optim
+
+// Tolerances - how far float64 values of solvers may be from exact ones.
+// PrimalFeasibility - violation of conditions and bounds a plan may have,
+// basis factors are factorized anew when relative residual of their solves
+// grows over it, DualFeasibility - violation of signs of deltas (reduced
+// costs) an optimal plan may have, Pivot - the least absolute value of pivot
+// element, smaller ones would make baseline matrix almost singular, Zero -
+// values this close to zero are zero, e.g. steps of degenerate pivots,
+// Integrality - values this close to an integer are integral, DualityGap -
+// difference of primal and dual objectives relative to 1 + |objective| an
+// optimal plan may have, branch and bound skips branches whose bound is within
+// it times max(1, |objective|) of the best integer objective. Zero fields of Options take
+// DefaultTolerances
+type Tolerances struct {
+	PrimalFeasibility float64
+	DualFeasibility   float64
+	Pivot             float64
+	Zero              float64
+	Integrality       float64
+	DualityGap        float64
+}
+
+// DefaultTolerances - tolerances of solvers run without Options setting them
+var DefaultTolerances = Tolerances{
+	PrimalFeasibility: 1e-9,
+	DualFeasibility:   1e-9,
+	Pivot:             1e-9,
+	Zero:              1e-9,
+	Integrality:       1e-6,
+	DualityGap:        1e-6,
+}
+
+// withDefaults - t with DefaultTolerances in place of zero fields
+func (t Tolerances) withDefaults() Tolerances {
+	if t.PrimalFeasibility <= 0 {
+		t.PrimalFeasibility = DefaultTolerances.PrimalFeasibility
+	}
+	if t.DualFeasibility <= 0 {
+		t.DualFeasibility = DefaultTolerances.DualFeasibility
+	}
+	if t.Pivot <= 0 {
+		t.Pivot = DefaultTolerances.Pivot
+	}
+	if t.Zero <= 0 {
+		t.Zero = DefaultTolerances.Zero
+	}
+	if t.Integrality <= 0 {
+		t.Integrality = DefaultTolerances.Integrality
+	}
+	if t.DualityGap <= 0 {
+		t.DualityGap = DefaultTolerances.DualityGap
+	}
+	return t
+}
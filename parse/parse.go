@@ -0,0 +1,165 @@
+// Package parse splits problem files into problems. Problems are separated by
+// blank lines, every line of a problem is a row of values separated by
+// whitespace or commas. Everything after # up to the end of line is a comment,
+// lines holding only comments are skipped. Numbers may be written as fractions
+// like 3/4.
+package parse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Block - one problem of the file: its rows and the line number of the first
+// one (numeration starts from 1)
+type Block struct {
+	File string
+	Line int
+	Rows [][]Token
+}
+
+// ReadBlocks - problems of input file
+func ReadBlocks(input string) ([]Block, error) {
+	str, err := ioutil.ReadFile(input)
+	if err != nil {
+		return nil, err
+	}
+	return Blocks(input, string(str)), nil
+}
+
+// Blocks - problems of text read from file
+func Blocks(file, text string) []Block {
+	var (
+		blocks []Block
+		block  Block
+	)
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if block.Rows != nil {
+				blocks = append(blocks, block)
+				block = Block{}
+			}
+			continue
+		}
+		tokens := Tokenize(file, i+1, line)
+		if len(tokens) == 0 {
+			// only comments
+			continue
+		}
+		if block.Rows == nil {
+			block.File, block.Line = file, i+1
+		}
+		block.Rows = append(block.Rows, tokens)
+	}
+	if block.Rows != nil {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// Errorf - error at the start of row of the block
+func (b Block) Errorf(row int, format string, a ...interface{}) error {
+	if row >= len(b.Rows) {
+		last := b.Rows[len(b.Rows)-1][0]
+		return &Error{File: b.File, Line: last.Line + 1, Column: 1, Msg: fmt.Sprintf(format, a...)}
+	}
+	return b.Rows[row][0].Errorf(format, a...)
+}
+
+// Numbers - numbers of row, there must be exactly n of them
+func (b Block) Numbers(row, n int) ([]float64, error) {
+	if row >= len(b.Rows) {
+		return nil, b.Errorf(row, "expected row of %v numbers, got end of problem", n)
+	}
+	tokens := b.Rows[row]
+	if len(tokens) < n {
+		last := tokens[len(tokens)-1]
+		return nil, last.errorAfter("expected %v numbers, got %v", n, len(tokens))
+	}
+	if len(tokens) > n {
+		return nil, tokens[n].Errorf("expected %v numbers, got %v", n, len(tokens))
+	}
+	numbers := make([]float64, n)
+	for i, token := range tokens {
+		number, err := token.Number()
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = number
+	}
+	return numbers, nil
+}
+
+// Rats - exact numbers of row, there must be exactly n of them
+func (b Block) Rats(row, n int) ([]*big.Rat, error) {
+	// Numbers checks the count and positions errors of values that aren't
+	// numbers at all
+	if _, err := b.Numbers(row, n); err != nil {
+		return nil, err
+	}
+	numbers := make([]*big.Rat, n)
+	for i, token := range b.Rows[row] {
+		number, err := token.Rat()
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = number
+	}
+	return numbers, nil
+}
+
+// RatMatrix - r rows of c exact numbers starting from row
+func (b Block) RatMatrix(row, r, c int) ([][]*big.Rat, error) {
+	matrix := make([][]*big.Rat, r)
+	for i := range matrix {
+		numbers, err := b.Rats(row+i, c)
+		if err != nil {
+			return nil, err
+		}
+		matrix[i] = numbers
+	}
+	return matrix, nil
+}
+
+// Indexes - indexes of row with numeration starting from 1, there must be
+// exactly n of them
+func (b Block) Indexes(row, n int) ([]int, error) {
+	numbers, err := b.Numbers(row, n)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, n)
+	for i, number := range numbers {
+		if number != float64(int(number)) || number < 1 {
+			return nil, b.Rows[row][i].Errorf("%v is not an index", number)
+		}
+		indexes[i] = int(number) - 1
+	}
+	return indexes, nil
+}
+
+// Vector - vector of row, there must be exactly n numbers in it
+func (b Block) Vector(row, n int) (*mat.VecDense, error) {
+	numbers, err := b.Numbers(row, n)
+	if err != nil {
+		return nil, err
+	}
+	return mat.NewVecDense(n, numbers), nil
+}
+
+// Matrix - r×c matrix of r rows starting from row
+func (b Block) Matrix(row, r, c int) (*mat.Dense, error) {
+	matrix := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		numbers, err := b.Numbers(row+i, c)
+		if err != nil {
+			return nil, err
+		}
+		matrix.SetRow(i, numbers)
+	}
+	return matrix, nil
+}
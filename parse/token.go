@@ -0,0 +1,121 @@
+package parse
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Error - error at position of the problem file
+type Error struct {
+	File         string
+	Line, Column int
+	Msg          string
+}
+
+func (e *Error) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%v:%v: %v", e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%v:%v:%v: %v", e.File, e.Line, e.Column, e.Msg)
+}
+
+// Token - value of the problem file with its position, Column counts bytes
+// starting from 1
+type Token struct {
+	Text         string
+	File         string
+	Line, Column int
+}
+
+// Tokenize - values of line with number lineNumber of file
+func Tokenize(file string, lineNumber int, line string) []Token {
+	if comment := strings.IndexByte(line, '#'); comment != -1 {
+		line = line[:comment]
+	}
+	var tokens []Token
+	start := -1
+	for i, r := range line + " " {
+		if unicode.IsSpace(r) || r == ',' {
+			if start != -1 {
+				tokens = append(tokens, Token{Text: line[start:i], File: file, Line: lineNumber, Column: start + 1})
+				start = -1
+			}
+		} else if start == -1 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// Errorf - error at the token position
+func (t Token) Errorf(format string, a ...interface{}) error {
+	return &Error{File: t.File, Line: t.Line, Column: t.Column, Msg: fmt.Sprintf(format, a...)}
+}
+
+// errorAfter - error right after the token
+func (t Token) errorAfter(format string, a ...interface{}) error {
+	t.Column += len(t.Text)
+	return t.Errorf(format, a...)
+}
+
+// Number - value of the token, decimal number or fraction like 3/4
+func (t Token) Number() (float64, error) {
+	number, err := ParseNumber(t.Text)
+	if err != nil {
+		return 0, t.Errorf("%v", err)
+	}
+	return number, nil
+}
+
+// ParseNumber - value of decimal number or fraction like 3/4
+func ParseNumber(text string) (float64, error) {
+	if slash := strings.IndexByte(text, '/'); slash != -1 {
+		numerator, err1 := strconv.ParseFloat(text[:slash], 64)
+		denominator, err2 := strconv.ParseFloat(text[slash+1:], 64)
+		if err1 != nil || err2 != nil {
+			return 0, fmt.Errorf("%q is not a fraction", text)
+		}
+		if denominator == 0 {
+			return 0, fmt.Errorf("%q has zero denominator", text)
+		}
+		return numerator / denominator, nil
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", text)
+	}
+	return number, nil
+}
+
+// Rat - exact value of the token, see ParseRat
+func (t Token) Rat() (*big.Rat, error) {
+	number, err := ParseRat(t.Text)
+	if err != nil {
+		return nil, t.Errorf("%v", err)
+	}
+	return number, nil
+}
+
+// ParseRat - exact value of decimal number or fraction like 3/4, 1/3 is one
+// third and 0.1 is 1/10 here while ParseNumber rounds them to float64
+func ParseRat(text string) (*big.Rat, error) {
+	if slash := strings.IndexByte(text, '/'); slash != -1 {
+		numerator, ok1 := new(big.Rat).SetString(text[:slash])
+		denominator, ok2 := new(big.Rat).SetString(text[slash+1:])
+		if !ok1 || !ok2 || strings.IndexByte(text[slash+1:], '/') != -1 {
+			return nil, fmt.Errorf("%q is not a fraction", text)
+		}
+		if denominator.Sign() == 0 {
+			return nil, fmt.Errorf("%q has zero denominator", text)
+		}
+		return numerator.Quo(numerator, denominator), nil
+	}
+	number, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a number", text)
+	}
+	return number, nil
+}
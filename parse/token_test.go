@@ -0,0 +1,55 @@
+package parse
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseRat(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"3", "3"},
+		{"-2", "-2"},
+		{"1/3", "1/3"},
+		{"-4/6", "-2/3"},
+		{"0.1", "1/10"},
+		{"1.5/2", "3/4"},
+		{"1e-3", "1/1000"},
+	}
+	for _, test := range tests {
+		got, err := ParseRat(test.text)
+		if err != nil {
+			t.Errorf("ParseRat(%q): %v", test.text, err)
+			continue
+		}
+		if want, _ := new(big.Rat).SetString(test.want); got.Cmp(want) != 0 {
+			t.Errorf("ParseRat(%q) = %v, want %v", test.text, got.RatString(), test.want)
+		}
+	}
+}
+
+func TestParseRatErrors(t *testing.T) {
+	for _, text := range []string{"", "x", "1/0", "1/", "/2", "1/2/3", "inf"} {
+		if got, err := ParseRat(text); err == nil {
+			t.Errorf("ParseRat(%q) = %v, want error", text, got.RatString())
+		}
+	}
+}
+
+func TestBlockRats(t *testing.T) {
+	blocks := Blocks("input.txt", "1/3 0.1 2\n")
+	got, err := blocks[0].Rats(0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []*big.Rat{big.NewRat(1, 3), big.NewRat(1, 10), big.NewRat(2, 1)} {
+		if got[i].Cmp(want) != 0 {
+			t.Errorf("value %v = %v, want %v", i+1, got[i].RatString(), want.RatString())
+		}
+	}
+	if _, err := blocks[0].Rats(0, 2); err == nil || err.Error() != "input.txt:1:9: expected 2 numbers, got 3" {
+		t.Errorf("Rats(0, 2) error = %v", err)
+	}
+}
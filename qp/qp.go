@@ -0,0 +1,259 @@
+// Package qp solves quadratic optimization problems
+//
+//	c'x + x'Dx/2 -> min, Ax = b, x >= 0
+//
+// with positive semidefinite D, starting from a feasible plan with its support
+// and extended support.
+package qp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SolveSquareProblem - solves quadratic problem starting from feasiblePlan
+// with its support supConstraints and extended support supConstraintsEx.
+// Result.ExtendedBasis holds the extended support of the last plan. Returns
+// optim.ErrUnbounded, optim.ErrSingularBasis or optim.ErrIterationLimit with a
+// non optimal Result
+func SolveSquareProblem(objectiveVector *mat.VecDense, semiDefiniteMatrix, conditionsMatrix *mat.Dense, feasiblePlan *mat.VecDense, supConstraints, supConstraintsEx []int) (optim.Result, error) {
+	return SolveSquareProblemContext(context.Background(), optim.Options{}, objectiveVector, semiDefiniteMatrix, conditionsMatrix, feasiblePlan, supConstraints, supConstraintsEx)
+}
+
+// SolveSquareProblemContext - SolveSquareProblem stopped with
+// optim.ErrCanceled when ctx is done or options.TimeLimit passes and with
+// optim.ErrIterationLimit after options.MaxIterations iterations. Result of
+// stopped run holds the last plan, the best one found
+func SolveSquareProblemContext(ctx context.Context, options optim.Options, objectiveVector *mat.VecDense, semiDefiniteMatrix, conditionsMatrix *mat.Dense, feasiblePlan *mat.VecDense, supConstraints, supConstraintsEx []int) (optim.Result, error) {
+	return solveSquareProblem(optim.NewLimits(ctx, options), objectiveVector, semiDefiniteMatrix, conditionsMatrix, mat.VecDenseCopyOf(feasiblePlan), linalg.FloatVector(supConstraints), linalg.FloatVector(supConstraintsEx))
+}
+
+func solveSquareProblem(limits optim.Limits, objectiveVector *mat.VecDense, semiDefiniteMatrix, conditionsMatrix *mat.Dense, feasiblePlan, supConstraints, supConstraintsEx *mat.VecDense) (optim.Result, error) {
+	tolerances := limits.Tolerances()
+	// conditions are Ax = b of the starting plan
+	freeVector := &mat.VecDense{}
+	freeVector.MulVec(conditionsMatrix, feasiblePlan)
+	for iteration := 0; ; iteration++ {
+		condNumber, varNumber := conditionsMatrix.Dims()
+		supNumber, supExNumber := supConstraints.Len(), supConstraintsEx.Len()
+		Dx := mat.NewVecDense(varNumber, nil)
+		Dx.MulVec(semiDefiniteMatrix, feasiblePlan)
+		result := optim.Result{
+			Objective:     mat.Dot(objectiveVector, feasiblePlan) + mat.Dot(feasiblePlan, Dx)/2,
+			X:             feasiblePlan,
+			Basis:         linalg.IntVector(supConstraints),
+			ExtendedBasis: linalg.IntVector(supConstraintsEx),
+			Iterations:    iteration,
+		}
+		result.PrimalInfeasibility = primalInfeasibility(conditionsMatrix, freeVector, feasiblePlan)
+
+		// 1 rank
+
+		// 2 non-degenerate matrix
+		baselineMatrix, baselineMatrixInv := linalg.Columns(conditionsMatrix, result.Basis), mat.NewDense(condNumber, condNumber, nil)
+		if err := baselineMatrixInv.Inverse(baselineMatrix); err != nil || math.Abs(mat.Det(baselineMatrix)) <= tolerances.Pivot {
+			result.Status = optim.SingularBasis
+			return result, optim.ErrSingularBasis
+		}
+
+		// 3.1
+		rawSupEx, contains := linalg.RawVector(supConstraintsEx), true
+		for i := 0; i < supNumber; i++ {
+			if !linalg.Find(rawSupEx, supConstraints.AtVec(i)) {
+				contains = false
+				break
+			}
+		}
+		if !contains {
+			return result, fmt.Errorf("supConstraintsEx %v doesnt contain supConstraints %v", result.ExtendedBasis, result.Basis)
+		}
+
+		// 3.2
+		cVector := mat.NewVecDense(varNumber, nil)
+		cVector.AddVec(objectiveVector, Dx)
+		cVectorBaseline := mat.NewVecDense(condNumber, nil)
+		for i := 0; i < condNumber; i++ {
+			cVectorBaseline.SetVec(i, cVector.AtVec(int(supConstraints.AtVec(i))))
+		}
+		cVectorBaseline.ScaleVec(-1, cVectorBaseline)
+		uVector := linalg.VecMulMat(cVectorBaseline, baselineMatrixInv)
+		uA := linalg.VecMulMat(uVector, conditionsMatrix)
+		deltaVector := mat.NewVecDense(varNumber, nil)
+		deltaVector.AddVec(uA, cVector)
+		for i := 0; i < varNumber; i++ {
+			result.DualInfeasibility = math.Max(result.DualInfeasibility, -deltaVector.AtVec(i))
+		}
+
+		// H matrix creating
+		conditionsMatrixEx := linalg.Columns(conditionsMatrix, result.ExtendedBasis)
+		dMatrixEx := mat.NewDense(supExNumber, supExNumber, nil)
+		for i := 0; i < supExNumber; i++ {
+			for j := 0; j < supExNumber; j++ {
+				dMatrixEx.Set(i, j, semiDefiniteMatrix.At(int(supConstraintsEx.AtVec(i)), int(supConstraintsEx.AtVec(j))))
+			}
+		}
+
+		conditionsMatrixExT := conditionsMatrixEx.T()
+
+		hMatrix := mat.NewDense(supExNumber+condNumber, supExNumber+condNumber, nil)
+		for i := 0; i < supExNumber+condNumber; i++ {
+			for j := 0; j < supExNumber+condNumber; j++ {
+				if i < supExNumber && j < supExNumber {
+					hMatrix.Set(i, j, dMatrixEx.At(i, j))
+				} else if i < supExNumber && j >= supExNumber {
+					hMatrix.Set(i, j, conditionsMatrixExT.At(i, j-supExNumber))
+				} else if i >= supExNumber && j < supExNumber {
+					hMatrix.Set(i, j, conditionsMatrixEx.At(i-supExNumber, j))
+				}
+			}
+		}
+
+		hMatrixInv := mat.NewDense(supExNumber+condNumber, supExNumber+condNumber, nil)
+		if err := hMatrixInv.Inverse(hMatrix); err != nil {
+			result.Status = optim.SingularBasis
+			return result, optim.ErrSingularBasis
+		}
+
+		// optimal criteria
+		isOptimal, negativeIndex := true, 0
+		for i := 0; i < varNumber; i++ {
+			if deltaVector.AtVec(i) < -tolerances.DualFeasibility {
+				negativeIndex = i
+				isOptimal = false
+				break
+			}
+		}
+		if isOptimal {
+			result.Status = optim.Optimal
+			return result, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+
+		// l vector creating
+		lVector := mat.NewVecDense(varNumber, nil)
+		lVector.SetVec(negativeIndex, 1.0)
+		bVector := mat.NewVecDense(supExNumber+condNumber, nil)
+		for i := 0; i < supExNumber; i++ {
+			bVector.SetVec(i, semiDefiniteMatrix.At(int(supConstraintsEx.AtVec(i)), negativeIndex))
+		}
+		for i := 0; i < condNumber; i++ {
+			bVector.SetVec(i+supExNumber, conditionsMatrix.At(i, negativeIndex))
+		}
+		xVector := mat.NewVecDense(supExNumber+condNumber, nil)
+		hMatrixInvNegative := mat.DenseCopyOf(hMatrixInv)
+		hMatrixInvNegative.Scale(-1, hMatrixInvNegative)
+
+		xVector.MulVec(hMatrixInvNegative, bVector)
+		for i := 0; i < supExNumber; i++ {
+			lVector.SetVec(int(supConstraintsEx.AtVec(i)), xVector.AtVec(i))
+		}
+
+		// minTheta
+		δ := mat.Dot(linalg.VecMulMat(lVector, semiDefiniteMatrix), lVector)
+		minTheta, minThetaIndex := 0.0, negativeIndex
+		if math.Abs(δ) <= tolerances.Zero {
+			minTheta = math.Inf(1)
+		} else if δ > 0 {
+			minTheta = math.Abs(deltaVector.AtVec(negativeIndex)) / δ
+		}
+
+		for i := 0; i < supExNumber; i++ {
+			if i != negativeIndex {
+				currentTheta := math.Inf(1)
+				if lVector.AtVec(i) < -tolerances.Pivot {
+					currentTheta = -(feasiblePlan.AtVec(i) / lVector.AtVec(i))
+					if currentTheta < minTheta {
+						minTheta = currentTheta
+						minThetaIndex = i
+					}
+				}
+			}
+		}
+		if minTheta == math.Inf(1) {
+			result.Status = optim.Unbounded
+			return result, optim.ErrUnbounded
+		}
+
+		// feasiblePlan updating
+		feasiblePlan = mat.VecDenseCopyOf(feasiblePlan)
+		feasiblePlan.AddScaledVec(feasiblePlan, minTheta, lVector)
+		optim.Tracef("new plan is\n")
+		optim.TraceMatrix(feasiblePlan)
+		optim.Tracef("new constraint vector is\n")
+		optim.TraceMatrix(supConstraints)
+		optim.Tracef("new constraint vector extended is\n")
+		optim.TraceMatrix(supConstraintsEx)
+
+		// supConstraints updating
+		rawSup := linalg.RawVector(supConstraints)
+		rawSupEx = linalg.RawVector(supConstraintsEx)
+		supConstraintsSubstraction := substractSets(rawSupEx, rawSup)
+		// negativeIndex - j0
+		// minThetaIndex - j*
+		if int(minThetaIndex) == negativeIndex {
+			rawSupEx = append(rawSupEx, float64(negativeIndex))
+		} else if linalg.Find(supConstraintsSubstraction, float64(minThetaIndex)) {
+			rawSupEx = removeByValue(rawSupEx, float64(minThetaIndex))
+		} else if s := linalg.Index(rawSup, float64(minThetaIndex)); s != -1 {
+			// 1 - 3 condition, 2 - 4 condition, 0 - neither condition
+			for _, jplus := range supConstraintsSubstraction {
+				tempVector := mat.VecDenseCopyOf(conditionsMatrix.ColView(int(jplus)))
+				tempVector.MulVec(baselineMatrixInv, tempVector)
+				if math.Abs(tempVector.AtVec(s)) > tolerances.Pivot {
+					rawSup[s] = jplus
+					rawSupEx = removeByValue(rawSupEx, float64(minThetaIndex))
+					break
+				} else if math.Abs(tempVector.AtVec(s)) <= tolerances.Pivot || reflect.DeepEqual(rawSup, rawSupEx) {
+					rawSup[s] = float64(negativeIndex)
+					rawSupEx[s] = float64(negativeIndex)
+					break
+				}
+			}
+		}
+		supConstraints = mat.NewVecDense(len(rawSup), rawSup)
+		supConstraintsEx = mat.NewVecDense(len(rawSupEx), rawSupEx)
+	}
+}
+
+// primalInfeasibility - the greatest of |b - Ax| and -x
+func primalInfeasibility(conditionsMatrix *mat.Dense, freeVector, plan *mat.VecDense) float64 {
+	residuals := &mat.VecDense{}
+	residuals.MulVec(conditionsMatrix, plan)
+	residuals.SubVec(freeVector, residuals)
+	infeasibility := 0.0
+	for i := 0; i < residuals.Len(); i++ {
+		infeasibility = math.Max(infeasibility, math.Abs(residuals.AtVec(i)))
+	}
+	for j := 0; j < plan.Len(); j++ {
+		infeasibility = math.Max(infeasibility, -plan.AtVec(j))
+	}
+	return infeasibility
+}
+
+// substractSets = (a - b) or (a \ b). For example {1 2 3} \ {2 3} = {1}
+func substractSets(a, b []float64) []float64 {
+	result := make([]float64, len(a))
+	copy(result, a)
+	for _, v := range b {
+		if linalg.Find(a, v) {
+			result = removeByValue(result, v)
+		}
+	}
+	return result
+}
+
+func removeByValue(slice []float64, value float64) []float64 {
+	if index := linalg.Index(slice, value); index != -1 {
+		return append(slice[:index], slice[index+1:]...)
+	}
+	return slice
+}
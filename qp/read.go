@@ -0,0 +1,87 @@
+package qp
+
+import (
+	"fmt"
+
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Problem - quadratic problem with objective vector c, semidefinite matrix D
+// and conditions matrix A. FeasiblePlan with its supports is set if the
+// problem file has them
+type Problem struct {
+	ObjectiveVector    *mat.VecDense
+	SemiDefiniteMatrix *mat.Dense
+	ConditionsMatrix   *mat.Dense
+	FeasiblePlan       *mat.VecDense
+	SupConstraints     []int
+	SupConstraintsEx   []int
+}
+
+// ParseSquareProblem - problem of block with rows c, D, A and, if withPlan is
+// set, feasible plan, its support and extended support (numeration starts
+// from 1 in file)
+func ParseSquareProblem(block parse.Block, withPlan bool) (Problem, error) {
+	var (
+		problem Problem
+		err     error
+	)
+	tail := 0
+	if withPlan {
+		tail = 3
+	}
+	varNumber := len(block.Rows[0])
+	conditionsNumber := len(block.Rows) - 1 - varNumber - tail
+	if conditionsNumber < 1 {
+		if withPlan {
+			return problem, block.Errorf(0, "can't infer sizes: expected c, %v rows of D, rows of A, feasible plan, its support and extended support, got %v rows", varNumber, len(block.Rows))
+		}
+		return problem, block.Errorf(0, "can't infer sizes: expected c, %v rows of D and rows of A, got %v rows", varNumber, len(block.Rows))
+	}
+	if problem.ObjectiveVector, err = block.Vector(0, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.SemiDefiniteMatrix, err = block.Matrix(1, varNumber, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.ConditionsMatrix, err = block.Matrix(1+varNumber, conditionsNumber, varNumber); err != nil {
+		return problem, err
+	}
+	if !withPlan {
+		return problem, nil
+	}
+	row := 1 + varNumber + conditionsNumber
+	if problem.FeasiblePlan, err = block.Vector(row, varNumber); err != nil {
+		return problem, err
+	}
+	if problem.SupConstraints, err = block.Indexes(row+1, conditionsNumber); err != nil {
+		return problem, err
+	}
+	if problem.SupConstraintsEx, err = block.Indexes(row+2, len(block.Rows[row+2])); err != nil {
+		return problem, err
+	}
+	for k, indexes := range [][]int{problem.SupConstraints, problem.SupConstraintsEx} {
+		for i, index := range indexes {
+			if index >= varNumber {
+				return problem, block.Rows[row+1+k][i].Errorf("index %v is out of %v variables", index+1, varNumber)
+			}
+		}
+	}
+	return problem, nil
+}
+
+// ReadSquareProblems - every problem of input, see ParseSquareProblem
+func ReadSquareProblems(input string, withPlan bool) ([]Problem, error) {
+	blocks, err := parse.ReadBlocks(input)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]Problem, len(blocks))
+	for i, block := range blocks {
+		if problems[i], err = ParseSquareProblem(block, withPlan); err != nil {
+			return nil, fmt.Errorf("%v: problem %v: %w", input, i+1, err)
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,180 @@
+package transport
+
+import (
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"gonum.org/v1/gonum/mat"
+)
+
+// countPos - count pos in array
+func countPos(array []Pos, value Pos) int {
+	res := 0
+	for _, curr := range array {
+		if curr == value {
+			res++
+		}
+	}
+	return res
+}
+
+// checkSum - whether sums of a and b are the same
+func checkSum[T any](field linalg.Field[T], a, b []T) bool {
+	sumA, sumB := field.FromFloat(0), field.FromFloat(0)
+	for _, v := range a {
+		sumA = field.Add(sumA, v)
+	}
+	for _, v := range b {
+		sumB = field.Add(sumB, v)
+	}
+	return field.Sign(field.Sub(sumA, sumB)) == 0
+}
+
+// findPos - find pos in array
+func findPos(array []Pos, value Pos) (bool, int) {
+	for i, curr := range array {
+		if curr == value {
+			return true, i
+		}
+	}
+	return false, -1
+}
+
+func newAdjacencyMatrix(pos []Pos) *mat.Dense {
+	adjacencyMatrix := mat.NewDense(len(pos), len(pos), nil)
+	for i := 0; i < len(pos); i++ {
+		for j := 0; j < len(pos); j++ {
+			if i != j && (pos[i].I == pos[j].I || pos[i].J == pos[j].J) {
+				adjacencyMatrix.Set(i, j, 1)
+			}
+		}
+	}
+	return adjacencyMatrix
+}
+
+func deleteRow[T any](m *linalg.Dense[T], posMatrix [][]Pos, row int) (*linalg.Dense[T], [][]Pos) {
+	r, c := m.Dims()
+	newSlice := linalg.NewDenseOf(m.Field, r-1, c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if i < row {
+				newSlice.Set(i, j, m.At(i, j))
+			} else if i > row {
+				newSlice.Set(i-1, j, m.At(i, j))
+			}
+		}
+	}
+	newPosMatrix := make([][]Pos, r-1)
+	for i := 0; i < r; i++ {
+		if i < row {
+			newPosMatrix[i] = posMatrix[i]
+		} else if i > row {
+			newPosMatrix[i-1] = posMatrix[i]
+		}
+	}
+	return newSlice, newPosMatrix
+}
+
+func deleteCol[T any](m *linalg.Dense[T], posMatrix [][]Pos, col int) (*linalg.Dense[T], [][]Pos) {
+	r, c := m.Dims()
+	newSlice := linalg.NewDenseOf(m.Field, r, c-1)
+	for i := 0; i < c; i++ {
+		if i < col {
+			newSlice.SetCol(i, m.Col(i))
+		} else if i > col {
+			newSlice.SetCol(i-1, m.Col(i))
+		}
+	}
+	newPosMatrix := make([][]Pos, r)
+	for i := 0; i < r; i++ {
+		newPosMatrix[i] = append(posMatrix[i][:col], posMatrix[i][col+1:]...)
+	}
+	return newSlice, newPosMatrix
+}
+
+func getNonBaselinePos(baslinePos []Pos, lenA, lenB int) []Pos {
+	nonBaselinePos := make([]Pos, 0)
+	for i := 0; i < lenA; i++ {
+		for j := 0; j < lenB; j++ {
+			curPos := Pos{i, j}
+			if countPos(baslinePos, curPos) == 0 {
+				nonBaselinePos = append(nonBaselinePos, curPos)
+			}
+		}
+	}
+	return nonBaselinePos
+}
+
+func bfs(adj *mat.Dense, pos []Pos, newPos Pos) []int {
+	n, _ := adj.Dims()
+	signs, queue, currentSign := make([]int, n), make([]Pos, 0), 1
+	_, i := findPos(pos, newPos)
+	queue = append(queue, newPos)
+	signs[i] = currentSign
+	currentSign *= -1
+	for len(queue) > 0 {
+		currentPos := queue[0]
+		queue = queue[1:]
+		_, currentPosIndex := findPos(pos, currentPos)
+		adjRow := adj.RawRowView(currentPosIndex)
+		for j, adjRowValue := range adjRow {
+			if int(adjRowValue) == 1 {
+				found, posIndex := findPos(pos, pos[j])
+				if found && signs[posIndex] == 0 {
+					signs[posIndex] = currentSign
+					queue = append(queue, pos[posIndex])
+				}
+			}
+		}
+		currentSign *= -1
+	}
+	return signs
+}
+
+func getUVBfs[T any](c *linalg.Dense[T], b []Pos) ([]T, []T) {
+	field := c.Field
+	lenA, lenB := c.Dims()
+	u, v := make([]T, lenA), make([]T, lenB)
+	for i := range u {
+		u[i] = field.FromFloat(0)
+	}
+	for j := range v {
+		v[j] = field.FromFloat(0)
+	}
+
+	// 0 - unvisited, 1 - u visited, 2 - v visited, 3 - u and v visited
+	visited := make([]int, len(b))
+
+	// Artificial u1 = 0
+	visited[0] = 1
+	queue := make([]Pos, 1)
+	queue[0] = b[0]
+
+	// Solving linear system equation indirectly
+	for len(queue) > 0 {
+		currentPos := queue[0]
+		queue = queue[1:]
+		_, currentPosIndex := findPos(b, currentPos)
+		visit := visited[currentPosIndex]
+		if visit != 0 && visit != 3 {
+			if visit == 1 {
+				v[currentPos.J] = field.Sub(c.At(currentPos.I, currentPos.J), u[currentPos.I])
+				visited[currentPosIndex] = 3
+			} else if visit == 2 {
+				u[currentPos.I] = field.Sub(c.At(currentPos.I, currentPos.J), v[currentPos.J])
+				visited[currentPosIndex] = 3
+			}
+			for k := 0; k < len(visited); k++ {
+				//currentPos != b[k]
+				if visited[k] != 3 {
+					if currentPos.I == b[k].I {
+						visited[k] = 1
+						queue = append(queue, b[k])
+					} else if currentPos.J == b[k].J {
+						visited[k] = 2
+						queue = append(queue, b[k])
+					}
+				}
+			}
+		}
+	}
+	return u, v
+}
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/parse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Problem - transport problem: produced values A, needed values B and costs
+// matrix C. Exact holds them as fractions of the problem file
+type Problem struct {
+	A, B  *mat.VecDense
+	C     *mat.Dense
+	Exact *ExactProblem
+}
+
+// ExactProblem - values of Problem as they're written in the problem file,
+// 1/3 is an exact fraction here
+type ExactProblem struct {
+	A, B []*big.Rat
+	C    *linalg.Dense[*big.Rat]
+}
+
+// ParseTransportProblem - problem of block with rows a, b and rows of c
+func ParseTransportProblem(block parse.Block) (Problem, error) {
+	var (
+		problem Problem
+		err     error
+	)
+	if len(block.Rows) < 2 || len(block.Rows) != 2+len(block.Rows[0]) {
+		return problem, block.Errorf(0, "can't infer sizes: expected a, b and a row of c for every a element, got %v rows", len(block.Rows))
+	}
+	lenA, lenB := len(block.Rows[0]), len(block.Rows[1])
+	if problem.A, err = block.Vector(0, lenA); err != nil {
+		return problem, err
+	}
+	if problem.B, err = block.Vector(1, lenB); err != nil {
+		return problem, err
+	}
+	if problem.C, err = block.Matrix(2, lenA, lenB); err != nil {
+		return problem, err
+	}
+	exact := ExactProblem{}
+	if exact.A, err = block.Rats(0, lenA); err != nil {
+		return problem, err
+	}
+	if exact.B, err = block.Rats(1, lenB); err != nil {
+		return problem, err
+	}
+	costs, err := block.RatMatrix(2, lenA, lenB)
+	if err != nil {
+		return problem, err
+	}
+	exact.C = linalg.RatDenseOf(costs)
+	problem.Exact = &exact
+	return problem, nil
+}
+
+// ReadTransportProblems - every problem of input, see ParseTransportProblem
+func ReadTransportProblems(input string) ([]Problem, error) {
+	blocks, err := parse.ReadBlocks(input)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]Problem, len(blocks))
+	for i, block := range blocks {
+		if problems[i], err = ParseTransportProblem(block); err != nil {
+			return nil, fmt.Errorf("%v: problem %v: %w", input, i+1, err)
+		}
+	}
+	return problems, nil
+}
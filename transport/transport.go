@@ -0,0 +1,342 @@
+// Package transport solves closed transport problems: a - produced values, b -
+// needed values, c - cost of moving unit of value from producer i to consumer
+// j. First plan is built with the north-west corner method, then improved with
+// the potentials method, with float64 values or exact fractions, see
+// ExactPotentialsMethod.
+package transport
+
+import (
+	"context"
+	"math"
+	"math/big"
+
+	"github.com/Lykashonok/moiu_labs_3_course/linalg"
+	"github.com/Lykashonok/moiu_labs_3_course/optim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Pos - cell of the transport plan, I - producer, J - consumer
+type Pos struct {
+	I, J int
+}
+
+// NorthWestMethod - first plan with its baseline positions. a and b are
+// changed in place, values within optim.DefaultTolerances.Zero from zero are
+// zeros
+func NorthWestMethod(a, b *mat.VecDense) (*mat.Dense, []Pos) {
+	field := linalg.Float{Tolerance: optim.DefaultTolerances.Zero}
+	aValues, bValues := linalg.VectorOf[float64](field, a), linalg.VectorOf[float64](field, b)
+	x, pos := northWestMethodOf[float64](field, aValues, bValues)
+	a.SetRawVector(mat.NewVecDense(len(aValues), aValues).RawVector())
+	b.SetRawVector(mat.NewVecDense(len(bValues), bValues).RawVector())
+	return x.Float(), pos
+}
+
+// northWestMethodOf - NorthWestMethod with values of any linalg.Field
+func northWestMethodOf[T any](field linalg.Field[T], a, b []T) (*linalg.Dense[T], []Pos) {
+	lenA, lenB := len(a), len(b)
+	x, pos := linalg.NewDenseOf(field, lenA, lenB), make([]Pos, 0)
+	zero := field.FromFloat(0)
+	i, j := 0, 0
+	for {
+		val := field.Sub(b[j], a[i])
+		if field.Sign(val) >= 0 {
+			x.Set(i, j, a[i])
+			b[j] = val
+			a[i] = zero
+			pos = append(pos, Pos{i, j})
+			if i < lenA {
+				i++
+			}
+		} else {
+			val = field.Sub(a[i], b[j])
+			x.Set(i, j, b[j])
+			a[i] = val
+			b[j] = zero
+			pos = append(pos, Pos{i, j})
+			if j < lenB {
+				j++
+			}
+		}
+		if field.Sign(a[lenA-1]) == 0 && field.Sign(b[lenB-1]) == 0 {
+			break
+		} else if i == lenA && j < lenB {
+			i--
+		} else if i < lenA && j == lenB {
+			j--
+		}
+	}
+	return x, pos
+}
+
+// PotentialsMethod - solves transport problem. Result.X is the plan stored by
+// rows (see Plan) and Result.Basis holds baseline positions as I*b.Len()+J.
+// Returns optim.ErrInfeasible if sums of needed and produced values are not
+// the same
+func PotentialsMethod(a, b *mat.VecDense, c *mat.Dense) (optim.Result, error) {
+	return PotentialsMethodContext(context.Background(), optim.Options{}, a, b, c)
+}
+
+// PotentialsMethodContext - PotentialsMethod stopped with optim.ErrCanceled
+// when ctx is done or options.TimeLimit passes and with
+// optim.ErrIterationLimit after options.MaxIterations pivots. Result of stopped
+// run holds the last plan, the cheapest one found
+func PotentialsMethodContext(ctx context.Context, options optim.Options, a, b *mat.VecDense, c *mat.Dense) (optim.Result, error) {
+	limits := optim.NewLimits(ctx, options)
+	field := linalg.Float{Tolerance: limits.Tolerances().Zero}
+	result, err := potentialsMethodOf[float64](limits, linalg.VectorOf[float64](field, a), linalg.VectorOf[float64](field, b), linalg.DenseOf[float64](field, c))
+	return withInfeasibility(result, err, limits.Tolerances(), a, b, c)
+}
+
+// ExactPotentialsMethod - PotentialsMethod with rational arithmetic: plan,
+// potentials and sums of a and b are compared exactly. Result holds Exact
+// values, see ExactPlan. Values are fractions as ExactProblem of the problem
+// file holds them
+func ExactPotentialsMethod(a, b []*big.Rat, c *linalg.Dense[*big.Rat]) (optim.Result, error) {
+	return ExactPotentialsMethodContext(context.Background(), optim.Options{}, a, b, c)
+}
+
+// ExactPotentialsMethodContext - ExactPotentialsMethod with limits, see
+// PotentialsMethodContext
+func ExactPotentialsMethodContext(ctx context.Context, options optim.Options, a, b []*big.Rat, c *linalg.Dense[*big.Rat]) (optim.Result, error) {
+	field := linalg.Rational{}
+	limits := optim.NewLimits(ctx, options)
+	result, err := potentialsMethodOf[*big.Rat](limits, a, b, c)
+	return withInfeasibility(result, err, limits.Tolerances(), linalg.FloatVectorOf[*big.Rat](field, a), linalg.FloatVectorOf[*big.Rat](field, b), c.Float())
+}
+
+// Plan - transport plan matrix of Result returned by PotentialsMethod
+func Plan(result optim.Result, lenA, lenB int) *mat.Dense {
+	return mat.NewDense(lenA, lenB, mat.VecDenseCopyOf(result.X).RawVector().Data)
+}
+
+// ExactPlan - transport plan matrix of fractions of Result returned by
+// ExactPotentialsMethod
+func ExactPlan(result optim.Result, lenA, lenB int) *linalg.Dense[*big.Rat] {
+	plan := linalg.NewDenseOf[*big.Rat](linalg.Rational{}, lenA, lenB)
+	for i := 0; i < lenA; i++ {
+		for j := 0; j < lenB; j++ {
+			plan.Set(i, j, result.Exact.X[i*lenB+j])
+		}
+	}
+	return plan
+}
+
+// potentialsMethodOf - checks sums of a and b, builds the first plan and
+// improves it with values of field of c. Float values of u[i] + v[j] up to
+// c[i][j] + limits.Tolerances().DualFeasibility are optimal
+func potentialsMethodOf[T any](limits optim.Limits, aValues, bValues []T, c *linalg.Dense[T]) (optim.Result, error) {
+	field := c.Field
+	// If consumers and producers have different sums of values
+	if !checkSum(field, aValues, bValues) {
+		return optim.Result{Status: optim.Infeasible}, optim.ErrInfeasible
+	}
+
+	x, baselinePos := northWestMethodOf(field, aValues, bValues)
+	dualTolerance := field.FromFloat(0)
+	if !field.Exact() {
+		dualTolerance = field.FromFloat(limits.Tolerances().DualFeasibility)
+	}
+	return potentialsMethodMainPhase(limits, c, x, baselinePos, dualTolerance)
+}
+
+// potentialsMethodMainPhase - improves plan x with baselinePos until it's optimal
+func potentialsMethodMainPhase[T any](limits optim.Limits, c *linalg.Dense[T], x *linalg.Dense[T], baselinePos []Pos, dualTolerance T) (optim.Result, error) {
+	field := c.Field
+	for iteration := 0; ; iteration++ {
+		optim.Tracef("---Iteration start---\n")
+		lenA, lenB := x.Dims()
+		result := transportResult(c, x, baselinePos, iteration)
+
+		optim.Tracef("baselinePos at start %v \nfirst plan\n", baselinePos)
+		traceDense(x)
+
+		uVector, vVector := getUVBfs(c, baselinePos)
+		optim.Tracef("uVector [%v] \nvVector [%v]\n", linalg.FormatVector(field, uVector), linalg.FormatVector(field, vVector))
+
+		nonBaselinePos, isOptimal, newBaselinePos := getNonBaselinePos(baselinePos, lenA, lenB), true, Pos{}
+
+		for _, pos := range nonBaselinePos {
+			if field.Sign(field.Sub(field.Sub(field.Add(uVector[pos.I], vVector[pos.J]), c.At(pos.I, pos.J)), dualTolerance)) > 0 {
+				isOptimal = false
+				newBaselinePos = pos
+				break
+			}
+		}
+		if isOptimal {
+			optim.Tracef("There's no pos with u[i]+v[j]>c[i][j], current x is optimal\n")
+			result.Status = optim.Optimal
+			return result, nil
+		}
+		if status, err := limits.Check(iteration); err != nil {
+			result.Status = status
+			return result, err
+		}
+		optim.Tracef("There's nonbasline position with u[%v]+v[%v]>c[%v][%v] => %v + %v > %v\n", newBaselinePos.I+1, newBaselinePos.J+1, newBaselinePos.I+1, newBaselinePos.J+1, field.Format(uVector[newBaselinePos.I]), field.Format(vVector[newBaselinePos.J]), field.Format(c.At(newBaselinePos.I, newBaselinePos.J)))
+
+		// Copying x and clearing it
+		x_copy, is_cleared := x.Clone(), false
+
+		baselinePosMatrix, nullPos := make([][]Pos, lenA), Pos{-1, -1}
+		for i := 0; i < lenA; i++ {
+			baselinePosMatrix[i] = make([]Pos, lenB)
+			for j := 0; j < lenB; j++ {
+				if found, _ := findPos(baselinePos, Pos{i, j}); found {
+					baselinePosMatrix[i][j] = Pos{i, j}
+				} else {
+					baselinePosMatrix[i][j] = nullPos
+				}
+			}
+		}
+		baselinePosMatrix[newBaselinePos.I][newBaselinePos.J] = newBaselinePos
+
+		// set is_cleared = true, if row was deleted, set is_cleared = false. Exit from cycle when
+		// no row or column deleted
+		for is_cleared == false {
+			is_cleared = true
+			for i := 0; i < lenA; i++ {
+				rowBaselinePosNumber := 0
+				for j := 0; j < lenB; j++ {
+					// Count baselinepos. If 1 or 0, delete this row
+					if baselinePosMatrix[i][j] != nullPos {
+						rowBaselinePosNumber++
+					}
+				}
+				if rowBaselinePosNumber <= 1 {
+					x_copy, baselinePosMatrix = deleteRow(x_copy, baselinePosMatrix, i)
+					is_cleared = false
+					break
+				}
+			}
+			lenA, lenB = x_copy.Dims()
+			for j := 0; j < lenB; j++ {
+				colBaselinePosNumber := 0
+				for i := 0; i < lenA; i++ {
+					// Count baselinepos. If 1 or 0, delete this col
+					if baselinePosMatrix[i][j] != nullPos {
+						colBaselinePosNumber++
+					}
+				}
+				if colBaselinePosNumber <= 1 {
+					x_copy, baselinePosMatrix = deleteCol(x_copy, baselinePosMatrix, j)
+					is_cleared = false
+					break
+				}
+			}
+			lenA, lenB = x_copy.Dims()
+		}
+		// New BaselinePos
+		baselinePos_copy := make([]Pos, 0)
+		for i := 0; i < lenA; i++ {
+			for j := 0; j < lenB; j++ {
+				if baselinePosMatrix[i][j] != nullPos {
+					baselinePos_copy = append(baselinePos_copy, baselinePosMatrix[i][j])
+				}
+			}
+		}
+		optim.Tracef("Cleared plan is\n")
+		traceDense(x_copy)
+
+		// finding min theta then process operation with x on baseline pos
+		// first element of baselinePos is newbaseline pos
+		adjacencyMatrix := newAdjacencyMatrix(baselinePos_copy)
+		signs := bfs(adjacencyMatrix, baselinePos_copy, newBaselinePos)
+
+		minTheta, minThetaPos, found := field.FromFloat(0), Pos{}, false
+		for k := 0; k < len(baselinePos_copy); k++ {
+			if signs[k] == -1 {
+				for i := 0; i < lenA; i++ {
+					for j := 0; j < lenB; j++ {
+						if baselinePos_copy[k] == baselinePosMatrix[i][j] && (!found || field.Sign(field.Sub(x_copy.At(i, j), minTheta)) < 0) {
+							found = true
+							minTheta, minThetaPos = x_copy.At(i, j), baselinePos_copy[k]
+						}
+					}
+				}
+			}
+		}
+
+		optim.Tracef("minTheta - %v %v\nnew x -\n", field.Format(minTheta), minThetaPos)
+		for i := 0; i < len(baselinePos_copy); i++ {
+			x.Set(baselinePos_copy[i].I, baselinePos_copy[i].J, field.Add(x.At(baselinePos_copy[i].I, baselinePos_copy[i].J), field.Mul(minTheta, field.FromFloat(float64(signs[i])))))
+		}
+		traceDense(x)
+
+		// New x was created. Update in pos where theta was min to new pos
+		for i := 0; i < len(baselinePos); i++ {
+			if baselinePos[i] == minThetaPos {
+				baselinePos[i] = newBaselinePos
+			}
+		}
+
+		optim.Tracef("NewBaselinePos at end - %v\n---Iteration end---\n", baselinePos)
+	}
+}
+
+// transportResult - Result for plan x with baseline positions baselinePos,
+// Exact values are set if field of x is exact
+func transportResult[T any](c, x *linalg.Dense[T], baselinePos []Pos, iteration int) optim.Result {
+	field := x.Field
+	lenA, lenB := x.Dims()
+	plan := make([]T, 0, lenA*lenB)
+	objective := field.FromFloat(0)
+	for i := 0; i < lenA; i++ {
+		for j := 0; j < lenB; j++ {
+			plan = append(plan, x.At(i, j))
+			objective = field.Add(objective, field.Mul(c.At(i, j), x.At(i, j)))
+		}
+	}
+	basis := make([]int, len(baselinePos))
+	for k, pos := range baselinePos {
+		basis[k] = pos.I*lenB + pos.J
+	}
+	result := optim.Result{Objective: field.Float(objective), X: linalg.FloatVectorOf(field, plan), Basis: basis, Iterations: iteration}
+	if field.Exact() {
+		result.Exact = &optim.Exact{Objective: field.Rat(objective), X: linalg.RatVectorOf(field, plan)}
+	}
+	return result
+}
+
+// withInfeasibility - result with PrimalInfeasibility of its plan, the
+// greatest of differences of its row and column sums from a and b and of -x,
+// and DualInfeasibility of its baseline positions, the greatest of u[i] + v[j]
+// - c[i][j]. Optimal result whose plan violates conditions beyond
+// tolerances.PrimalFeasibility is NotSolved, see optim.CheckPrimalFeasibility
+func withInfeasibility(result optim.Result, err error, tolerances optim.Tolerances, a, b *mat.VecDense, c *mat.Dense) (optim.Result, error) {
+	lenA, lenB := a.Len(), b.Len()
+	if result.X == nil || result.X.Len() != lenA*lenB {
+		return result, err
+	}
+	plan := Plan(result, lenA, lenB)
+	for i := 0; i < lenA; i++ {
+		result.PrimalInfeasibility = math.Max(result.PrimalInfeasibility, math.Abs(mat.Sum(plan.RowView(i))-a.AtVec(i)))
+	}
+	for j := 0; j < lenB; j++ {
+		result.PrimalInfeasibility = math.Max(result.PrimalInfeasibility, math.Abs(mat.Sum(plan.ColView(j))-b.AtVec(j)))
+	}
+	for _, value := range plan.RawMatrix().Data {
+		result.PrimalInfeasibility = math.Max(result.PrimalInfeasibility, -value)
+	}
+
+	baselinePos := make([]Pos, len(result.Basis))
+	for k, index := range result.Basis {
+		baselinePos[k] = Pos{index / lenB, index % lenB}
+	}
+	costs := linalg.DenseOf[float64](linalg.Float{}, c)
+	uVector, vVector := getUVBfs(costs, baselinePos)
+	for i := 0; i < lenA; i++ {
+		for j := 0; j < lenB; j++ {
+			result.DualInfeasibility = math.Max(result.DualInfeasibility, uVector[i]+vVector[j]-c.At(i, j))
+		}
+	}
+	return optim.CheckPrimalFeasibility(result, err, tolerances)
+}
+
+// traceDense - trace matrix m, float64 one as optim.TraceMatrix does
+func traceDense[T any](m *linalg.Dense[T]) {
+	if m.Field.Exact() {
+		m.Fprint(optim.Trace)
+		return
+	}
+	optim.TraceMatrix(m.Float())
+}